@@ -13,6 +13,12 @@ type Input struct {
 	Help        bool
 	AccountType string
 	RcDBRoot    string
+	OutPath     string
+	DBCount     int
+	Backend     string
+	History     bool
+	From        uint64
+	To          uint64
 }
 
 const (
@@ -21,12 +27,15 @@ const (
 	AddressUsage     = "Address to query"
 	IISSDataUsage    = "Data type to query. One of header, gv(governance variables), bp(block produce info), prep and tx. Print all iiss related data if this option has not given"
 	RCDBRootUsage    = "path of RC DB"
+	OutPathUsage     = "path of new RC DB"
+	DBCountUsage     = "The number of account DB of new RC DB. Same as source if this option has not given"
+	BackendUsage     = "DB backend of new RC DB. goleveldb, badgerdb or boltdb. Same as source if this option has not given"
 	HelpMsgUsage     = "Print help message"
 )
 
 func InitManageInput(flagSet *flag.FlagSet) *Input {
 	input := new(Input)
-	ManageDataUsage := "Data type to query. One of di(db info), gv(governance variables), prep and pc(prep candidate). if this option has not given, Print all manage data"
+	ManageDataUsage := "Data type to query. One of di(db info), gv(governance variables), prep, pc(prep candidate) and ab(account DB backup). if this option has not given, Print all manage data"
 	flagSet.StringVar(&input.Path, "path", "", pathUsage)
 	flagSet.StringVar(&input.Path, "p", "", pathUsage)
 	flagSet.StringVar(&input.Data, "data", "", ManageDataUsage)
@@ -41,6 +50,7 @@ func InitManageInput(flagSet *flag.FlagSet) *Input {
 func InitAccountInput(flagSet *flag.FlagSet) *Input {
 	input := new(Input)
 	AccountQueryTypeUsage := "Type of account to query. `query` or `calculate`. if enter this option, must enter `dbroot` option."
+	AccountBlockHeightUsage := "Calculation block height to query in account DBs and retained backup account DBs. if enter this option, must enter `dbroot` option."
 	flagSet.StringVar(&input.Path, "path", "", pathUsage)
 	flagSet.StringVar(&input.Path, "p", "", pathUsage)
 	flagSet.StringVar(&input.Address, "address", "", AddressUsage)
@@ -49,6 +59,8 @@ func InitAccountInput(flagSet *flag.FlagSet) *Input {
 	flagSet.StringVar(&input.RcDBRoot, "d", "", RCDBRootUsage)
 	flagSet.StringVar(&input.AccountType, "type", "", AccountQueryTypeUsage)
 	flagSet.StringVar(&input.AccountType, "t", "", AccountQueryTypeUsage)
+	flagSet.Uint64Var(&input.Height, "blockheight", 0, AccountBlockHeightUsage)
+	flagSet.Uint64Var(&input.Height, "b", 0, AccountBlockHeightUsage)
 	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
 	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
 	return input
@@ -56,10 +68,16 @@ func InitAccountInput(flagSet *flag.FlagSet) *Input {
 
 func InitClaimInput(flagSet *flag.FlagSet) *Input {
 	input := new(Input)
+	historyUsage := "Print claims in claim history DB. Set path of claim history DB with this option"
+	fromUsage := "Start block height of claims in claim history"
+	toUsage := "End block height of claims in claim history. Print to the latest claim if this option has not given"
 	flagSet.StringVar(&input.Path, "path", "", pathUsage)
 	flagSet.StringVar(&input.Path, "p", "", pathUsage)
 	flagSet.StringVar(&input.Address, "address", "", AddressUsage)
 	flagSet.StringVar(&input.Address, "a", "", AddressUsage)
+	flagSet.BoolVar(&input.History, "history", false, historyUsage)
+	flagSet.Uint64Var(&input.From, "from", 0, fromUsage)
+	flagSet.Uint64Var(&input.To, "to", 0, toUsage)
 	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
 	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
 	return input
@@ -126,6 +144,61 @@ func InitCalcDebugResult(flagSet *flag.FlagSet) *Input {
 	return input
 }
 
+func InitRewardBreakdownInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	RewardBreakdownBlockHeightUsage := "Calculation block height to query. Print all calculations if this option has not given"
+	flagSet.StringVar(&input.Path, "path", "", pathUsage)
+	flagSet.StringVar(&input.Path, "p", "", pathUsage)
+	flagSet.StringVar(&input.Address, "address", "", AddressUsage)
+	flagSet.StringVar(&input.Address, "a", "", AddressUsage)
+	flagSet.Uint64Var(&input.Height, "blockheight", 0, RewardBreakdownBlockHeightUsage)
+	flagSet.Uint64Var(&input.Height, "b", 0, RewardBreakdownBlockHeightUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
+func InitMigrateInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	flagSet.StringVar(&input.RcDBRoot, "dbroot", "", RCDBRootUsage)
+	flagSet.StringVar(&input.RcDBRoot, "d", "", RCDBRootUsage)
+	flagSet.StringVar(&input.OutPath, "out", "", OutPathUsage)
+	flagSet.StringVar(&input.OutPath, "o", "", OutPathUsage)
+	flagSet.IntVar(&input.DBCount, "dbcount", 0, DBCountUsage)
+	flagSet.IntVar(&input.DBCount, "c", 0, DBCountUsage)
+	flagSet.StringVar(&input.Backend, "backend", "", BackendUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
+func InitStateHashInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	AccountDBTypeUsage := "Type of account DB to calculate state hash. `query` or `calculate`. Default is `calculate`"
+	flagSet.StringVar(&input.RcDBRoot, "dbroot", "", RCDBRootUsage)
+	flagSet.StringVar(&input.RcDBRoot, "d", "", RCDBRootUsage)
+	flagSet.StringVar(&input.AccountType, "type", "", AccountDBTypeUsage)
+	flagSet.StringVar(&input.AccountType, "t", "", AccountDBTypeUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
+func InitPRepReportInput(flagSet *flag.FlagSet) *Input {
+	input := new(Input)
+	PRepReportBlockHeightUsage := "Calculation block height to read P-Rep report. The latest calculation if this option has not given"
+	CSVUsage := "CSV file to write P-Rep report. Print P-Rep report if this option has not given"
+	flagSet.StringVar(&input.RcDBRoot, "dbroot", "", RCDBRootUsage)
+	flagSet.StringVar(&input.RcDBRoot, "d", "", RCDBRootUsage)
+	flagSet.Uint64Var(&input.Height, "blockheight", 0, PRepReportBlockHeightUsage)
+	flagSet.Uint64Var(&input.Height, "b", 0, PRepReportBlockHeightUsage)
+	flagSet.StringVar(&input.OutPath, "csv", "", CSVUsage)
+	flagSet.StringVar(&input.OutPath, "o", "", CSVUsage)
+	flagSet.BoolVar(&input.Help, "help", false, HelpMsgUsage)
+	flagSet.BoolVar(&input.Help, "h", false, HelpMsgUsage)
+	return input
+}
+
 func ValidateInput(flagSet *flag.FlagSet, err error, flag bool) {
 	if err != nil {
 		flagSet.PrintDefaults()
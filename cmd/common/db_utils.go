@@ -33,9 +33,15 @@ func IteratePrintDB(qdb db.Database, prefix *util.Range, printFunc func([]byte,
 }
 
 func PrintDB(path string, prefix *util.Range, printFunc func([]byte, []byte) error) (err error) {
+	return PrintDBWithBackend(path, string(db.GoLevelDBBackend), prefix, printFunc)
+}
+
+// PrintDBWithBackend prints data of DB opened with backend
+func PrintDBWithBackend(path string, backend string, prefix *util.Range,
+	printFunc func([]byte, []byte) error) (err error) {
 	fmt.Printf("===================== Data in %s =============\n", path)
 	dir, name := filepath.Split(path)
-	qdb := db.Open(dir, string(db.GoLevelDBBackend), name)
+	qdb := db.Open(dir, backend, name)
 	defer qdb.Close()
 	err = IteratePrintDB(qdb, prefix, printFunc)
 	return
@@ -17,6 +17,9 @@ const (
 	DBNameCalcResult      = "calcResult"
 	DBNameIISS            = "iiss"
 	DBNameCalcDebugResult = "calcDebug"
+	DBNameRewardBreakdown = "rewardBreakdown"
+
+	CommandMigrate = "migrate"
 
 	DataTypeGV     = "gv"
 	DataTypePRep   = "prep"
@@ -25,14 +28,15 @@ const (
 	DataTypeBP     = "bp"
 	DataTypeDI     = "di"
 	DataTypePC     = "pc"
+	DataTypeAB     = "ab"
 
 	AccountDBTypeQuery     = "query"
 	AccountDBTypeCalculate = "calculate"
 )
 
 func printUsage() {
-	fmt.Printf("Usage: %s [db_name] [[options]]\n", os.Args[0])
-	fmt.Printf("\t db_name     DB Name (%s, %s, %s, %s, %s, %s, %s, %s)\n",
+	fmt.Printf("Usage: %s [db_name|command] [[options]]\n", os.Args[0])
+	fmt.Printf("\t db_name     DB Name (%s, %s, %s, %s, %s, %s, %s, %s, %s)\n",
 		DBNameManagement,
 		DBNameAccount,
 		DBNameClaim,
@@ -41,7 +45,10 @@ func printUsage() {
 		DBNameCalcResult,
 		DBNameIISS,
 		DBNameCalcDebugResult,
+		DBNameRewardBreakdown,
 	)
+	fmt.Printf("\t command     Command (%s)\n", CommandMigrate)
+	fmt.Printf("\t\t %s     Copy RC DB to new RC DB with another account DB count and DB backend\n", CommandMigrate)
 }
 
 func validateArgs() (err error) {
@@ -78,6 +85,8 @@ func Run() (err error) {
 	calcResultFlagSet := flag.NewFlagSet(DBNameCalcResult, flag.ExitOnError)
 	iissFlagSet := flag.NewFlagSet(DBNameIISS, flag.ExitOnError)
 	calcDebugFlagSet := flag.NewFlagSet(DBNameCalcDebugResult, flag.ExitOnError)
+	rewardBreakdownFlagSet := flag.NewFlagSet(DBNameRewardBreakdown, flag.ExitOnError)
+	migrateFlagSet := flag.NewFlagSet(CommandMigrate, flag.ExitOnError)
 
 	manageInput := common.InitManageInput(manageFlagSet)
 	accountInput := common.InitAccountInput(accountFlagSet)
@@ -87,6 +96,8 @@ func Run() (err error) {
 	calcResultInput := common.InitCalcResultInput(calcResultFlagSet)
 	iissInput := common.InitIISS(iissFlagSet)
 	calcDebugInput := common.InitCalcDebugResult(calcDebugFlagSet)
+	rewardBreakdownInput := common.InitRewardBreakdownInput(rewardBreakdownFlagSet)
+	migrateInput := common.InitMigrateInput(migrateFlagSet)
 
 	switch dbName {
 	case DBNameManagement:
@@ -121,6 +132,14 @@ func Run() (err error) {
 		err = calcDebugFlagSet.Parse(os.Args[2:])
 		common.ValidateInput(calcDebugFlagSet, err, calcDebugInput.Help)
 		err = common.QueryCalcDebugDB(*calcDebugInput)
+	case DBNameRewardBreakdown:
+		err = rewardBreakdownFlagSet.Parse(os.Args[2:])
+		common.ValidateInput(rewardBreakdownFlagSet, err, rewardBreakdownInput.Help)
+		err = queryRewardBreakdownDB(*rewardBreakdownInput)
+	case CommandMigrate:
+		err = migrateFlagSet.Parse(os.Args[2:])
+		common.ValidateInput(migrateFlagSet, err, migrateInput.Help)
+		err = migrateDB(*migrateInput)
 	default:
 		printUsage()
 		err = errors.New("invalid dbName")
@@ -9,12 +9,20 @@ import (
 	"github.com/icon-project/rewardcalculator/core"
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 )
 
 func queryAccountDB(input cmdCommon.Input) (err error) {
-	if input.Path != "" {
+	if input.Height != 0 {
+		if input.RcDBRoot == "" {
+			fmt.Println("Enter dbroot to query with block height")
+			return errors.New("invalid db path")
+		}
+		err = queryAccountDBAt(input.RcDBRoot, input.Address, input.Height)
+		return
+	} else if input.Path != "" {
 		err = queryAccountDBWithPath(input.Path, input.Address)
 		return
 	} else if input.RcDBRoot != "" {
@@ -25,12 +33,17 @@ func queryAccountDB(input cmdCommon.Input) (err error) {
 }
 
 func queryAccountDBWithPath(path string, address string) error {
+	return queryAccountDBWithBackend(path, string(db.GoLevelDBBackend), address)
+}
+
+// queryAccountDBWithBackend queries account DB opened with DB backend
+func queryAccountDBWithBackend(path string, backend string, address string) error {
 	if address == "" {
-		err := cmdCommon.PrintDB(path, util.BytesPrefix([]byte(db.PrefixIScore)), printAccount)
+		err := cmdCommon.PrintDBWithBackend(path, backend, util.BytesPrefix([]byte(db.PrefixIScore)), printAccount)
 		return err
 	} else {
 		dir, name := filepath.Split(path)
-		qdb := db.Open(dir, string(db.GoLevelDBBackend), name)
+		qdb := db.Open(dir, backend, name)
 		defer qdb.Close()
 
 		if account, err := getIScoreAccount(qdb, address); err != nil {
@@ -47,7 +60,12 @@ func queryAccountDBWithPath(path string, address string) error {
 }
 
 func queryAccountDBWithRCRoot(rcRoot string, address string, accountDBType string) error {
-	accountDBCount, queryDBSuffix, err := getAccountDBInfo(rcRoot)
+	backend, err := core.ReadDBBackend(rcRoot)
+	if err != nil {
+		fmt.Printf("Failed to read DB backend of %s\n", rcRoot)
+		return err
+	}
+	accountDBCount, queryDBSuffix, err := getAccountDBInfo(rcRoot, backend)
 
 	index := -1
 	if address != "" {
@@ -88,7 +106,81 @@ func queryAccountDBWithRCRoot(rcRoot string, address string, accountDBType strin
 
 	// do query
 	for _, path := range pathSlice {
-		if err = queryAccountDBWithPath(path, address); err != nil {
+		if err = queryAccountDBWithBackend(path, backend, address); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryAccountDBAt queries account DB which has I-Scores calculated at blockHeight.
+// It finds calculate DB, query DB or retained backup account DB with management DB
+func queryAccountDBAt(rcRoot string, address string, blockHeight uint64) error {
+	backend, err := core.ReadDBBackend(rcRoot)
+	if err != nil {
+		fmt.Printf("Failed to read DB backend of %s\n", rcRoot)
+		return err
+	}
+
+	dir, name := filepath.Split(filepath.Clean(rcRoot))
+	mngDB := db.Open(dir, core.ManagementDBBackend, name)
+	defer mngDB.Close()
+
+	dbInfo, err := getDBInfo(mngDB)
+	if err != nil {
+		return err
+	}
+	if dbInfo == nil {
+		return errors.New("invalid management DB")
+	}
+	if dbInfo.Calculating > dbInfo.CalcDone {
+		return fmt.Errorf("calculating %d now", dbInfo.Calculating)
+	}
+
+	queryDBSuffix := 1
+	if dbInfo.QueryDBIsZero {
+		queryDBSuffix = 0
+	}
+
+	var dbName func(index int) string
+	switch blockHeight {
+	case dbInfo.CalcDone:
+		dbName = func(index int) string {
+			return fmt.Sprintf(core.AccountDBNameFormat, index+1, dbInfo.DBCount, 1-queryDBSuffix)
+		}
+	case dbInfo.PrevCalcDone:
+		dbName = func(index int) string {
+			return fmt.Sprintf(core.AccountDBNameFormat, index+1, dbInfo.DBCount, queryDBSuffix)
+		}
+	default:
+		backups, err := core.LoadAccountBackups(mngDB)
+		if err != nil {
+			return err
+		}
+		backup := core.FindAccountBackup(backups, blockHeight)
+		if backup == nil {
+			return fmt.Errorf("there is no account DB calculated at %d", blockHeight)
+		}
+		dbName = backup.DBName
+	}
+
+	pathSlice := make([]string, 0)
+	if address != "" {
+		addr := common.NewAddressFromString(address)
+		pathSlice = append(pathSlice, filepath.Join(rcRoot, dbName(int(addr.ID()[0])%dbInfo.DBCount)))
+	} else {
+		for i := 0; i < dbInfo.DBCount; i++ {
+			pathSlice = append(pathSlice, filepath.Join(rcRoot, dbName(i)))
+		}
+	}
+
+	fmt.Printf("Account DB calculated at %d\n", blockHeight)
+	for _, path := range pathSlice {
+		if _, err = os.Stat(path); err != nil {
+			return err
+		}
+		if err = queryAccountDBWithBackend(path, backend, address); err != nil {
 			return err
 		}
 	}
@@ -142,10 +234,10 @@ func newIScoreAccount(key []byte, value []byte) (account *core.IScoreAccount, er
 	return
 }
 
-func getFirstAccount(dbPath string) (*core.IScoreAccount, error) {
+func getFirstAccount(dbPath string, backend string) (*core.IScoreAccount, error) {
 	var account *core.IScoreAccount
 	dir, name := filepath.Split(dbPath)
-	qdb := db.Open(dir, string(db.GoLevelDBBackend), name)
+	qdb := db.Open(dir, backend, name)
 	defer qdb.Close()
 
 	iter, err := qdb.GetIterator()
@@ -180,7 +272,7 @@ func getAccountDBPathWithIndex(rcRootPath string, index int, dbCount int, suffix
 	return filepath.Join(rcRootPath, name)
 }
 
-func getAccountDBInfo(rcRoot string) (accountDBCount int, queryDBSuffix int, err error) {
+func getAccountDBInfo(rcRoot string, backend string) (accountDBCount int, queryDBSuffix int, err error) {
 	if accountDBCount, err = getAccountDBCount(rcRoot); err != nil {
 		return 0, 0, err
 	}
@@ -188,9 +280,9 @@ func getAccountDBInfo(rcRoot string) (accountDBCount int, queryDBSuffix int, err
 
 	for i := 1; i <= accountDBCount; i++ {
 		dbPath0 := getAccountDBPathWithIndex(rcRoot, i, accountDBCount, 0)
-		account0, err = getFirstAccount(dbPath0)
+		account0, err = getFirstAccount(dbPath0, backend)
 		dbPath1 := getAccountDBPathWithIndex(rcRoot, i, accountDBCount, 1)
-		account1, err = getFirstAccount(dbPath1)
+		account1, err = getFirstAccount(dbPath1, backend)
 		if account0 != nil {
 			break
 		}
@@ -56,6 +56,10 @@ func getCalcResult(qdb db.Database, blockHeight uint64) (*core.CalculationResult
 }
 
 func printCalcResult(key []byte, value []byte) error {
+	// skip CALCULATE_DONE outbox
+	if len(key) > 8 {
+		return nil
+	}
 	if cr, err := newCalcResult(key, value); err != nil {
 		return err
 	} else {
@@ -67,6 +71,9 @@ func printCalcResult(key []byte, value []byte) error {
 func printCalculationResult(cr *core.CalculationResult) {
 	if cr != nil {
 		fmt.Printf("%s\n", cr.String())
+		if !cr.Success {
+			fmt.Printf("\tError: %s\n", core.CalcErrorToString(cr.ErrorCode))
+		}
 	}
 }
 
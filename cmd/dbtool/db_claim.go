@@ -17,6 +17,10 @@ func queryClaimDB(input cmdCommon.Input) (err error) {
 		return errors.New("invalid db path")
 	}
 
+	if input.History {
+		return queryClaimHistoryDB(input)
+	}
+
 	if input.Address == "" {
 		err = cmdCommon.PrintDB(input.Path, util.BytesPrefix([]byte(db.PrefixClaim)), printClaim)
 	} else {
@@ -74,3 +78,42 @@ func newClaim(key []byte, value []byte) (*core.Claim, error) {
 		return claim, nil
 	}
 }
+
+func queryClaimHistoryDB(input cmdCommon.Input) error {
+	// claim history DB is in I-Score DB root
+	rcRoot := filepath.Dir(filepath.Clean(input.Path))
+	backend, err := core.ReadDBBackend(rcRoot)
+	if err != nil {
+		fmt.Printf("Failed to read DB backend of %s\n", rcRoot)
+		return err
+	}
+
+	if input.Address == "" {
+		return cmdCommon.PrintDBWithBackend(input.Path, backend, util.BytesPrefix([]byte(db.PrefixClaimHistory)),
+			printClaimHistory)
+	}
+
+	dir, name := filepath.Split(input.Path)
+	chDB := db.Open(dir, backend, name)
+	defer chDB.Close()
+
+	chList, err := core.ReadClaimHistory(chDB, *common.NewAddressFromString(input.Address), input.From, input.To)
+	if err != nil {
+		fmt.Println("Error while read claim history")
+		return err
+	}
+	for _, ch := range chList {
+		fmt.Printf("%s\n", ch.String())
+	}
+	return nil
+}
+
+func printClaimHistory(key []byte, value []byte) error {
+	if ch, err := core.NewClaimRecordFromBytes(key[len(db.PrefixClaimHistory):], value); err != nil {
+		fmt.Println("Failed to make claim history instance")
+		return err
+	} else {
+		fmt.Printf("%s\n", ch.String())
+		return nil
+	}
+}
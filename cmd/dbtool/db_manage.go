@@ -38,6 +38,10 @@ func queryManagementDB(input cmdCommon.Input) (err error) {
 		if err = queryPC(qdb, ""); err != nil {
 			return
 		}
+		fmt.Println("\n============== Account DB backups ==============")
+		if err = queryAccountBackup(qdb); err != nil {
+			return
+		}
 	case DataTypeDI:
 		if err = queryDBInfo(qdb); err != nil {
 			return
@@ -54,6 +58,10 @@ func queryManagementDB(input cmdCommon.Input) (err error) {
 		if err = queryPC(qdb, input.Address); err != nil {
 			return
 		}
+	case DataTypeAB:
+		if err = queryAccountBackup(qdb); err != nil {
+			return
+		}
 	default:
 		return errors.New("invalid data type")
 	}
@@ -76,20 +84,42 @@ func queryPC(qdb db.Database, address string) error {
 }
 
 func queryDBInfo(qdb db.Database) error {
+	dbInfo, err := getDBInfo(qdb)
+	if err != nil {
+		return err
+	}
+	if dbInfo != nil {
+		fmt.Println(dbInfo.String())
+	}
+	return nil
+}
+
+func getDBInfo(qdb db.Database) (*core.DBInfo, error) {
 	bucket, err := qdb.GetBucket(db.PrefixManagement)
 	if err != nil {
 		fmt.Println("error while getting database info bucket")
-		return err
+		return nil, err
 	}
 	dbInfo := new(core.DBInfo)
 	value, e := bucket.Get(dbInfo.ID())
 	if e != nil {
 		fmt.Println("error while Get value of Database info")
-		return e
+		return nil, e
 	}
 	if err = dbInfo.SetBytes(value); err != nil {
-	} else {
-		fmt.Println(dbInfo.String())
+		return nil, nil
+	}
+	return dbInfo, nil
+}
+
+func queryAccountBackup(qdb db.Database) error {
+	backups, err := core.LoadAccountBackups(qdb)
+	if err != nil {
+		fmt.Println("error while load account DB backups")
+		return err
+	}
+	for _, backup := range backups {
+		fmt.Println(backup.String())
 	}
 	return nil
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	cmdCommon "github.com/icon-project/rewardcalculator/cmd/common"
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+func migrateDB(input cmdCommon.Input) error {
+	if input.RcDBRoot == "" {
+		fmt.Println("Enter dbroot")
+		return errors.New("invalid db path")
+	}
+	if input.OutPath == "" {
+		fmt.Println("Enter out")
+		return errors.New("invalid output path")
+	}
+	if input.Backend != "" {
+		if err := core.CheckDBBackend(input.Backend); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Migrate RC DB %s to %s\n", input.RcDBRoot, input.OutPath)
+	if err := core.MigrateIScoreDB(input.RcDBRoot, input.OutPath, input.DBCount, input.Backend); err != nil {
+		fmt.Printf("Failed to migrate RC DB. %v\n", err)
+		return err
+	}
+	fmt.Printf("Migrated RC DB to %s\n", input.OutPath)
+	return nil
+}
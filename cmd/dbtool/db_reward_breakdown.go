@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	cmdCommon "github.com/icon-project/rewardcalculator/cmd/common"
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/core"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"path/filepath"
+)
+
+func queryRewardBreakdownDB(input cmdCommon.Input) (err error) {
+	if input.Path == "" {
+		fmt.Println("Enter dbPath")
+		return errors.New("invalid db path")
+	}
+
+	// reward breakdown DB is in I-Score DB root
+	rcRoot := filepath.Dir(filepath.Clean(input.Path))
+	backend, err := core.ReadDBBackend(rcRoot)
+	if err != nil {
+		fmt.Printf("Failed to read DB backend of %s\n", rcRoot)
+		return
+	}
+
+	if input.Address != "" {
+		return queryRewardBreakdownOfAddress(input.Path, backend, input.Address, input.Height)
+	}
+
+	if input.Height == 0 {
+		err = cmdCommon.PrintDBWithBackend(input.Path, backend, util.BytesPrefix([]byte(db.PrefixRewardTerm)),
+			printRewardTerm)
+		if err != nil {
+			return
+		}
+		err = cmdCommon.PrintDBWithBackend(input.Path, backend, util.BytesPrefix([]byte(db.PrefixRewardBreakdown)),
+			printRewardBreakdown)
+	} else {
+		err = cmdCommon.PrintDBWithBackend(input.Path, backend, core.RewardBreakdownIteratorPrefix(input.Height),
+			printRewardBreakdown)
+	}
+	return
+}
+
+func queryRewardBreakdownOfAddress(path string, backend string, address string, blockHeight uint64) error {
+	dir, name := filepath.Split(path)
+	qdb := db.Open(dir, backend, name)
+	defer qdb.Close()
+
+	rbList, err := core.ReadRewardBreakdowns(qdb, *common.NewAddressFromString(address), blockHeight)
+	if err != nil {
+		return err
+	}
+	if len(rbList) == 0 {
+		fmt.Printf("There is no reward breakdown of %s\n", address)
+		return nil
+	}
+	for _, rb := range rbList {
+		printRewardBreakdownData(rb)
+	}
+	return nil
+}
+
+func printRewardTerm(key []byte, value []byte) error {
+	fmt.Printf("Calculation: %d, Accounts: %d\n",
+		common.BytesToUint64(key[len(db.PrefixRewardTerm):]), common.BytesToUint64(value))
+	return nil
+}
+
+func printRewardBreakdown(key []byte, value []byte) error {
+	rb, err := core.NewRewardBreakdownFromBytes(key[len(db.PrefixRewardBreakdown):], value)
+	if err != nil {
+		return err
+	}
+	printRewardBreakdownData(rb)
+	return nil
+}
+
+func printRewardBreakdownData(rb *core.RewardBreakdown) {
+	fmt.Printf("BlockHeight: %d, Address: %s, Beta1: %s, Beta2: %s, Beta3: %s, IScore: %s\n",
+		rb.BlockHeight, rb.Address.String(), rb.Beta1.String(), rb.Beta2.String(), rb.Beta3.String(),
+		rb.IScore().String())
+}
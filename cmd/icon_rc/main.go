@@ -39,6 +39,26 @@ func main() {
 	flag.BoolVar(&optVersion, "version", false, "Print version information")
 	flag.StringVar(&cfg.CalcDebugConf, "calculate-debug-conf", "./calculation_debug.json",
 		"calculation debug config file path")
+	flag.StringVar(&cfg.DBBackend, "db-backend", "goleveldb",
+		"I-Score DB backend. goleveldb, badgerdb or boltdb")
+	flag.StringVar(&cfg.IISSBackend, "iissdata-backend", "goleveldb", "IISS data DB backend")
+	flag.StringVar(&cfg.MetricsAddress, "metrics-addr", "", "HTTP address to serve Prometheus metrics. ex) :9100")
+	flag.StringVar(&cfg.QueryAddr, "query-addr", "", "HTTP address to serve read-only JSON-RPC query. ex) :9200")
+	flag.IntVar(&cfg.DBBackups, "db-backups", core.DefaultAccountDBBackups,
+		"The number of backup account DB generations to retain for rollback and query at past calculation")
+	flag.Uint64Var(&cfg.ClaimPeriod, "claim-backup-period", core.DefaultClaimBackupPeriod,
+		"The number of blocks to keep claims in claim backup DB for rollback")
+	flag.IntVar(&cfg.Breakdowns, "reward-breakdowns", 0,
+		"The number of calculations to keep Beta1, Beta2 and Beta3 of all accounts. Disabled if 0")
+	flag.IntVar(&cfg.PRepReports, "prep-reports", 0,
+		"The number of calculations to keep Beta3 and delegators of P-Reps. Disabled if 0")
+	flag.BoolVar(&cfg.MerkleProof, "merkle-proof", false,
+		"Make Merkle root of accounts in calculation and serve QUERY_ISCORE_PROOF")
+	flag.BoolVar(&cfg.CalcDoneAck, "calc-done-ack", false,
+		"Send CALCULATE_DONE again on new connection until ICON Service sends ACK_CALCULATE_DONE")
+	flag.StringVar(&cfg.IpcCert, "ipc-cert", "", "Certificate file for IPC channel with mutual TLS")
+	flag.StringVar(&cfg.IpcKey, "ipc-key", "", "Private key file of -ipc-cert")
+	flag.StringVar(&cfg.IpcCA, "ipc-ca", "", "CA certificate file to verify peer of IPC channel")
 	flag.Parse()
 
 	log.SetFlags(log.Ldate | log.Lmicroseconds | log.Lshortfile)
@@ -24,7 +24,7 @@ func (cli *CLI) read(dbDir string, dbName string) {
 		}
 	}
 
-	iissDB := core.OpenIISSData(path)
+	iissDB := core.OpenIISSData(path, DBType)
 	core.LoadIISSData(iissDB)
 	ReadIISSBP(iissDB)
 	ReadIISSTX(iissDB)
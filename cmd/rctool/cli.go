@@ -35,6 +35,11 @@ func (cli *CLI) printUsage() {
 	fmt.Printf("\t calculate                     Query Calculation status or result\n")
 	fmt.Printf("\t logctx                        Log context information\n")
 	fmt.Printf("\t calculate_debug               Config calculation debugging\n")
+	fmt.Printf("\t proof                         Query I-Score of account with Merkle proof\n")
+	fmt.Printf("\t statehash                     Calculate state hash with account DB. Can run without icon_rc\n")
+	fmt.Printf("\t prep-report                   Read Beta3 and delegators of P-Reps. Can run without icon_rc\n")
+	fmt.Printf("\t simulate                      Simulate calculation with governance variable overrides. Can run without icon_rc\n")
+	fmt.Printf("\t verify-calc                   Verify calculation result of IISS data without updating RC DB. Can run without icon_rc\n")
 }
 
 func (cli *CLI) validateArgs() {
@@ -50,6 +55,34 @@ func (cli *CLI) Run() {
 	address := core.DebugAddress
 	cmd := os.Args[1]
 
+	// commands without icon_rc
+	switch cmd {
+	case "statehash":
+		if err := cli.stateHash(os.Args[2:]); err != nil {
+			fmt.Printf("Failed to handle command. (%+v)\n", err)
+			os.Exit(1)
+		}
+		return
+	case "prep-report":
+		if err := cli.pRepReport(os.Args[2:]); err != nil {
+			fmt.Printf("Failed to handle command. (%+v)\n", err)
+			os.Exit(1)
+		}
+		return
+	case "simulate":
+		if err := cli.simulate(os.Args[2:]); err != nil {
+			fmt.Printf("Failed to handle command. (%+v)\n", err)
+			os.Exit(1)
+		}
+		return
+	case "verify-calc":
+		if err := cli.verifyCalculate(os.Args[2:]); err != nil {
+			fmt.Printf("Failed to handle command. (%+v)\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Connect to server
 	net := "unix"
 	conn, err := ipc.Dial(net, address)
@@ -97,6 +130,8 @@ func (cli *CLI) Run() {
 		err = cli.logCtx()
 	case "calculate_debug":
 		err = cli.calculateDebug(os.Args[2:])
+	case "proof":
+		err = cli.proof(os.Args[2:])
 	default:
 		cli.printUsage()
 		os.Exit(1)
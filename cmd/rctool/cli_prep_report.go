@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	cmdCommon "github.com/icon-project/rewardcalculator/cmd/common"
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+// pRepReport reads Beta3 and delegators of P-Reps from P-Rep report DB offline
+func (cli *CLI) pRepReport(input []string) error {
+	flagSet := flag.NewFlagSet("prep-report", flag.ExitOnError)
+	reportInput := cmdCommon.InitPRepReportInput(flagSet)
+	err := flagSet.Parse(input)
+	cmdCommon.ValidateInput(flagSet, err, reportInput.Help)
+
+	if reportInput.RcDBRoot == "" {
+		flagSet.PrintDefaults()
+		return fmt.Errorf("enter dbroot")
+	}
+
+	blockHeight, reports, err := core.ReadPRepReportDB(reportInput.RcDBRoot, reportInput.Height)
+	if err != nil {
+		return err
+	}
+	if blockHeight == 0 {
+		return fmt.Errorf("there is no P-Rep report")
+	}
+
+	if reportInput.OutPath == "" {
+		fmt.Printf("prep-report command get result of calculation %d:\n%s\n", blockHeight, Display(reports))
+		return nil
+	}
+
+	if err = writePRepReportCSV(reportInput.OutPath, reports); err != nil {
+		return err
+	}
+	fmt.Printf("Write P-Rep report of calculation %d to %s. %d P-Reps\n", blockHeight, reportInput.OutPath, len(reports))
+	return nil
+}
+
+func writePRepReportCSV(path string, reports []*core.PRepReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"blockHeight", "pRep", "beta3", "delegators", "excluded"})
+	for _, pr := range reports {
+		w.Write([]string{
+			strconv.FormatUint(pr.BlockHeight, 10),
+			pr.Address.String(),
+			pr.Beta3.Int.String(),
+			strconv.FormatUint(pr.Delegators, 10),
+			strconv.FormatUint(pr.Excluded, 10),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
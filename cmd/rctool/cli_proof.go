@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+// proof queries I-Score of account with Merkle proof and verifies it
+func (cli *CLI) proof(input []string) error {
+	if len(input) < 1 || len(input) > 2 {
+		return fmt.Errorf("\nUsage: proof <Address> [<calculation block height>]")
+	}
+
+	var req core.QueryIScoreProofRequest
+	if err := req.Address.SetString(input[0]); err != nil {
+		return err
+	}
+	if len(input) == 2 {
+		blockHeight, err := strconv.ParseUint(input[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid block height. (%+v)", err)
+		}
+		req.BlockHeight = blockHeight
+	} else {
+		// use block height of the last calculation
+		var status core.QueryCalculateStatusResponse
+		err := cli.conn.SendAndReceive(core.MsgQueryCalculateStatus, cli.id, nil, &status)
+		if err != nil {
+			return err
+		}
+		req.BlockHeight = status.BlockHeight
+	}
+
+	var resp core.QueryIScoreProofResponse
+	err := cli.conn.SendAndReceive(core.MsgQueryIScoreProof, cli.id, &req, &resp)
+	if err == nil {
+		fmt.Printf("proof command get response:\n%s\n", Display(resp))
+		if resp.Status == core.ProofStatusOK {
+			fmt.Printf("Verify proof with Merkle root: %v\n", resp.Verify(resp.MerkleRoot))
+		} else {
+			fmt.Printf("%s\n", resp.StatusString())
+		}
+	}
+
+	return err
+}
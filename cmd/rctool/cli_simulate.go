@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+// simulate calculates I-Score with IISS data and governance variable overrides without modifying I-Score DB
+func (cli *CLI) simulate(input []string) error {
+	flagSet := flag.NewFlagSet("simulate", flag.ExitOnError)
+	dbRoot := flagSet.String("dbroot", "", "path of RC DB(Required)")
+	iissPath := flagSet.String("iiss", "", "path of IISS data DB to calculate(Required)")
+	iissBackend := flagSet.String("iissdata-backend", "goleveldb", "IISS data DB backend")
+	incentiveRep := flagSet.Uint64("incentive-rep", 0, "IncentiveRep to override")
+	rewardRep := flagSet.Uint64("reward-rep", 0, "RewardRep to override")
+	mainPRepCount := flagSet.Uint64("main-prep-count", 0, "MainPRepCount to override")
+	subPRepCount := flagSet.Uint64("sub-prep-count", 0, "SubPRepCount to override")
+	accounts := flagSet.Bool("accounts", false, "Print I-Score deltas of accounts")
+	help := flagSet.Bool("h", false, "Print help message")
+	err := flagSet.Parse(input)
+	if err != nil || *help {
+		flagSet.PrintDefaults()
+		return err
+	}
+
+	if *dbRoot == "" || *iissPath == "" {
+		flagSet.PrintDefaults()
+		return fmt.Errorf("enter dbroot and iiss")
+	}
+
+	// override governance variables given only
+	gv := new(core.SimulateGV)
+	flagSet.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "incentive-rep":
+			gv.IncentiveRep = incentiveRep
+		case "reward-rep":
+			gv.RewardRep = rewardRep
+		case "main-prep-count":
+			gv.MainPRepCount = mainPRepCount
+		case "sub-prep-count":
+			gv.SubPRepCount = subPRepCount
+		}
+	})
+
+	result, err := core.Simulate(*dbRoot, *iissPath, *iissBackend, gv)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("simulate command get result: calculation %d -> %d\n", result.CalcDoneBH, result.BlockHeight)
+	fmt.Printf("%s\n", result.Stats.String())
+	fmt.Printf("State hash: %s\n", hex.EncodeToString(result.StateHash))
+	fmt.Printf("Accounts with I-Score delta: %d\n", len(result.Accounts))
+	if *accounts {
+		for _, delta := range result.Accounts {
+			fmt.Printf("\t%s: %s -> %s (%s)\n", delta.Address.String(), delta.Before.String(),
+				delta.After.String(), delta.Delta.String())
+		}
+	}
+
+	return nil
+}
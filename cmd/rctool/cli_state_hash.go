@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	cmdCommon "github.com/icon-project/rewardcalculator/cmd/common"
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+const (
+	accountDBTypeQuery     = "query"
+	accountDBTypeCalculate = "calculate"
+)
+
+// stateHash calculates sharding independent state hash with account DB offline
+func (cli *CLI) stateHash(input []string) error {
+	flagSet := flag.NewFlagSet("statehash", flag.ExitOnError)
+	stateHashInput := cmdCommon.InitStateHashInput(flagSet)
+	err := flagSet.Parse(input)
+	cmdCommon.ValidateInput(flagSet, err, stateHashInput.Help)
+
+	if stateHashInput.RcDBRoot == "" {
+		flagSet.PrintDefaults()
+		return fmt.Errorf("enter dbroot")
+	}
+
+	queryDB := false
+	switch stateHashInput.AccountType {
+	case "", accountDBTypeCalculate:
+	case accountDBTypeQuery:
+		queryDB = true
+	default:
+		return fmt.Errorf("invalid account DB type %s", stateHashInput.AccountType)
+	}
+
+	info, err := core.ReadStateHash(stateHashInput.RcDBRoot, queryDB)
+	if err == nil {
+		fmt.Printf("statehash command get result:\n%s\n", Display(info))
+	}
+
+	return err
+}
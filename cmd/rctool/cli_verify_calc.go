@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+// verifyCalculate calculates I-Score with IISS data to throwaway DBs and prints CALCULATE_DONE which CALCULATE would produce
+func (cli *CLI) verifyCalculate(input []string) error {
+	flagSet := flag.NewFlagSet("verify-calc", flag.ExitOnError)
+	dbRoot := flagSet.String("dbroot", "", "path of RC DB(Required)")
+	iissPath := flagSet.String("iiss", "", "path of IISS data DB to calculate(Required)")
+	iissBackend := flagSet.String("iissdata-backend", "goleveldb", "IISS data DB backend")
+	stateHash := flagSet.String("statehash", "", "expected state hash in hex string")
+	help := flagSet.Bool("h", false, "Print help message")
+	err := flagSet.Parse(input)
+	if err != nil || *help {
+		flagSet.PrintDefaults()
+		return err
+	}
+
+	if *dbRoot == "" || *iissPath == "" {
+		flagSet.PrintDefaults()
+		return fmt.Errorf("enter dbroot and iiss")
+	}
+
+	var expected []byte
+	if *stateHash != "" {
+		if expected, err = hex.DecodeString(*stateHash); err != nil {
+			return fmt.Errorf("invalid state hash %s. %v", *stateHash, err)
+		}
+	}
+
+	resp, err := core.VerifyCalculate(*dbRoot, *iissPath, *iissBackend)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("verify-calc command get result: %s\n", resp.String())
+	if resp.Status != core.CalcRespStatusOK || !resp.Success {
+		return fmt.Errorf("failed to calculate")
+	}
+	if expected != nil {
+		if !bytes.Equal(expected, resp.StateHash) {
+			return fmt.Errorf("state hash mismatch. expected %s, calculated %s", *stateHash,
+				hex.EncodeToString(resp.StateHash))
+		}
+		fmt.Printf("State hash matched\n")
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/icon-project/rewardcalculator/common/ipc"
@@ -30,6 +31,7 @@ func (cli *CLI) printUsage() {
 	fmt.Printf("\t version                   Send a VERSION message\n")
 	fmt.Printf("\t init                      Send a INIT message\n")
 	fmt.Printf("\t query                     Send a QUERY message to query I-Score\n")
+	fmt.Printf("\t batch_query               Send a BATCH_QUERY message to query I-Score of accounts\n")
 	fmt.Printf("\t claim                     Send a CLAIM message to claim I-Score\n")
 	fmt.Printf("\t commitclaim               Send a COMMIT_CLAIM message to commit CLAIM message\n")
 	fmt.Printf("\t commitblock               Send a COMMIT_BLOCK message to commit block\n")
@@ -37,6 +39,11 @@ func (cli *CLI) printUsage() {
 	fmt.Printf("\t query_calculate_status    Send a QUERY_CALCULATE_STATUS message\n")
 	fmt.Printf("\t query_calculate_result    Send a QUERY_CALCULATE_RESULT message\n")
 	fmt.Printf("\t rollback                  Send a ROLLBACK message\n")
+	fmt.Printf("\t query_iscore_proof        Send a QUERY_ISCORE_PROOF message to get Merkle proof of I-Score\n")
+	fmt.Printf("\t query_at                  Send a QUERY_AT message to query I-Score at past calculation\n")
+	fmt.Printf("\t query_reward_breakdown    Send a QUERY_REWARD_BREAKDOWN message to query Beta1, Beta2 and Beta3 of calculations\n")
+	fmt.Printf("\t query_claim_history       Send a QUERY_CLAIM_HISTORY message to query claims of account\n")
+	fmt.Printf("\t ack_calculate_done        Send a ACK_CALCULATE_DONE message to delete CALCULATE_DONE in outbox\n")
 	fmt.Printf("\t monitor                   Monitor account in configuration file\n")
 }
 
@@ -62,6 +69,9 @@ func (cli *CLI) Run() {
 	queryCmd := flag.NewFlagSet("query", flag.ExitOnError)
 	queryAddress := queryCmd.String("address", "", "Account address")
 
+	batchQueryCmd := flag.NewFlagSet("batch_query", flag.ExitOnError)
+	batchQueryAddresses := batchQueryCmd.String("addresses", "", "Comma separated account addresses(Required)")
+
 	claimCmd := flag.NewFlagSet("claim", flag.ExitOnError)
 	claimAddress := claimCmd.String("address", "", "Account address")
 	claimBlockHeight := claimCmd.Uint64("blockheight", 0, "Block height")
@@ -87,6 +97,7 @@ func (cli *CLI) Run() {
 	calculateCmd := flag.NewFlagSet("calculate", flag.ExitOnError)
 	calculateIISSData := calculateCmd.String("iissdata", "", "IISS data DB path(Required)")
 	calculateBlockHeight := calculateCmd.Uint64("blockheight", 0, "Block height to calculate. Set 0 if you want current block+1")
+	calculateDryRun := calculateCmd.Bool("dryrun", false, "Calculate to throwaway DBs and get CALCULATE_DONE without updating I-Score DB")
 
 	monitorCmd := flag.NewFlagSet("monitor", flag.ExitOnError)
 	monitorConfig := monitorCmd.String("config", "./monitor.json", "Monitoring configuration file path")
@@ -101,6 +112,26 @@ func (cli *CLI) Run() {
 	rollbackBlockHeight := rollbackCmd.Uint64("blockheight", 0, "Rollback block height(Required)")
 	rollbackBlockHash := rollbackCmd.String("blockhash", "", "Rollback block hash(Required)")
 
+	queryProofCmd := flag.NewFlagSet("query_iscore_proof", flag.ExitOnError)
+	queryProofAddress := queryProofCmd.String("address", "", "Account address(Required)")
+	queryProofBlockHeight := queryProofCmd.Uint64("blockheight", 0, "Calculation block height")
+
+	queryAtCmd := flag.NewFlagSet("query_at", flag.ExitOnError)
+	queryAtAddress := queryAtCmd.String("address", "", "Account address(Required)")
+	queryAtBlockHeight := queryAtCmd.Uint64("blockheight", 0, "Calculation block height")
+
+	queryRBCmd := flag.NewFlagSet("query_reward_breakdown", flag.ExitOnError)
+	queryRBAddress := queryRBCmd.String("address", "", "Account address(Required)")
+	queryRBBlockHeight := queryRBCmd.Uint64("blockheight", 0, "Calculation block height. Query all calculations if 0")
+
+	queryCHCmd := flag.NewFlagSet("query_claim_history", flag.ExitOnError)
+	queryCHAddress := queryCHCmd.String("address", "", "Account address(Required)")
+	queryCHFrom := queryCHCmd.Uint64("from", 0, "Start block height of claims")
+	queryCHTo := queryCHCmd.Uint64("to", 0, "End block height of claims. Query to the latest claim if 0")
+
+	ackCalcDoneCmd := flag.NewFlagSet("ack_calculate_done", flag.ExitOnError)
+	ackCalcDoneBlockHeight := ackCalcDoneCmd.Uint64("blockheight", 0, "Block height of CALCULATE_DONE(Required)")
+
 	// Parse the CLI
 	switch cmd {
 	case "version":
@@ -121,6 +152,12 @@ func (cli *CLI) Run() {
 			queryCmd.PrintDefaults()
 			os.Exit(1)
 		}
+	case "batch_query":
+		err := batchQueryCmd.Parse(os.Args[3:])
+		if err != nil {
+			batchQueryCmd.PrintDefaults()
+			os.Exit(1)
+		}
 	case "claim":
 		err := claimCmd.Parse(os.Args[3:])
 		if err != nil {
@@ -169,6 +206,36 @@ func (cli *CLI) Run() {
 			rollbackCmd.PrintDefaults()
 			os.Exit(1)
 		}
+	case "query_iscore_proof":
+		err := queryProofCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryProofCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "query_at":
+		err := queryAtCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryAtCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "query_reward_breakdown":
+		err := queryRBCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryRBCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "query_claim_history":
+		err := queryCHCmd.Parse(os.Args[3:])
+		if err != nil {
+			queryCHCmd.PrintDefaults()
+			os.Exit(1)
+		}
+	case "ack_calculate_done":
+		err := ackCalcDoneCmd.Parse(os.Args[3:])
+		if err != nil {
+			ackCalcDoneCmd.PrintDefaults()
+			os.Exit(1)
+		}
 	default:
 		cli.printUsage()
 		os.Exit(1)
@@ -183,13 +250,14 @@ func (cli *CLI) Run() {
 	}
 	defer conn.Close()
 
-	// flush READY message
-	for true {
-		var m core.ResponseVersion
-		msg, _, _ := conn.Receive(m)
-		if msg == core.MsgReady {
-			break
-		}
+	// flush READY message and CALCULATE_DONE in outbox
+	doneList, err := core.ReceiveReady(conn)
+	if err != nil {
+		fmt.Printf("Failed to get READY message err=%+v\n", err)
+		os.Exit(1)
+	}
+	for _, done := range doneList {
+		fmt.Printf("Get CALCULATE_DONE in outbox: %s\n", done.String())
 	}
 
 	// Send message to server
@@ -238,6 +306,14 @@ func (cli *CLI) Run() {
 		cli.query(conn, *queryAddress)
 	}
 
+	if batchQueryCmd.Parsed() {
+		if *batchQueryAddresses == "" {
+			batchQueryCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		cli.batchQuery(conn, strings.Split(*batchQueryAddresses, ","))
+	}
+
 	if calculateCmd.Parsed() {
 		if *calculateIISSData == "" {
 			calculateCmd.PrintDefaults()
@@ -246,7 +322,11 @@ func (cli *CLI) Run() {
 		start := time.Now()
 
 		// send CALCULATE message
-		cli.calculate(conn, *calculateIISSData, *calculateBlockHeight)
+		if *calculateDryRun {
+			cli.calculateDryRun(conn, *calculateIISSData, *calculateBlockHeight)
+		} else {
+			cli.calculate(conn, *calculateIISSData, *calculateBlockHeight)
+		}
 
 		end := time.Now()
 		diff := end.Sub(start)
@@ -272,4 +352,44 @@ func (cli *CLI) Run() {
 		}
 		cli.rollback(conn, *rollbackBlockHeight, *rollbackBlockHash)
 	}
+
+	if queryProofCmd.Parsed() {
+		if *queryProofAddress == "" {
+			queryProofCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		cli.queryIScoreProof(conn, *queryProofAddress, *queryProofBlockHeight)
+	}
+
+	if queryAtCmd.Parsed() {
+		if *queryAtAddress == "" {
+			queryAtCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		cli.queryAt(conn, *queryAtAddress, *queryAtBlockHeight)
+	}
+
+	if queryRBCmd.Parsed() {
+		if *queryRBAddress == "" {
+			queryRBCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		cli.queryRewardBreakdown(conn, *queryRBAddress, *queryRBBlockHeight)
+	}
+
+	if queryCHCmd.Parsed() {
+		if *queryCHAddress == "" {
+			queryCHCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		cli.queryClaimHistory(conn, *queryCHAddress, *queryCHFrom, *queryCHTo)
+	}
+
+	if ackCalcDoneCmd.Parsed() {
+		if *ackCalcDoneBlockHeight == 0 {
+			ackCalcDoneCmd.PrintDefaults()
+			os.Exit(1)
+		}
+		cli.ackCalculateDone(conn, *ackCalcDoneBlockHeight)
+	}
 }
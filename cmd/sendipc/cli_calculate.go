@@ -15,7 +15,7 @@ func (cli *CLI) calculate(conn ipc.Connection, iissData string, blockHeight uint
 	req.BlockHeight = blockHeight
 
 	// Send CALCULATE and get response
-	conn.SendAndReceive(core.MsgCalculate, cli.id, &req, &resp)
+	core.SendAndReceive(conn, core.MsgCalculate, cli.id, &req, &resp)
 	fmt.Printf("CALCULATE command get response: %s\n", resp.String())
 	if resp.Status != core.CalcRespStatusOK {
 		return
@@ -26,12 +26,36 @@ func (cli *CLI) calculate(conn ipc.Connection, iissData string, blockHeight uint
 	msg, id, _ := conn.Receive(&respDone)
 	if msg == core.MsgCalculateDone {
 		fmt.Printf("CALCULATE command get calculate result: %s\n", respDone.String())
+		cli.ackCalculateDone(conn, respDone.BlockHeight)
 	} else {
 		fmt.Printf("CALCULATE command get invalid response : (msg:%d, id:%d)\n", msg, id)
 	}
 
 }
 
+func (cli *CLI) calculateDryRun(conn ipc.Connection, iissData string, blockHeight uint64) {
+	var req core.CalculateRequest
+	var resp core.CalculateDryRunResponse
+
+	req.Path = iissData
+	req.BlockHeight = blockHeight
+	req.DryRun = true
+
+	// Send CALCULATE with dry-run and get CALCULATE_DONE which CALCULATE would produce
+	core.SendAndReceive(conn, core.MsgCalculate, cli.id, &req, &resp)
+	fmt.Printf("CALCULATE dry-run command get response: %s\n", resp.String())
+}
+
+func (cli *CLI) ackCalculateDone(conn ipc.Connection, blockHeight uint64) {
+	// Send ACK_CALCULATE_DONE and get response
+	err := core.SendAndReceive(conn, core.MsgAckCalculateDone, cli.id, &blockHeight, nil)
+	if err != nil {
+		fmt.Printf("Failed to send ACK_CALCULATE_DONE. err=%+v\n", err)
+		return
+	}
+	fmt.Printf("ACK_CALCULATE_DONE command get response for %d\n", blockHeight)
+}
+
 func (cli *CLI) queryCalculateStatus(conn ipc.Connection) {
 	var resp core.QueryCalculateStatusResponse
 
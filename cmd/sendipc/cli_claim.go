@@ -42,7 +42,7 @@ func (cli *CLI) claim(conn ipc.Connection, address string, blockHeight uint64, b
 	}
 
 	fmt.Printf("Send CLAIM message: %s\n", req.String())
-	conn.SendAndReceive(core.MsgClaim, cli.id, &req, &resp)
+	core.SendAndReceive(conn, core.MsgClaim, cli.id, &req, &resp)
 	cli.id++
 	fmt.Printf("Get CLAIM response: %s\n", resp.String())
 
@@ -90,7 +90,7 @@ func (cli *CLI) commitClaim(conn ipc.Connection, success bool, address string, b
 	}
 
 	fmt.Printf("Send COMMIT_CLAIM message: %s\n", req.String())
-	conn.SendAndReceive(core.MsgCommitClaim, cli.id, &req, &resp)
+	core.SendAndReceive(conn, core.MsgCommitClaim, cli.id, &req, &resp)
 	fmt.Printf("Get COMMIT_CLAIM ack\n")
 }
 
@@ -112,6 +112,23 @@ func (cli *CLI) commitBlock(conn ipc.Connection, success bool, blockHeight uint6
 	req.BlockHeight = blockHeight
 
 	fmt.Printf("Send COMMIT_BLOCK message: %s\n", req.String())
-	conn.SendAndReceive(core.MsgCommitBlock, cli.id, &req, &resp)
+	core.SendAndReceive(conn, core.MsgCommitBlock, cli.id, &req, &resp)
 	fmt.Printf("Get COMMIT_BLOCK response: %s\n", resp.String())
 }
+
+func (cli *CLI) queryClaimHistory(conn ipc.Connection, address string, from uint64, to uint64) {
+	var req core.QueryClaimHistoryRequest
+	var resp core.QueryClaimHistoryResponse
+
+	req.Address.SetString(address)
+	req.From = from
+	req.To = to
+
+	// Send QUERY_CLAIM_HISTORY and get response
+	conn.SendAndReceive(core.MsgQueryClaimHistory, cli.id, &req, &resp)
+
+	fmt.Printf("QUERY_CLAIM_HISTORY command get response: %s\n", resp.String())
+	for _, entry := range resp.Claims {
+		fmt.Printf("\t%s\n", entry.String())
+	}
+}
@@ -10,6 +10,6 @@ import (
 func (cli *CLI) init(conn ipc.Connection, blockHeight uint64) {
 	var resp core.ResponseInit
 
-	conn.SendAndReceive(core.MsgINIT, cli.id, &blockHeight, &resp)
+	core.SendAndReceive(conn, core.MsgINIT, cli.id, &blockHeight, &resp)
 	fmt.Printf("INIT command get response: %s\n", resp.String())
 }
\ No newline at end of file
@@ -50,10 +50,19 @@ func (cli *CLI) monitor(conn ipc.Connection, configFile string, url string) {
 func (cli *CLI) readAndPush(conn ipc.Connection, targets []monitorTarget, url string) {
 	pusher := push.New(url, "icon_rc")
 
-	for _, target := range targets {
-		// read IScore from RC
-		resp := cli.query(conn, target.Address.String())
-		target.IScore.Set(&resp.IScore.Int)
+	// read IScore of all targets from RC with a BATCH_QUERY message
+	addresses := make([]string, len(targets))
+	for i, target := range targets {
+		addresses[i] = target.Address.String()
+	}
+	resp := cli.batchQuery(conn, addresses)
+	if len(resp.Accounts) != len(targets) {
+		log.Printf("Invalid BATCH_QUERY response. %d accounts for %d targets", len(resp.Accounts), len(targets))
+		return
+	}
+
+	for i, target := range targets {
+		target.IScore.Set(&resp.Accounts[i].IScore.Int)
 
 		// set metric
 		temp := prometheus.NewGauge(prometheus.GaugeOpts{Name: target.Name})
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+func (cli *CLI) queryIScoreProof(conn ipc.Connection, address string, blockHeight uint64) {
+	var req core.QueryIScoreProofRequest
+	var resp core.QueryIScoreProofResponse
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	// Send QUERY_ISCORE_PROOF and get response
+	conn.SendAndReceive(core.MsgQueryIScoreProof, cli.id, &req, &resp)
+
+	fmt.Printf("QUERY_ISCORE_PROOF command get response: %s\n", resp.String())
+	if resp.Status == core.ProofStatusOK {
+		fmt.Printf("Proof: %s\n", Display(resp.Proof))
+		fmt.Printf("Verify proof with Merkle root: %v\n", resp.Verify(resp.MerkleRoot))
+	}
+}
+
+func (cli *CLI) queryAt(conn ipc.Connection, address string, blockHeight uint64) {
+	var req core.QueryAtRequest
+	var resp core.QueryAtResponse
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	// Send QUERY_AT and get response
+	conn.SendAndReceive(core.MsgQueryAt, cli.id, &req, &resp)
+
+	fmt.Printf("QUERY_AT command get response: %s\n", resp.String())
+	if resp.Status == core.QueryAtStatusOK {
+		fmt.Printf("Delegations: %s\n", Display(resp.Delegations))
+	}
+}
+
+func (cli *CLI) queryRewardBreakdown(conn ipc.Connection, address string, blockHeight uint64) {
+	var req core.QueryRewardBreakdownRequest
+	var resp core.QueryRewardBreakdownResponse
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	// Send QUERY_REWARD_BREAKDOWN and get response
+	conn.SendAndReceive(core.MsgQueryRewardBreakdown, cli.id, &req, &resp)
+
+	fmt.Printf("QUERY_REWARD_BREAKDOWN command get response: %s\n", resp.String())
+	for _, rt := range resp.Rewards {
+		fmt.Printf("\t%s\n", rt.String())
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"github.com/icon-project/rewardcalculator/common"
 
 	"github.com/icon-project/rewardcalculator/common/ipc"
@@ -20,4 +21,21 @@ func (cli *CLI) query(conn ipc.Connection, address string) *core.ResponseQuery {
 	fmt.Printf("QUERY command get response: %s\n", resp.String())
 
 	return resp
-}
\ No newline at end of file
+}
+
+func (cli *CLI) batchQuery(conn ipc.Connection, addresses []string) *core.ResponseBatchQuery {
+	addrs := make([]common.Address, len(addresses))
+	resp := new(core.ResponseBatchQuery)
+
+	for i, address := range addresses {
+		addrs[i].SetString(strings.TrimSpace(address))
+	}
+
+	conn.SendAndReceive(core.MsgBatchQuery, cli.id, &addrs, resp)
+	fmt.Printf("BATCH_QUERY command get response: %s\n", resp.String())
+	for _, account := range resp.Accounts {
+		fmt.Printf("\t%s\n", account.String())
+	}
+
+	return resp
+}
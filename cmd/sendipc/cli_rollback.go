@@ -22,6 +22,6 @@ func (cli *CLI) rollback(conn ipc.Connection, blockHeight uint64, blockHash stri
 	req.BlockHash = make([]byte, core.BlockHashSize)
 	copy(req.BlockHash, hash[0:core.BlockHashSize])
 
-	conn.SendAndReceive(core.MsgRollBack, cli.id, &req, &resp)
+	core.SendAndReceive(conn, core.MsgRollBack, cli.id, &req, &resp)
 	fmt.Printf("ROLLBACK command get response: %s\n", resp.String())
 }
\ No newline at end of file
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
 
 	"github.com/dgraph-io/badger"
@@ -20,7 +22,10 @@ func NewBadgerDB(name string, dir string) (*BadgerDB, error) {
 	opts.Dir = dbPath
 	opts.ValueDir = dbPath
 
-	// TODO : badger.openDatabase() use os.Mkdir(). parent dirs must be created
+	// badger.Open() use os.Mkdir(). parent dirs must be created
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
 	db, err := badger.Open(opts)
 
 	if err != nil {
@@ -51,15 +56,21 @@ func (db *BadgerDB) GetBucket(id BucketID) (Bucket, error) {
 }
 
 func (db *BadgerDB) GetIterator() (Iterator, error) {
-	return nil, nil
+	return &badgerIterator{
+		db: db.db,
+	}, nil
 }
 
 func (db *BadgerDB) GetBatch() (Batch, error) {
-	return nil, nil
+	return &badgerBatch{
+		db: db.db,
+	}, nil
 }
 
 func (db *BadgerDB) GetSnapshot() (Snapshot, error) {
-	return nil, nil
+	return &badgerSnapshot{
+		db: db.db,
+	}, nil
 }
 
 func (db *BadgerDB) Close() error {
@@ -86,8 +97,9 @@ func (bucket *badgerBucket) Get(key []byte) ([]byte, error) {
 			if err == badger.ErrKeyNotFound {
 				return nil
 			}
+			return err
 		}
-		value, err = item.Value()
+		value, err = item.ValueCopy(nil)
 		return err
 	})
 	return value, err
@@ -115,3 +127,198 @@ func (bucket *badgerBucket) Delete(key []byte) error {
 		return txn.Delete(ikey)
 	})
 }
+
+//----------------------------------------
+// DBIterator
+
+// badgerRange iterates keys in [start, limit) with badger iterator.
+// limit == nil means no limit.
+type badgerRange struct {
+	iter    *badger.Iterator
+	limit   []byte
+	started bool
+	key     []byte
+	value   []byte
+	err     error
+}
+
+func newBadgerRange(txn *badger.Txn, start []byte, limit []byte) *badgerRange {
+	r := &badgerRange{
+		iter:  txn.NewIterator(badger.DefaultIteratorOptions),
+		limit: limit,
+	}
+	if start == nil {
+		r.iter.Rewind()
+	} else {
+		r.iter.Seek(start)
+	}
+	return r
+}
+
+func (r *badgerRange) next() bool {
+	if r.err != nil {
+		return false
+	}
+	if r.started {
+		r.iter.Next()
+	}
+	r.started = true
+
+	if !r.iter.Valid() {
+		return false
+	}
+	item := r.iter.Item()
+	if r.limit != nil && bytes.Compare(item.Key(), r.limit) >= 0 {
+		return false
+	}
+	r.key = item.KeyCopy(r.key)
+	r.value, r.err = item.ValueCopy(r.value)
+	return r.err == nil
+}
+
+func (r *badgerRange) close() {
+	r.iter.Close()
+}
+
+var _ Iterator = (*badgerIterator)(nil)
+
+type badgerIterator struct {
+	txn *badger.Txn
+	r   *badgerRange
+	db  *badger.DB
+}
+
+func (i *badgerIterator) New(start []byte, limit []byte) {
+	i.txn = i.db.NewTransaction(false)
+	i.r = newBadgerRange(i.txn, start, limit)
+}
+
+func (i *badgerIterator) Next() bool {
+	return i.r.next()
+}
+
+func (i *badgerIterator) Key() []byte {
+	return i.r.key
+}
+
+func (i *badgerIterator) Value() []byte {
+	return i.r.value
+}
+
+func (i *badgerIterator) Release() {
+	i.r.close()
+	i.txn.Discard()
+}
+
+func (i *badgerIterator) Error() error {
+	return i.r.err
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*badgerBatch)(nil)
+
+type badgerBatchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type badgerBatch struct {
+	ops []badgerBatchOp
+	db  *badger.DB
+}
+
+func (b *badgerBatch) New() {
+	b.ops = make([]badgerBatchOp, 0)
+}
+
+func (b *badgerBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *badgerBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, badgerBatchOp{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	})
+}
+
+func (b *badgerBatch) Delete(key []byte) {
+	b.ops = append(b.ops, badgerBatchOp{key: append([]byte(nil), key...), delete: true})
+}
+
+// Write commits batch with one transaction, so all or nothing is written like other backends.
+// It returns badger.ErrTxnTooBig if batch doesn't fit into a transaction. Writers must split entries with batch count
+func (b *badgerBatch) Write() error {
+	txn := b.db.NewTransaction(true)
+	defer txn.Discard()
+
+	for _, op := range b.ops {
+		var err error
+		if op.delete {
+			err = txn.Delete(op.key)
+		} else {
+			err = txn.Set(op.key, op.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return txn.Commit(nil)
+}
+
+func (b *badgerBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+//----------------------------------------
+// Snapshot
+
+var _ Snapshot = (*badgerSnapshot)(nil)
+
+type badgerSnapshot struct {
+	txn *badger.Txn
+	r   *badgerRange
+	db  *badger.DB
+}
+
+func (s *badgerSnapshot) New() error {
+	s.txn = s.db.NewTransaction(false)
+	return nil
+}
+
+func (s *badgerSnapshot) Get(key []byte) ([]byte, error) {
+	item, err := s.txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+func (s *badgerSnapshot) NewIterator(start []byte, limit []byte) {
+	s.r = newBadgerRange(s.txn, start, limit)
+}
+
+func (s *badgerSnapshot) IterNext() bool {
+	return s.r.next()
+}
+
+func (s *badgerSnapshot) IterKey() []byte {
+	return s.r.key
+}
+
+func (s *badgerSnapshot) IterValue() []byte {
+	return s.r.value
+}
+
+func (s *badgerSnapshot) ReleaseIterator() {
+	s.r.close()
+}
+
+func (s *badgerSnapshot) Release() {
+	s.txn.Discard()
+}
@@ -1,11 +1,14 @@
 package db
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/dgraph-io/badger"
 	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 func TestBadgerDB_Database(t *testing.T) {
@@ -32,3 +35,151 @@ func TestBadgerDB_Database(t *testing.T) {
 	result, _ = bucket.Get(key)
 	assert.Nil(t, result, "empty")
 }
+
+func TestBadgerDB_Iterator(t *testing.T) {
+	tests := []struct {
+		key   []byte
+		value []byte
+	}{
+		{key: []byte("key0"), value: []byte("value0")},
+		{key: []byte("key1"), value: []byte("value1")},
+	}
+	dir, err := ioutil.TempDir("", "badgerdb")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testDB := openDatabase(BadgerDBBackend, "test", dir)
+	defer testDB.Close()
+
+	bucket, _ := testDB.GetBucket("")
+	for _, tt := range tests {
+		bucket.Set(tt.key, tt.value)
+	}
+	other, _ := testDB.GetBucket("XX")
+	other.Set([]byte("key2"), []byte("value2"))
+
+	iter, _ := testDB.GetIterator()
+	prefix := util.BytesPrefix([]byte("key"))
+	iter.New(prefix.Start, prefix.Limit)
+	i := 0
+	for ; iter.Next(); i++ {
+		assert.Equal(t, tests[i].key, iter.Key())
+		assert.Equal(t, tests[i].value, iter.Value())
+	}
+	iter.Release()
+	assert.NoError(t, iter.Error())
+	assert.Equal(t, len(tests), i)
+
+	// iterate all
+	iter.New(nil, nil)
+	for i = 0; iter.Next(); i++ {
+	}
+	iter.Release()
+	assert.Equal(t, len(tests)+1, i)
+}
+
+func TestBadgerDB_Batch(t *testing.T) {
+	tests := []struct {
+		key   []byte
+		value []byte
+	}{
+		{key: []byte("key0"), value: []byte("value0")},
+		{key: []byte("key1"), value: []byte("value1")},
+	}
+	dir, err := ioutil.TempDir("", "badgerdb")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testDB := openDatabase(BadgerDBBackend, "test", dir)
+	defer testDB.Close()
+
+	batch, _ := testDB.GetBatch()
+	batch.New()
+	for _, tt := range tests {
+		batch.Set(tt.key, tt.value)
+	}
+
+	assert.Equal(t, len(tests), batch.Len())
+
+	batch.Delete(tests[0].key)
+	assert.Equal(t, len(tests)+1, batch.Len())
+
+	assert.NoError(t, batch.Write())
+	batch.Reset()
+	assert.Equal(t, 0, batch.Len())
+
+	bucket, _ := testDB.GetBucket("")
+	assert.False(t, bucket.Has(tests[0].key), "False")
+	assert.True(t, bucket.Has(tests[1].key), "True")
+}
+
+func TestBadgerDB_BatchTooBig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badgerdb")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testDB := openDatabase(BadgerDBBackend, "test", dir)
+	defer testDB.Close()
+
+	// batch which doesn't fit into a transaction is not written at all
+	count := testDB.(*BadgerDB).db.MaxBatchCount() + 1
+	batch, _ := testDB.GetBatch()
+	batch.New()
+	for i := int64(0); i < count; i++ {
+		batch.Set([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+	}
+	assert.Equal(t, badger.ErrTxnTooBig, batch.Write())
+
+	bucket, _ := testDB.GetBucket("")
+	assert.False(t, bucket.Has([]byte("key0")))
+}
+
+func TestBadgerDB_Snapshot(t *testing.T) {
+	tests := []struct {
+		key   []byte
+		value []byte
+	}{
+		{key: []byte("key0"), value: []byte("value0")},
+		{key: []byte("key1"), value: []byte("value1")},
+	}
+	dir, err := ioutil.TempDir("", "badgerdb")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testDB := openDatabase(BadgerDBBackend, "test", dir)
+	defer testDB.Close()
+
+	bucket, _ := testDB.GetBucket("")
+	for _, tt := range tests {
+		bucket.Set(tt.key, tt.value)
+	}
+
+	snapshot, _ := testDB.GetSnapshot()
+	assert.NoError(t, snapshot.New())
+
+	bucket.Set(tests[0].key, []byte("NEW_VALUE"))
+	bucket.Set([]byte("key2"), []byte("value2"))
+
+	value, _ := snapshot.Get(tests[0].key)
+	assert.Equal(t, tests[0].value, value)
+	value, _ = snapshot.Get([]byte("key2"))
+	assert.Nil(t, value)
+
+	snapshot.NewIterator(nil, nil)
+	i := 0
+	for ; snapshot.IterNext(); i++ {
+		assert.Equal(t, tests[i].key, snapshot.IterKey())
+		assert.Equal(t, tests[i].value, snapshot.IterValue())
+	}
+	assert.Equal(t, len(tests), i)
+	snapshot.ReleaseIterator()
+	snapshot.Release()
+}
@@ -1,11 +1,26 @@
 package db
 
 import (
+	"bytes"
+	"os"
 	"path/filepath"
 
 	bolt "go.etcd.io/bbolt"
 )
 
+const (
+	boltDBFileName   = "bolt.db"
+	boltIterChunkLen = 1024
+
+	// Write transaction waits for read transactions to remap DB file.
+	// Map enough size initially so snapshot does not block writing
+	boltInitialMmapSize = 1 << 30
+)
+
+// All buckets share one bolt bucket with prefixed keys as other backends do,
+// so iterators and batches use the same key space as goleveldb.
+var boltRootBucket = []byte("default")
+
 func init() {
 	dbCreator := func(name string, dir string) (Database, error) {
 		return NewBoltDB(name, dir)
@@ -14,11 +29,25 @@ func init() {
 }
 
 func NewBoltDB(name string, dir string) (*BoltDB, error) {
-	dbPath := filepath.Join(dir, name+".db")
-	db, err := bolt.Open(dbPath, 0644, nil)
+	// make directory for DB file. account DB is moved with directory
+	dbDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(dbDir, 0755); err != nil {
+		return nil, err
+	}
+	opts := *bolt.DefaultOptions
+	opts.InitialMmapSize = boltInitialMmapSize
+	db, err := bolt.Open(filepath.Join(dbDir, boltDBFileName), 0644, &opts)
 	if err != nil {
 		return nil, err
 	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltRootBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 	database := &BoltDB{
 		db: db,
 	}
@@ -39,24 +68,25 @@ type BoltDB struct {
 }
 
 func (db *BoltDB) GetBucket(id BucketID) (Bucket, error) {
-	// create bucket
-	err := db.db.Update(func(tx *bolt.Tx) error {
-		_, err := tx.CreateBucketIfNotExists([]byte(id))
-		return err
-	})
-	return &boltBucket{db: db.db, id: id}, err
+	return &boltBucket{db: db.db, id: id}, nil
 }
 
 func (db *BoltDB) GetIterator() (Iterator, error) {
-	return nil, nil
+	return &boltIterator{
+		boltRange{db: db.db},
+	}, nil
 }
 
 func (db *BoltDB) GetBatch() (Batch, error) {
-	return nil, nil
+	return &boltBatch{
+		db: db.db,
+	}, nil
 }
 
 func (db *BoltDB) GetSnapshot() (Snapshot, error) {
-	return nil, nil
+	return &boltSnapshot{
+		db: db.db,
+	}, nil
 }
 
 func (db *BoltDB) Close() error {
@@ -77,7 +107,10 @@ type boltBucket struct {
 func (bucket *boltBucket) Get(key []byte) ([]byte, error) {
 	var value []byte
 	err := bucket.db.View(func(tx *bolt.Tx) error {
-		value = tx.Bucket([]byte(bucket.id)).Get(key)
+		v := tx.Bucket(boltRootBucket).Get(internalKey(bucket.id, key))
+		if v != nil {
+			value = append([]byte(nil), v...)
+		}
 		return nil
 	})
 	return value, err
@@ -93,18 +126,241 @@ func (bucket *boltBucket) Has(key []byte) bool {
 
 func (bucket *boltBucket) Set(key []byte, value []byte) error {
 	err := bucket.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(bucket.id))
-		err := bucket.Put(key, value)
-		return err
+		return tx.Bucket(boltRootBucket).Put(internalKey(bucket.id, key), value)
 	})
 	return err
 }
 
 func (bucket *boltBucket) Delete(key []byte) error {
 	err := bucket.db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte(bucket.id))
-		err := bucket.Delete(key)
-		return err
+		return tx.Bucket(boltRootBucket).Delete(internalKey(bucket.id, key))
+	})
+	return err
+}
+
+//----------------------------------------
+// DBIterator
+
+// boltRange iterates keys in [start, limit). limit == nil means no limit.
+// Bolt read transaction blocks remapping of write transaction in same goroutine,
+// so boltRange reads entries by chunk with short read transactions
+// instead of holding a transaction while iterating.
+type boltRange struct {
+	tx     *bolt.Tx // iterate with tx if not nil
+	db     *bolt.DB
+	next   []byte
+	limit  []byte
+	keys   [][]byte
+	values [][]byte
+	index  int
+	done   bool
+	err    error
+}
+
+func (r *boltRange) init(start []byte, limit []byte) {
+	r.next = start
+	r.limit = limit
+	r.keys = nil
+	r.values = nil
+	r.index = 0
+	r.done = false
+	r.err = nil
+}
+
+func (r *boltRange) fill(tx *bolt.Tx) error {
+	c := tx.Bucket(boltRootBucket).Cursor()
+	var k, v []byte
+	if r.next == nil {
+		k, v = c.First()
+	} else {
+		k, v = c.Seek(r.next)
+	}
+	for ; k != nil && len(r.keys) < boltIterChunkLen; k, v = c.Next() {
+		if r.limit != nil && bytes.Compare(k, r.limit) >= 0 {
+			r.done = true
+			return nil
+		}
+		r.keys = append(r.keys, append([]byte(nil), k...))
+		r.values = append(r.values, append([]byte(nil), v...))
+	}
+	if k == nil {
+		r.done = true
+	} else {
+		r.next = append([]byte(nil), k...)
+	}
+	return nil
+}
+
+func (r *boltRange) nextEntry() bool {
+	if r.err != nil {
+		return false
+	}
+	r.index++
+	if r.index < len(r.keys) {
+		return true
+	}
+	if r.done {
+		return false
+	}
+
+	r.keys = r.keys[:0]
+	r.values = r.values[:0]
+	r.index = 0
+	if r.tx != nil {
+		r.err = r.fill(r.tx)
+	} else {
+		r.err = r.db.View(r.fill)
+	}
+	return r.err == nil && len(r.keys) > 0
+}
+
+func (r *boltRange) key() []byte {
+	return r.keys[r.index]
+}
+
+func (r *boltRange) value() []byte {
+	return r.values[r.index]
+}
+
+var _ Iterator = (*boltIterator)(nil)
+
+type boltIterator struct {
+	boltRange
+}
+
+func (i *boltIterator) New(start []byte, limit []byte) {
+	i.init(start, limit)
+	i.index = -1
+}
+
+func (i *boltIterator) Next() bool {
+	return i.nextEntry()
+}
+
+func (i *boltIterator) Key() []byte {
+	return i.key()
+}
+
+func (i *boltIterator) Value() []byte {
+	return i.value()
+}
+
+func (i *boltIterator) Release() {
+	i.keys = nil
+	i.values = nil
+}
+
+func (i *boltIterator) Error() error {
+	return i.err
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*boltBatch)(nil)
+
+type boltBatchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+type boltBatch struct {
+	ops []boltBatchOp
+	db  *bolt.DB
+}
+
+func (b *boltBatch) New() {
+	b.ops = make([]boltBatchOp, 0)
+}
+
+func (b *boltBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *boltBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, boltBatchOp{
+		key:   append([]byte(nil), key...),
+		value: append([]byte(nil), value...),
+	})
+}
+
+func (b *boltBatch) Delete(key []byte) {
+	b.ops = append(b.ops, boltBatchOp{key: append([]byte(nil), key...), delete: true})
+}
+
+func (b *boltBatch) Write() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltRootBucket)
+		for _, op := range b.ops {
+			var err error
+			if op.delete {
+				err = bucket.Delete(op.key)
+			} else {
+				err = bucket.Put(op.key, op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
+}
+
+func (b *boltBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+//----------------------------------------
+// Snapshot
+
+var _ Snapshot = (*boltSnapshot)(nil)
+
+// boltSnapshot holds a read transaction until Release().
+// Writing may wait for Release() if DB file grows over boltInitialMmapSize
+type boltSnapshot struct {
+	tx *bolt.Tx
+	r  boltRange
+	db *bolt.DB
+}
+
+func (s *boltSnapshot) New() error {
+	var err error
+	s.tx, err = s.db.Begin(false)
 	return err
 }
+
+func (s *boltSnapshot) Get(key []byte) ([]byte, error) {
+	v := s.tx.Bucket(boltRootBucket).Get(key)
+	if v == nil {
+		return nil, nil
+	}
+	return append([]byte(nil), v...), nil
+}
+
+func (s *boltSnapshot) NewIterator(start []byte, limit []byte) {
+	s.r = boltRange{tx: s.tx, db: s.db}
+	s.r.init(start, limit)
+	s.r.index = -1
+}
+
+func (s *boltSnapshot) IterNext() bool {
+	return s.r.nextEntry()
+}
+
+func (s *boltSnapshot) IterKey() []byte {
+	return s.r.key()
+}
+
+func (s *boltSnapshot) IterValue() []byte {
+	return s.r.value()
+}
+
+func (s *boltSnapshot) ReleaseIterator() {
+	s.r.keys = nil
+	s.r.values = nil
+}
+
+func (s *boltSnapshot) Release() {
+	s.tx.Rollback()
+}
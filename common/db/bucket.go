@@ -19,6 +19,9 @@ const (
 	// For calculation result DB
 	PrefixCalcResult BucketID         = ""
 
+	// CALCULATE_DONE not acknowledged by peer
+	PrefixCalcDoneOutbox BucketID     = "CO"
+
 	// For claim DB
 	PrefixClaim BucketID              = ""
 
@@ -33,9 +36,39 @@ const (
 	// P-Rep candidate list
 	PrefixPRepCandidate BucketID      = "PC"
 
+	// P-Rep candidate changes for rollback
+	PrefixPRepCandidateLog BucketID   = "PL"
+
 	// Main/Sub P-Rep list
 	PrefixPRep BucketID               = "PR"
 
+	// Backup account DB generations
+	PrefixAccountBackup BucketID      = "AB"
+
+	// Calculation and rollback in progress
+	PrefixJournal BucketID            = "JN"
+
+	// For reward breakdown DB
+	// Beta1, Beta2 and Beta3 of account in a calculation
+	PrefixRewardBreakdown BucketID    = "RB"
+
+	// Calculations in reward breakdown DB
+	PrefixRewardTerm BucketID         = "RT"
+
+	// For P-Rep report DB
+	// Beta3 and delegators of P-Rep in a calculation
+	PrefixPRepReport BucketID         = "PP"
+
+	// Calculations in P-Rep report DB
+	PrefixPRepReportTerm BucketID     = "PT"
+
+	// For claim history DB
+	// Claims of address in block height order
+	PrefixClaimHistory BucketID       = "CH"
+
+	// Claims in block height order to rollback
+	PrefixClaimHistoryIndex BucketID  = "CI"
+
 	// FOR IISS data DB
 	// Header
 	PrefixIISSHeader BucketID         = "HD"
@@ -1,22 +1,26 @@
 package db
 
 import (
-	"github.com/pkg/errors"
+	"bytes"
+	"sort"
 	"sync"
+
+	"github.com/pkg/errors"
 )
 
+// layerBucket reads and writes data of layerDB with prefixed keys
 type layerBucket struct {
-	lock sync.Mutex
-	data map[string][]byte
+	id   BucketID
 	real Bucket
+	ldb  *layerDB
 }
 
 func (bk *layerBucket) Get(key []byte) ([]byte, error) {
-	bk.lock.Lock()
-	defer bk.lock.Unlock()
+	bk.ldb.lock.Lock()
+	defer bk.ldb.lock.Unlock()
 
-	if bk.data != nil {
-		if value, ok := bk.data[string(key)]; ok {
+	if bk.ldb.data != nil {
+		if value, ok := bk.ldb.data[string(internalKey(bk.id, key))]; ok {
 			return value, nil
 		}
 	}
@@ -24,11 +28,11 @@ func (bk *layerBucket) Get(key []byte) ([]byte, error) {
 }
 
 func (bk *layerBucket) Has(key []byte) bool {
-	bk.lock.Lock()
-	defer bk.lock.Unlock()
+	bk.ldb.lock.Lock()
+	defer bk.ldb.lock.Unlock()
 
-	if bk.data != nil {
-		if value, ok := bk.data[string(key)]; ok {
+	if bk.ldb.data != nil {
+		if value, ok := bk.ldb.data[string(internalKey(bk.id, key))]; ok {
 			return value != nil
 		}
 	}
@@ -40,13 +44,13 @@ func (bk *layerBucket) Set(key []byte, value []byte) error {
 		return errors.New("IllegalArgument")
 	}
 
-	bk.lock.Lock()
-	defer bk.lock.Unlock()
+	bk.ldb.lock.Lock()
+	defer bk.ldb.lock.Unlock()
 
-	if bk.data != nil {
+	if bk.ldb.data != nil {
 		v2 := make([]byte, len(value))
 		copy(v2, value)
-		bk.data[string(key)] = v2
+		bk.ldb.data[string(internalKey(bk.id, key))] = v2
 		return nil
 	} else {
 		return bk.real.Set(key, value)
@@ -54,75 +58,59 @@ func (bk *layerBucket) Set(key []byte, value []byte) error {
 }
 
 func (bk *layerBucket) Delete(key []byte) error {
-	bk.lock.Lock()
-	defer bk.lock.Unlock()
+	bk.ldb.lock.Lock()
+	defer bk.ldb.lock.Unlock()
 
-	if bk.data != nil {
-		bk.data[string(key)] = nil
+	if bk.ldb.data != nil {
+		bk.ldb.data[string(internalKey(bk.id, key))] = nil
 		return nil
 	} else {
 		return bk.real.Delete(key)
 	}
 }
 
-func (bk *layerBucket) Flush(write bool) error {
-	bk.lock.Lock()
-	defer bk.lock.Unlock()
-
-	if write && bk.data != nil {
-		for k, v := range bk.data {
-			if v == nil {
-				if err := bk.real.Delete([]byte(k)); err != nil {
-					return err
-				}
-			} else {
-				if err := bk.real.Set([]byte(k), v); err != nil {
-					return err
-				}
-			}
-		}
-	}
-	bk.data = nil
-	return nil
-}
-
+// layerDB keeps data written to real DB in memory until Flush.
+// Data are kept with prefixed keys as other backends do, so iterators and batches use the same key space
 type layerDB struct {
 	lock sync.Mutex
 
-	flushed bool
-	real    Database
-	buckets map[string]*layerBucket
+	// nil after Flush. nil value is deleted data
+	data map[string][]byte
+	real Database
 }
 
 func (ldb *layerDB) GetBucket(id BucketID) (Bucket, error) {
-	ldb.lock.Lock()
-	defer ldb.lock.Unlock()
-
-	if bk, ok := ldb.buckets[string(id)]; ok {
-		return bk, nil
-	}
-
 	realbk, err := ldb.real.GetBucket(id)
 	if err != nil {
 		return nil, err
 	}
-	if ldb.flushed {
-		return realbk, nil
-	}
-	bk := &layerBucket{
-		data: make(map[string][]byte),
+	return &layerBucket{
+		id:   id,
 		real: realbk,
-	}
-	ldb.buckets[string(id)] = bk
-	return bk, nil
+		ldb:  ldb,
+	}, nil
 }
 
 func (ldb *layerDB) GetIterator() (Iterator, error) {
-	return nil, nil
+	iter, err := ldb.real.GetIterator()
+	if err != nil {
+		return nil, err
+	}
+	return &layerIterator{
+		real: iter,
+		ldb:  ldb,
+	}, nil
 }
 
-func (db *layerDB) GetBatch() (Batch, error) {
-	return nil, nil
+func (ldb *layerDB) GetBatch() (Batch, error) {
+	batch, err := ldb.real.GetBatch()
+	if err != nil {
+		return nil, err
+	}
+	return &layerBatch{
+		real: batch,
+		ldb:  ldb,
+	}, nil
 }
 
 func (db *layerDB) GetSnapshot() (Snapshot, error) {
@@ -133,12 +121,24 @@ func (ldb *layerDB) Flush(write bool) error {
 	ldb.lock.Lock()
 	defer ldb.lock.Unlock()
 
-	for _, bk := range ldb.buckets {
-		if err := bk.Flush(write); err != nil {
+	if write && ldb.data != nil {
+		bk, err := ldb.real.GetBucket("")
+		if err != nil {
 			return err
 		}
+		for k, v := range ldb.data {
+			if v == nil {
+				if err := bk.Delete([]byte(k)); err != nil {
+					return err
+				}
+			} else {
+				if err := bk.Set([]byte(k), v); err != nil {
+					return err
+				}
+			}
+		}
 	}
-	ldb.flushed = true
+	ldb.data = nil
 	return nil
 }
 
@@ -148,7 +148,159 @@ func (ldb *layerDB) Close() error {
 
 func NewLayerDB(dbase Database) LayerDB {
 	return &layerDB{
-		real:    dbase,
-		buckets: make(map[string]*layerBucket),
+		data: make(map[string][]byte),
+		real: dbase,
 	}
 }
+
+//----------------------------------------
+// DBIterator
+
+var _ Iterator = (*layerIterator)(nil)
+
+// layerIterator merges data of layerDB copied when New() is called into iteration of real DB
+type layerIterator struct {
+	real     Iterator
+	realNext bool
+
+	keys   [][]byte
+	values [][]byte
+	index  int
+
+	key   []byte
+	value []byte
+	ldb   *layerDB
+}
+
+func (i *layerIterator) New(start []byte, limit []byte) {
+	i.ldb.lock.Lock()
+	i.keys = make([][]byte, 0)
+	for k := range i.ldb.data {
+		if start != nil && bytes.Compare([]byte(k), start) < 0 {
+			continue
+		}
+		if limit != nil && bytes.Compare([]byte(k), limit) >= 0 {
+			continue
+		}
+		i.keys = append(i.keys, []byte(k))
+	}
+	sort.Slice(i.keys, func(a, b int) bool {
+		return bytes.Compare(i.keys[a], i.keys[b]) < 0
+	})
+	i.values = make([][]byte, len(i.keys))
+	for index, k := range i.keys {
+		i.values[index] = i.ldb.data[string(k)]
+	}
+	i.ldb.lock.Unlock()
+	i.index = 0
+
+	i.real.New(start, limit)
+	i.realNext = i.real.Next()
+}
+
+func (i *layerIterator) Next() bool {
+	for {
+		hasLayer := i.index < len(i.keys)
+		if !hasLayer && !i.realNext {
+			return false
+		}
+
+		if hasLayer && (!i.realNext || bytes.Compare(i.keys[i.index], i.real.Key()) <= 0) {
+			// data of layer overrides data of real DB
+			if i.realNext && bytes.Equal(i.keys[i.index], i.real.Key()) {
+				i.realNext = i.real.Next()
+			}
+			i.key = i.keys[i.index]
+			i.value = i.values[i.index]
+			i.index++
+			if i.value == nil {
+				continue
+			}
+			return true
+		}
+
+		i.key = append([]byte(nil), i.real.Key()...)
+		i.value = append([]byte(nil), i.real.Value()...)
+		i.realNext = i.real.Next()
+		return true
+	}
+}
+
+func (i *layerIterator) Key() []byte {
+	return i.key
+}
+
+func (i *layerIterator) Value() []byte {
+	return i.value
+}
+
+func (i *layerIterator) Release() {
+	i.real.Release()
+	i.keys = nil
+	i.values = nil
+}
+
+func (i *layerIterator) Error() error {
+	return i.real.Error()
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*layerBatch)(nil)
+
+type layerBatchOp struct {
+	key   string
+	value []byte
+}
+
+// layerBatch writes to layerDB. It writes to real DB with batch of real DB after Flush
+type layerBatch struct {
+	ops  []layerBatchOp
+	real Batch
+	ldb  *layerDB
+}
+
+func (b *layerBatch) New() {
+	b.ops = make([]layerBatchOp, 0)
+}
+
+func (b *layerBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *layerBatch) Set(key, value []byte) {
+	v2 := make([]byte, len(value))
+	copy(v2, value)
+	b.ops = append(b.ops, layerBatchOp{key: string(key), value: v2})
+}
+
+func (b *layerBatch) Delete(key []byte) {
+	b.ops = append(b.ops, layerBatchOp{key: string(key)})
+}
+
+func (b *layerBatch) Write() error {
+	b.ldb.lock.Lock()
+	defer b.ldb.lock.Unlock()
+
+	if b.ldb.data != nil {
+		for _, op := range b.ops {
+			b.ldb.data[op.key] = op.value
+		}
+		return nil
+	}
+
+	b.real.New()
+	for _, op := range b.ops {
+		if op.value == nil {
+			b.real.Delete([]byte(op.key))
+		} else {
+			b.real.Set([]byte(op.key), op.value)
+		}
+	}
+	return b.real.Write()
+}
+
+func (b *layerBatch) Reset() {
+	b.ops = b.ops[:0]
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+func TestLayerDB_Database(t *testing.T) {
+	realDB := NewMapDB()
+	realBucket, _ := realDB.GetBucket("XX")
+	realBucket.Set([]byte("key0"), []byte("value0"))
+
+	testDB := NewLayerDB(realDB)
+	bucket, _ := testDB.GetBucket("XX")
+	bucket.Set([]byte("key1"), []byte("value1"))
+	bucket.Delete([]byte("key0"))
+
+	result, _ := bucket.Get([]byte("key1"))
+	assert.Equal(t, []byte("value1"), result)
+	assert.False(t, bucket.Has([]byte("key0")))
+
+	// real DB is not changed
+	assert.False(t, realBucket.Has([]byte("key1")))
+	assert.True(t, realBucket.Has([]byte("key0")))
+
+	// discard
+	assert.NoError(t, testDB.Flush(false))
+	assert.False(t, bucket.Has([]byte("key1")))
+	assert.True(t, bucket.Has([]byte("key0")))
+}
+
+func TestLayerDB_Iterator(t *testing.T) {
+	tests := []struct {
+		key   []byte
+		value []byte
+	}{
+		{key: []byte("key0"), value: []byte("value0")},
+		{key: []byte("key1"), value: []byte("NEW_VALUE")},
+		{key: []byte("key3"), value: []byte("value3")},
+		{key: []byte("key4"), value: []byte("value4")},
+	}
+	realDB := NewMapDB()
+	realBucket, _ := realDB.GetBucket("")
+	realBucket.Set([]byte("key0"), []byte("value0"))
+	realBucket.Set([]byte("key1"), []byte("value1"))
+	realBucket.Set([]byte("key2"), []byte("value2"))
+	realBucket.Set([]byte("key4"), []byte("value4"))
+
+	testDB := NewLayerDB(realDB)
+	bucket, _ := testDB.GetBucket("")
+	bucket.Set([]byte("key1"), []byte("NEW_VALUE"))
+	bucket.Delete([]byte("key2"))
+	bucket.Set([]byte("key3"), []byte("value3"))
+	other, _ := testDB.GetBucket("XX")
+	other.Set([]byte("key5"), []byte("value5"))
+
+	iter, _ := testDB.GetIterator()
+	prefix := util.BytesPrefix([]byte("key"))
+	iter.New(prefix.Start, prefix.Limit)
+	i := 0
+	for ; iter.Next(); i++ {
+		assert.Equal(t, tests[i].key, iter.Key())
+		assert.Equal(t, tests[i].value, iter.Value())
+	}
+	iter.Release()
+	assert.NoError(t, iter.Error())
+	assert.Equal(t, len(tests), i)
+
+	// iterate all
+	iter.New(nil, nil)
+	for i = 0; iter.Next(); i++ {
+	}
+	iter.Release()
+	assert.Equal(t, len(tests)+1, i)
+}
+
+func TestLayerDB_Batch(t *testing.T) {
+	realDB := NewMapDB()
+	realBucket, _ := realDB.GetBucket("")
+	realBucket.Set([]byte("key0"), []byte("value0"))
+
+	testDB := NewLayerDB(realDB)
+	batch, _ := testDB.GetBatch()
+	batch.New()
+	batch.Set([]byte("key1"), []byte("value1"))
+	batch.Delete([]byte("key0"))
+	assert.Equal(t, 2, batch.Len())
+	assert.NoError(t, batch.Write())
+	batch.Reset()
+	assert.Equal(t, 0, batch.Len())
+
+	bucket, _ := testDB.GetBucket("")
+	assert.False(t, bucket.Has([]byte("key0")))
+	assert.True(t, bucket.Has([]byte("key1")))
+	assert.True(t, realBucket.Has([]byte("key0")))
+	assert.False(t, realBucket.Has([]byte("key1")))
+
+	// write to real DB
+	assert.NoError(t, testDB.Flush(true))
+	assert.False(t, realBucket.Has([]byte("key0")))
+	assert.True(t, realBucket.Has([]byte("key1")))
+
+	// batch writes to real DB after flush
+	batch.Set([]byte("key2"), []byte("value2"))
+	assert.NoError(t, batch.Write())
+	assert.True(t, realBucket.Has([]byte("key2")))
+}
@@ -1,8 +1,10 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 	"log"
+	"sort"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -16,7 +18,7 @@ func init() {
 	dbCreator := func(name string, dir string) (Database, error) {
 		return &mapDatabase{
 			name: name,
-			bks:  map[BucketID]*mapBucket{},
+			real: make(map[string]string),
 		}, nil
 	}
 	registerDBCreator(MapDBBackend, dbCreator, false)
@@ -24,7 +26,7 @@ func init() {
 
 func NewMapDB() Database {
 	dbase := &mapDatabase{
-		bks: map[BucketID]*mapBucket{},
+		real: make(map[string]string),
 	}
 	dbase.name = fmt.Sprintf("%p", dbase)
 	return dbase
@@ -35,73 +37,100 @@ func NewMapDB() Database {
 
 var _ Database = (*mapDatabase)(nil)
 
+// mapDatabase stores entries of all buckets with prefixed keys as other backends do
 type mapDatabase struct {
-	name string
-	bks  map[BucketID]*mapBucket
+	name  string
+	real  map[string]string
+	mutex sync.Mutex
 }
 
 func (t *mapDatabase) GetBucket(id BucketID) (Bucket, error) {
-	if bk, ok := t.bks[id]; ok {
-		return bk, nil
-	}
-	bk := &mapBucket{
-		id:   fmt.Sprintf("%s:%s", t.name, id),
-		real: make(map[string]string),
-	}
-	t.bks[id] = bk
-	return bk, nil
+	return &mapBucket{
+		id:   id,
+		name: fmt.Sprintf("%s:%s", t.name, id),
+		db:   t,
+	}, nil
 }
 
 func (t *mapDatabase) GetIterator() (Iterator, error) {
-	return nil, nil
+	return &mapIterator{
+		db: t,
+	}, nil
 }
 
 func (db *mapDatabase) GetBatch() (Batch, error) {
-	return nil, nil
+	return &mapBatch{
+		db: db,
+	}, nil
 }
 
 func (db *mapDatabase) GetSnapshot() (Snapshot, error) {
-	return nil, nil
+	return &mapSnapshot{
+		db: db,
+	}, nil
 }
 
 func (t *mapDatabase) Close() error {
 	return nil
 }
 
+// rangeOf returns sorted keys and values in [start, limit) from entries. limit == nil means no limit
+func rangeOf(entries map[string]string, start []byte, limit []byte) ([][]byte, [][]byte) {
+	keys := make([]string, 0)
+	for k := range entries {
+		if start != nil && bytes.Compare([]byte(k), start) < 0 {
+			continue
+		}
+		if limit != nil && bytes.Compare([]byte(k), limit) >= 0 {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	bKeys := make([][]byte, len(keys))
+	bValues := make([][]byte, len(keys))
+	for i, k := range keys {
+		bKeys[i] = []byte(k)
+		bValues[i] = []byte(entries[k])
+	}
+	return bKeys, bValues
+}
+
 //----------------------------------------
 // Bucket
 
 var _ Bucket = (*mapBucket)(nil)
 
 type mapBucket struct {
-	id    string
-	real  map[string]string
-	mutex sync.Mutex
+	id   BucketID
+	name string
+	db   *mapDatabase
 }
 
 func (t *mapBucket) Get(k []byte) ([]byte, error) {
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	v, ok := t.real[string(k)]
+	t.db.mutex.Lock()
+	defer t.db.mutex.Unlock()
+	v, ok := t.db.real[string(internalKey(t.id, k))]
 	if ok {
 		bytes := []byte(v)
 		if configLogMapDB {
-			log.Printf("mapBucket[%s].Get(%x) -> [%x]", t.id, k, bytes)
+			log.Printf("mapBucket[%s].Get(%x) -> [%x]", t.name, k, bytes)
 		}
 		return bytes, nil
 	}
 	if configLogMapDB {
-		log.Printf("mapBucket[%s].Get(%x) -> FAIL", t.id, k)
+		log.Printf("mapBucket[%s].Get(%x) -> FAIL", t.name, k)
 	}
 	return nil, nil
 }
 
 func (t *mapBucket) Has(k []byte) bool {
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	_, ok := t.real[string(k)]
+	t.db.mutex.Lock()
+	defer t.db.mutex.Unlock()
+	_, ok := t.db.real[string(internalKey(t.id, k))]
 	if configLogMapDB {
-		log.Printf("mapBucket[%s].Has(%x) -> %v", t.id, k, ok)
+		log.Printf("mapBucket[%s].Has(%x) -> %v", t.name, k, ok)
 	}
 	return ok
 }
@@ -111,20 +140,165 @@ func (t *mapBucket) Set(k, v []byte) error {
 		return errors.Errorf("Illegal Key:%x", k)
 	}
 	if configLogMapDB {
-		log.Printf("mapBucket[%s].Set(%x,%x)", t.id, k, v)
+		log.Printf("mapBucket[%s].Set(%x,%x)", t.name, k, v)
 	}
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	t.real[string(k)] = string(v)
+	t.db.mutex.Lock()
+	defer t.db.mutex.Unlock()
+	t.db.real[string(internalKey(t.id, k))] = string(v)
 	return nil
 }
 
 func (t *mapBucket) Delete(k []byte) error {
 	if configLogMapDB {
-		log.Printf("mapBucket[%s].Delete(%x)", t.id, k)
+		log.Printf("mapBucket[%s].Delete(%x)", t.name, k)
+	}
+	t.db.mutex.Lock()
+	defer t.db.mutex.Unlock()
+	delete(t.db.real, string(internalKey(t.id, k)))
+	return nil
+}
+
+//----------------------------------------
+// DBIterator
+
+var _ Iterator = (*mapIterator)(nil)
+
+// mapIterator iterates entries copied when New() is called
+type mapIterator struct {
+	keys   [][]byte
+	values [][]byte
+	index  int
+	db     *mapDatabase
+}
+
+func (i *mapIterator) New(start []byte, limit []byte) {
+	i.db.mutex.Lock()
+	defer i.db.mutex.Unlock()
+	i.keys, i.values = rangeOf(i.db.real, start, limit)
+	i.index = -1
+}
+
+func (i *mapIterator) Next() bool {
+	i.index++
+	return i.index < len(i.keys)
+}
+
+func (i *mapIterator) Key() []byte {
+	return i.keys[i.index]
+}
+
+func (i *mapIterator) Value() []byte {
+	return i.values[i.index]
+}
+
+func (i *mapIterator) Release() {
+	i.keys = nil
+	i.values = nil
+}
+
+func (i *mapIterator) Error() error {
+	return nil
+}
+
+//----------------------------------------
+// Batch
+
+var _ Batch = (*mapBatch)(nil)
+
+type mapBatchOp struct {
+	key    string
+	value  string
+	delete bool
+}
+
+type mapBatch struct {
+	ops []mapBatchOp
+	db  *mapDatabase
+}
+
+func (b *mapBatch) New() {
+	b.ops = make([]mapBatchOp, 0)
+}
+
+func (b *mapBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *mapBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, mapBatchOp{key: string(key), value: string(value)})
+}
+
+func (b *mapBatch) Delete(key []byte) {
+	b.ops = append(b.ops, mapBatchOp{key: string(key), delete: true})
+}
+
+func (b *mapBatch) Write() error {
+	b.db.mutex.Lock()
+	defer b.db.mutex.Unlock()
+	for _, op := range b.ops {
+		if op.delete {
+			delete(b.db.real, op.key)
+		} else {
+			b.db.real[op.key] = op.value
+		}
+	}
+	return nil
+}
+
+func (b *mapBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+//----------------------------------------
+// Snapshot
+
+var _ Snapshot = (*mapSnapshot)(nil)
+
+type mapSnapshot struct {
+	real map[string]string
+	iter mapIterator
+	db   *mapDatabase
+}
+
+func (s *mapSnapshot) New() error {
+	s.db.mutex.Lock()
+	defer s.db.mutex.Unlock()
+	s.real = make(map[string]string, len(s.db.real))
+	for k, v := range s.db.real {
+		s.real[k] = v
 	}
-	t.mutex.Lock()
-	defer t.mutex.Unlock()
-	delete(t.real, string(k))
 	return nil
 }
+
+func (s *mapSnapshot) Get(key []byte) ([]byte, error) {
+	v, ok := s.real[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	return []byte(v), nil
+}
+
+func (s *mapSnapshot) NewIterator(start []byte, limit []byte) {
+	s.iter.keys, s.iter.values = rangeOf(s.real, start, limit)
+	s.iter.index = -1
+}
+
+func (s *mapSnapshot) IterNext() bool {
+	return s.iter.Next()
+}
+
+func (s *mapSnapshot) IterKey() []byte {
+	return s.iter.Key()
+}
+
+func (s *mapSnapshot) IterValue() []byte {
+	return s.iter.Value()
+}
+
+func (s *mapSnapshot) ReleaseIterator() {
+	s.iter.Release()
+}
+
+func (s *mapSnapshot) Release() {
+	s.real = nil
+}
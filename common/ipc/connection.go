@@ -70,6 +70,11 @@ func (c *connection) Receive(buffer interface{}) (uint, uint32, error) {
 	if err := codec.MP.Unmarshal(c.conn, &m); err != nil {
 		return m.Msg, m.Id, err
 	}
+	// raw data is returned as it is. Messages without data have empty raw data
+	if raw, ok := buffer.(*codec2.Raw); ok {
+		*raw = m.Data
+		return m.Msg, m.Id, nil
+	}
 	if _, err := codec.MP.UnmarshalFromBytes(m.Data, buffer); err != nil {
 		return m.Msg, m.Id, err
 	}
@@ -1,6 +1,7 @@
 package ipc
 
 import (
+	"crypto/tls"
 	"log"
 	"net"
 	"os"
@@ -11,6 +12,10 @@ type Server interface {
 	// Listen specified port to watch.
 	Listen(net, addr string) error
 
+	// ListenTLS listen specified port with TLS. Connections are handled
+	// after peer is authenticated by TLS handshake.
+	ListenTLS(net, addr string, config *tls.Config) error
+
 	// SetHandler set handler for connection. The handler can add message
 	// handler for the connection, and clean-up resource on close.
 	SetHandler(handler ConnectionHandler)
@@ -51,11 +56,27 @@ func (s *server) Listen(network, address string) error {
 	return nil
 }
 
+func (s *server) ListenTLS(network, address string, config *tls.Config) error {
+	if err := s.Listen(network, address); err != nil {
+		return err
+	}
+	s.listener = tls.NewListener(s.listener, config)
+	return nil
+}
+
 func (s *server) SetHandler(handler ConnectionHandler) {
 	s.handler = handler
 }
 
 func (s *server) handleConnection(conn net.Conn) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := handshake(tlsConn); err != nil {
+			log.Printf("Reject connection from %s. err=%+v", conn.RemoteAddr(), err)
+			conn.Close()
+			return
+		}
+	}
+
 	co := connectionFromConn(conn)
 	handler := s.handler
 	if handler != nil {
@@ -90,8 +111,6 @@ func (s *server) Loop() error {
 		}
 		go s.handleConnection(conn)
 	}
-	s.listener.Close()
-	return nil
 }
 
 func (s *server) Close() error {
@@ -0,0 +1,76 @@
+package ipc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+const tlsHandshakeTimeout = 10 * time.Second
+
+// NewTLSConfig makes TLS configuration for mutual authentication.
+// The peer must have a certificate signed by CA in caFile.
+// Server requires client certificate and client verifies server certificate with serverName.
+func NewTLSConfig(certFile, keyFile, caFile string, isServer bool, serverName string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate. %v", err)
+	}
+
+	pem, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate. %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("invalid CA certificate %s", caFile)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if isServer {
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		config.RootCAs = pool
+		config.ServerName = serverName
+	}
+	return config, nil
+}
+
+// handshake authenticates peer of TLS connection before exchanging messages
+func handshake(conn *tls.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout)); err != nil {
+		return err
+	}
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
+
+func DialTLS(network, address string, config *tls.Config) (Connection, error) {
+	if config.ServerName == "" {
+		// verify server certificate with host of address
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			config = config.Clone()
+			config.ServerName = host
+		}
+	}
+
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := handshake(tlsConn); err != nil {
+		tlsConn.Close()
+		return nil, err
+	}
+	return connectionFromConn(tlsConn), nil
+}
@@ -0,0 +1,182 @@
+package ipc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const msgTLSTest uint = 100
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, name string, ca *testCert, isServer bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	parent, parentKey := template, key
+	if ca == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		parent, parentKey = ca.cert, ca.key
+		if isServer {
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+			template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		} else {
+			template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+// write writes certificate and key files and returns their paths
+func (c *testCert) write(t *testing.T, dir string, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	assert.NoError(t, ioutil.WriteFile(certFile, certPEM, 0600))
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	assert.NoError(t, ioutil.WriteFile(keyFile, keyPEM, 0600))
+
+	return certFile, keyFile
+}
+
+type tlsTestHandler struct {
+	onConnect int32
+}
+
+func (h *tlsTestHandler) OnConnect(c Connection) error {
+	atomic.AddInt32(&h.onConnect, 1)
+	return c.Send(msgTLSTest, 0, "READY")
+}
+
+func (h *tlsTestHandler) OnClose(c Connection) error {
+	return nil
+}
+
+func TestTLS_MutualAuthentication(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipc_tls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil, false)
+	caFile, _ := ca.write(t, dir, "ca")
+	serverCert, serverKey := newTestCert(t, "server", ca, true).write(t, dir, "server")
+	clientCert, clientKey := newTestCert(t, "client", ca, false).write(t, dir, "client")
+
+	// certificates signed by another CA
+	otherCA := newTestCert(t, "other", nil, false)
+	otherCAFile, _ := otherCA.write(t, dir, "other")
+	otherClientCert, otherClientKey := newTestCert(t, "other client", otherCA, false).write(t, dir, "other_client")
+
+	serverConfig, err := NewTLSConfig(serverCert, serverKey, caFile, true, "")
+	assert.NoError(t, err)
+
+	s := NewServer()
+	err = s.ListenTLS("tcp", "127.0.0.1:0", serverConfig)
+	assert.NoError(t, err)
+	defer s.Close()
+	handler := new(tlsTestHandler)
+	s.SetHandler(handler)
+	go s.Loop()
+	address := s.Addr().String()
+
+	// authenticated client
+	clientConfig, err := NewTLSConfig(clientCert, clientKey, caFile, false, "")
+	assert.NoError(t, err)
+	conn, err := DialTLS("tcp", address, clientConfig)
+	assert.NoError(t, err)
+	var ready string
+	msg, _, err := conn.Receive(&ready)
+	assert.NoError(t, err)
+	assert.Equal(t, msgTLSTest, msg)
+	assert.Equal(t, "READY", ready)
+	conn.Close()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handler.onConnect))
+
+	// client without certificate
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	_, err = DialTLS("tcp", address, &tls.Config{RootCAs: pool})
+	if err == nil {
+		// TLS 1.3 client finishes handshake before server verifies client certificate
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// client with certificate signed by another CA
+	otherConfig, err := NewTLSConfig(otherClientCert, otherClientKey, caFile, false, "")
+	assert.NoError(t, err)
+	conn, err = DialTLS("tcp", address, otherConfig)
+	if err == nil {
+		_, _, err = conn.Receive(&ready)
+		assert.Error(t, err)
+		conn.Close()
+	}
+
+	// client does not trust server certificate
+	untrustedConfig, err := NewTLSConfig(clientCert, clientKey, otherCAFile, false, "")
+	assert.NoError(t, err)
+	_, err = DialTLS("tcp", address, untrustedConfig)
+	assert.Error(t, err)
+
+	// READY is sent to authenticated client only
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&handler.onConnect))
+}
+
+func TestTLS_NewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipc_tls")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil, false)
+	caFile, caKey := ca.write(t, dir, "ca")
+	cert, key := newTestCert(t, "server", ca, true).write(t, dir, "server")
+
+	_, err = NewTLSConfig(cert, key, caFile, true, "")
+	assert.NoError(t, err)
+
+	// invalid key
+	_, err = NewTLSConfig(cert, caKey, caFile, true, "")
+	assert.Error(t, err)
+
+	// invalid CA certificate
+	_, err = NewTLSConfig(cert, key, key, true, "")
+	assert.Error(t, err)
+	_, err = NewTLSConfig(cert, key, filepath.Join(dir, "none"), true, "")
+	assert.Error(t, err)
+}
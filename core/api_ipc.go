@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
 	"github.com/icon-project/rewardcalculator/common/ipc"
+	codec2 "github.com/ugorji/go/codec"
 )
 
 type RCIPC struct {
@@ -34,16 +36,85 @@ RETRY:
 	}
 	rc.conn = conn
 
-	// flush READY message
+	// flush READY message and CALCULATE_DONE in outbox
+	doneList, err := ReceiveReady(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	for _, done := range doneList {
+		log.Printf("Get CALCULATE_DONE in outbox: %s\n", done.String())
+	}
+
+	return rc, nil
+}
+
+// ReceiveReady receives READY message and CALCULATE_DONE in outbox which Reward Calculator sends on connection.
+// It sends VERSION and receives messages until VERSION response, because outbox is sent before handling requests.
+// Reward Calculator in server mode sends CALCULATE_DONE in outbox to the writer connection only. See SendAndReceive
+func ReceiveReady(conn ipc.Connection) ([]*CalculateDone, error) {
 	for true {
 		var m ResponseVersion
-		msg, _, _ := conn.Receive(m)
+		msg, _, err := conn.Receive(&m)
+		if err != nil {
+			return nil, err
+		}
 		if msg == MsgReady {
 			break
 		}
 	}
 
-	return rc, nil
+	if err := conn.Send(MsgVersion, 0, nil); err != nil {
+		return nil, err
+	}
+	doneList := make([]*CalculateDone, 0)
+	for true {
+		var data codec2.Raw
+		msg, _, err := conn.Receive(&data)
+		if err != nil {
+			return nil, err
+		}
+		if msg == MsgVersion {
+			break
+		}
+		if msg == MsgCalculateDone {
+			done := new(CalculateDone)
+			if _, err = codec.MP.UnmarshalFromBytes(data, done); err != nil {
+				return nil, err
+			}
+			doneList = append(doneList, done)
+		}
+	}
+
+	return doneList, nil
+}
+
+// SendAndReceive sends message and receives its response.
+// Reward Calculator sends CALCULATE_DONE in outbox before the response of the first message which modifies
+// I-Score DB, because the connection becomes the writer connection with it. They are skipped
+func SendAndReceive(conn ipc.Connection, msg uint, id uint32, data interface{}, buffer interface{}) error {
+	if err := conn.Send(msg, id, data); err != nil {
+		return err
+	}
+	for true {
+		var resp codec2.Raw
+		m, _, err := conn.Receive(&resp)
+		if err != nil {
+			return err
+		}
+		if m == MsgCalculateDone {
+			var done CalculateDone
+			if _, err = codec.MP.UnmarshalFromBytes(resp, &done); err == nil {
+				log.Printf("Get CALCULATE_DONE in outbox: %s\n", done.String())
+			}
+			continue
+		}
+		if buffer != nil {
+			_, err = codec.MP.UnmarshalFromBytes(resp, buffer)
+		}
+		return err
+	}
+	return nil
 }
 
 func FiniRCIPC(ipc *RCIPC) {
@@ -90,7 +161,7 @@ func (rc *RCIPC) SendClaim(address string, blockHeight uint64, blockHash string,
 
 	log.Printf("Send CLAIM message: %s\n", req.String())
 	rc.id++
-	err := rc.conn.SendAndReceive(MsgClaim, rc.id, &req, resp)
+	err := SendAndReceive(rc.conn, MsgClaim, rc.id, &req, resp)
 	if err != nil {
 		return resp, err
 	}
@@ -128,6 +199,19 @@ func (rc *RCIPC) SendQuery(address string) (*ResponseQuery, error) {
 	return resp, err
 }
 
+func (rc *RCIPC) SendBatchQuery(addresses []string) (*ResponseBatchQuery, error) {
+	addrs := make([]common.Address, len(addresses))
+	resp := new(ResponseBatchQuery)
+
+	for i, address := range addresses {
+		addrs[i].SetString(address)
+	}
+
+	err := rc.conn.SendAndReceive(MsgBatchQuery, rc.id, &addrs, resp)
+
+	return resp, err
+}
+
 func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateResponse, error) {
 	var req CalculateRequest
 	resp := new(CalculateResponse)
@@ -136,7 +220,7 @@ func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateR
 	req.BlockHeight = blockHeight
 
 	// Send CALCULATE and get response
-	err := rc.conn.SendAndReceive(MsgCalculate, rc.id, &req, resp)
+	err := SendAndReceive(rc.conn, MsgCalculate, rc.id, &req, resp)
 	if err != nil {
 		log.Printf("Failed to get CALCULATE response. %v", err)
 		return nil, err
@@ -155,6 +239,9 @@ func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateR
 	}
 	if msg == MsgCalculateDone {
 		log.Printf("Get CALCULATE_DONE: %s\n", respDone.String())
+		if err = rc.SendAckCalculateDone(respDone.BlockHeight); err != nil {
+			return resp, err
+		}
 	} else {
 		log.Printf("Get invalid response : (msg:%d, id:%d)\n", msg, id)
 	}
@@ -162,6 +249,19 @@ func (rc *RCIPC) SendCalculate(iissData string, blockHeight uint64) (*CalculateR
 	return resp, nil
 }
 
+func (rc *RCIPC) SendCalculateDryRun(iissData string, blockHeight uint64) (*CalculateDryRunResponse, error) {
+	var req CalculateRequest
+	resp := new(CalculateDryRunResponse)
+
+	req.Path = iissData
+	req.BlockHeight = blockHeight
+	req.DryRun = true
+
+	err := SendAndReceive(rc.conn, MsgCalculate, rc.id, &req, resp)
+
+	return resp, err
+}
+
 func (rc *RCIPC) SendCommitBlock(success bool, blockHeight uint64, blockHash string) (*CommitBlock, error) {
 	var req CommitBlock
 	resp := new(CommitBlock)
@@ -182,7 +282,7 @@ func (rc *RCIPC) SendCommitBlock(success bool, blockHeight uint64, blockHash str
 
 	log.Printf("Send COMMIT_BLOCK message: %s\n", req.String())
 	rc.id++
-	err := rc.conn.SendAndReceive(MsgCommitBlock, rc.id, &req, &resp)
+	err := SendAndReceive(rc.conn, MsgCommitBlock, rc.id, &req, &resp)
 	log.Printf("Get COMMIT_BLOCK response: %s\n", resp.String())
 
 	return resp, err
@@ -221,7 +321,7 @@ func (rc *RCIPC) SendCommitClaim(success bool, address string, blockHeight uint6
 
 	log.Printf("Send COMMIT_CLAIM message: %s\n", req.String())
 	rc.id++
-	err := rc.conn.SendAndReceive(MsgCommitClaim, rc.id, &req, nil)
+	err := SendAndReceive(rc.conn, MsgCommitClaim, rc.id, &req, nil)
 	log.Printf("Get COMMIT_CLAIM ack. %v\n", err)
 
 	return err
@@ -268,7 +368,7 @@ func (rc *RCIPC) SendRollback(blockHeight uint64, blockHash string) (*RollBackRe
 	req.BlockHash = make([]byte, BlockHashSize)
 	copy(req.BlockHash, hash)
 
-	err = rc.conn.SendAndReceive(MsgRollBack, rc.id, &req, &resp)
+	err = SendAndReceive(rc.conn, MsgRollBack, rc.id, &req, &resp)
 	if err != nil {
 		log.Printf("Failed to ROLLBACK response. %v\n", err)
 		return nil, err
@@ -280,7 +380,7 @@ func (rc *RCIPC) SendRollback(blockHeight uint64, blockHash string) (*RollBackRe
 func (rc *RCIPC) SendInit(blockHeight uint64) (*ResponseInit, error) {
 	resp := new(ResponseInit)
 
-	err := rc.conn.SendAndReceive(MsgINIT, rc.id, &blockHeight, &resp)
+	err := SendAndReceive(rc.conn, MsgINIT, rc.id, &blockHeight, &resp)
 	if err != nil {
 		log.Printf("Failed to INIT response. %v\n", err)
 		return nil, err
@@ -288,3 +388,88 @@ func (rc *RCIPC) SendInit(blockHeight uint64) (*ResponseInit, error) {
 	fmt.Printf("Get INIT response: %s\n", resp.String())
 	return resp, nil
 }
+
+func (rc *RCIPC) SendQueryIScoreProof(address string, blockHeight uint64) (*QueryIScoreProofResponse, error) {
+	var req QueryIScoreProofRequest
+	resp := new(QueryIScoreProofResponse)
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	// Send QUERY_ISCORE_PROOF and get response
+	err := rc.conn.SendAndReceive(MsgQueryIScoreProof, rc.id, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_ISCORE_PROOF response. %v", err)
+		return nil, err
+	}
+
+	log.Printf("Get QUERY_ISCORE_PROOF response: %s\n", resp.String())
+	return resp, nil
+}
+
+func (rc *RCIPC) SendQueryAt(address string, blockHeight uint64) (*QueryAtResponse, error) {
+	var req QueryAtRequest
+	resp := new(QueryAtResponse)
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	// Send QUERY_AT and get response
+	err := rc.conn.SendAndReceive(MsgQueryAt, rc.id, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_AT response. %v", err)
+		return nil, err
+	}
+
+	log.Printf("Get QUERY_AT response: %s\n", resp.String())
+	return resp, nil
+}
+
+func (rc *RCIPC) SendQueryRewardBreakdown(address string, blockHeight uint64) (*QueryRewardBreakdownResponse, error) {
+	var req QueryRewardBreakdownRequest
+	resp := new(QueryRewardBreakdownResponse)
+
+	req.Address.SetString(address)
+	req.BlockHeight = blockHeight
+
+	// Send QUERY_REWARD_BREAKDOWN and get response
+	err := rc.conn.SendAndReceive(MsgQueryRewardBreakdown, rc.id, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_REWARD_BREAKDOWN response. %v", err)
+		return nil, err
+	}
+
+	log.Printf("Get QUERY_REWARD_BREAKDOWN response: %s\n", resp.String())
+	return resp, nil
+}
+
+func (rc *RCIPC) SendQueryClaimHistory(address string, from uint64, to uint64) (*QueryClaimHistoryResponse, error) {
+	var req QueryClaimHistoryRequest
+	resp := new(QueryClaimHistoryResponse)
+
+	req.Address.SetString(address)
+	req.From = from
+	req.To = to
+
+	// Send QUERY_CLAIM_HISTORY and get response
+	err := rc.conn.SendAndReceive(MsgQueryClaimHistory, rc.id, &req, resp)
+	if err != nil {
+		log.Printf("Failed to get QUERY_CLAIM_HISTORY response. %v", err)
+		return nil, err
+	}
+
+	log.Printf("Get QUERY_CLAIM_HISTORY response: %s\n", resp.String())
+	return resp, nil
+}
+
+func (rc *RCIPC) SendAckCalculateDone(blockHeight uint64) error {
+	// Send ACK_CALCULATE_DONE and get response
+	err := SendAndReceive(rc.conn, MsgAckCalculateDone, rc.id, &blockHeight, nil)
+	if err != nil {
+		log.Printf("Failed to get ACK_CALCULATE_DONE response. %v", err)
+		return err
+	}
+
+	log.Printf("Get ACK_CALCULATE_DONE response\n")
+	return nil
+}
@@ -18,10 +18,13 @@ const (
 	AccountDBNameFormat = "calculate_%d_%d_%d"
 	BackupDBNamePrefix  = "backup_"
 	BackupDBNameFormat  = BackupDBNamePrefix + "%d_%d" // backup_CalcBH_accountDBIndex
+	// temporary directory of throwaway account DBs of simulated calculation
+	SimulateDBNamePrefix = "simulate_"
 
 	Revision8   uint64 = 8
+	Revision9   uint64 = 9
 	RevisionMin        = Revision8
-	RevisionMax        = Revision8
+	RevisionMax        = Revision9
 )
 
 type IScoreDB struct {
@@ -34,10 +37,35 @@ type IScoreDB struct {
 	preCommit   db.Database
 	claim       db.Database
 	claimBackup db.Database
+	// claims committed to claim DB
+	claimHistory db.Database
+	// the number of blocks to keep claims in claim backup DB
+	claimBackupPeriod uint64
 
 	accountLock sync.RWMutex
 	Account0    []db.Database
 	Account1    []db.Database
+
+	// readViews which pinned Account0 and Account1 as query DB
+	views [2]sync.WaitGroup
+
+	// held while calculation changes I-Score DB. Rollback waits for canceled calculation with it
+	calcLock sync.Mutex
+
+	// the number of backup account DB generations to retain
+	accountBackups int
+	backupLock     sync.Mutex
+
+	// rewards of accounts in the latest breakdowns calculations. nil if disabled
+	rewardBreakdown db.Database
+	breakdowns      int
+
+	// delegation rewards of P-Reps in the latest pRepReports calculations. nil if disabled
+	pRepReport  db.Database
+	pRepReports int
+
+	// Merkle trees of accounts for QUERY_ISCORE_PROOF. nil if disabled
+	merkleTrees *merkleTreeCache
 }
 
 func (idb *IScoreDB) getQueryDBList() []db.Database {
@@ -78,6 +106,20 @@ func (idb *IScoreDB) toggleAccountDB(blockHeight uint64) {
 	idb.writeToDB()
 }
 
+// setAccountDBToggle sets query DB and toggle block height. It can be applied again unlike toggleAccountDB
+func (idb *IScoreDB) setAccountDBToggle(queryDBIsZero bool, blockHeight uint64) {
+	idb.accountLock.Lock()
+	idb.info.QueryDBIsZero = queryDBIsZero
+	idb.info.ToggleBH = blockHeight
+	idb.accountLock.Unlock()
+
+	// account DBs are rolled back
+	idb.merkleTrees.clear()
+
+	// write to DB
+	idb.writeToDB()
+}
+
 func (idb *IScoreDB) getAccountDBIndex(address common.Address) int {
 	prefix := int(address.ID()[0])
 	return prefix % idb.info.DBCount
@@ -133,7 +175,37 @@ func (idb *IScoreDB) getCalculateResultDB() db.Database {
 	return idb.calcResult
 }
 
-func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error {
+// SetAccountDBBackups sets the number of backup account DB generations to retain. The minimum is 1 for rollback
+func (idb *IScoreDB) SetAccountDBBackups(count int) {
+	if count < DefaultAccountDBBackups {
+		count = DefaultAccountDBBackups
+	}
+	idb.accountBackups = count
+}
+
+func (idb *IScoreDB) getAccountDBBackups() int {
+	if idb.accountBackups < DefaultAccountDBBackups {
+		return DefaultAccountDBBackups
+	}
+	return idb.accountBackups
+}
+
+// SetClaimBackupPeriod sets the number of blocks to keep claims in claim backup DB for rollback
+func (idb *IScoreDB) SetClaimBackupPeriod(period uint64) {
+	if period == 0 {
+		period = DefaultClaimBackupPeriod
+	}
+	idb.claimBackupPeriod = period
+}
+
+func (idb *IScoreDB) getClaimBackupPeriod() uint64 {
+	if idb.claimBackupPeriod == 0 {
+		return DefaultClaimBackupPeriod
+	}
+	return idb.claimBackupPeriod
+}
+
+func (idb *IScoreDB) resetAccountDB(blockHeight uint64) error {
 	idb.accountLock.Lock()
 	defer idb.accountLock.Unlock()
 
@@ -144,23 +216,18 @@ func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error
 		oldQueryDBPostFix = 1
 	}
 
-	// delete old backup account DB
-	oldBackup := filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+strconv.FormatUint(oldCalcBH, 10)+"_*")
-	oldBackups, err := filepath.Glob(oldBackup)
-	if err != nil {
-		log.Printf("Failed to get old backup account DB %s. %v", oldBackup, err)
-		return err
-	}
-	log.Printf("delete old backup %d account DBs. %s", len(oldBackups), oldBackup)
-	for _, f := range oldBackups {
-		err = os.RemoveAll(f)
-		if err != nil {
-			log.Printf("Failed to delete old backup account DB %s. %v", f, err)
-			return err
-		}
-	}
+	// wait for requests reading old query DB
+	idb.waitReadViews(oldQueryDBPostFix)
 
+	// set new calculate DB before opening it to close opened DB after crash
 	newCalcDBs := make([]db.Database, len(oldQueryDBs))
+	copy(newCalcDBs, oldQueryDBs)
+	if idb.info.QueryDBIsZero {
+		idb.Account1 = newCalcDBs
+	} else {
+		idb.Account0 = newCalcDBs
+	}
+
 	backupCount := 0
 	for i, oldQueryDB := range oldQueryDBs {
 		oldQueryDB.Close()
@@ -181,15 +248,15 @@ func (idb *IScoreDB) resetAccountDB(blockHeight uint64, oldCalcBH uint64) error
 
 		// open new calculate DB
 		newCalcDBs[i] = db.Open(idb.info.DBRoot, idb.info.DBType, dbName)
+		crashPoint()
 	}
 	backup := filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+strconv.FormatUint(blockHeight, 10)+"_*")
 	log.Printf("backup %d account DBs. %s", backupCount, backup)
 
-	// set new calculate DB
-	if idb.info.QueryDBIsZero {
-		idb.Account1 = newCalcDBs
-	} else {
-		idb.Account0 = newCalcDBs
+	// old query DB has I-Scores of the previous calculation.
+	// It is unknown after rollback because previous calculation block height was not rolled back
+	if backupCount > 0 && idb.info.PrevCalcDone != idb.info.CalcDone {
+		writeAccountBackup(idb.management, blockHeight, idb.info.PrevCalcDone)
 	}
 
 	return nil
@@ -246,9 +313,10 @@ func (idb *IScoreDB) rollbackCurrentBlockInfo(blockHeight uint64, blockHash []by
 	idb.writeToDB()
 }
 
-func (idb *IScoreDB) rollbackAccountDBBlockInfo() {
-	idb.info.CalcDone = idb.info.PrevCalcDone
-	idb.info.Calculating = idb.info.PrevCalcDone
+func (idb *IScoreDB) rollbackAccountDBBlockInfo(calcDone uint64, prevCalcDone uint64) {
+	idb.info.CalcDone = calcDone
+	idb.info.PrevCalcDone = prevCalcDone
+	idb.info.Calculating = calcDone
 
 	idb.writeToDB()
 }
@@ -260,20 +328,135 @@ func (idb *IScoreDB) writeToDB() {
 }
 
 func (idb *IScoreDB) rollbackAccountDB(blockHeight uint64) error {
-	log.Printf("Start Rollback account DB to %d", blockHeight)
+	ar, err := idb.planAccountDBRollback(blockHeight)
+	if err != nil {
+		return err
+	}
+	return idb.applyAccountDBRollback(ar)
+}
+
+// planAccountDBRollback returns steps to roll back account DB to blockHeight.
+// The plan is written to journal before account DB is changed
+func (idb *IScoreDB) planAccountDBRollback(blockHeight uint64) (*AccountRollback, error) {
 	var calcDBPostFix = 0
 	if idb.info.QueryDBIsZero {
 		calcDBPostFix = 1
 	}
 
-	backups, err := filepath.Glob(filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+"*"))
+	calcBHs, chain, err := idb.getRollbackChain()
 	if err != nil {
 		log.Printf("Failed to get backup account DB")
-		return err
+		return nil, err
+	}
+
+	// the number of calculations to roll back
+	terms := 1
+	for terms < len(chain) && calcBHs[terms] >= blockHeight {
+		terms++
+	}
+	if calcBHs[terms] >= blockHeight {
+		return nil, &RollbackLowBlockHeightError{calcBHs[terms], blockHeight}
+	}
+
+	ar := new(AccountRollback)
+	if terms == 1 {
+		// restore the latest backup generation. Older generations are retained for query
+		backupBHs, err := idb.getAccountBackupBHs()
+		if err != nil {
+			log.Printf("Failed to get backup account DB")
+			return nil, err
+		}
+		if len(backupBHs) > 0 {
+			ar.Restores = append(ar.Restores, AccountRestore{BlockHeight: backupBHs[0], PostFix: calcDBPostFix})
+		}
+	} else {
+		// query DB of calcBHs[terms] is restored to calculate DB and toggled.
+		// calculate DB of calcBHs[terms] is made backup by calculation calcBHs[terms-2]
+		for i, postFix := range []int{calcDBPostFix, 1 - calcDBPostFix} {
+			ab := chain[terms-1-i]
+			ar.Restores = append(ar.Restores, AccountRestore{BlockHeight: ab.BlockHeight, PostFix: postFix})
+		}
+
+		// backup generations of rolled back calculations have I-Scores which are rolled back too
+		for _, ab := range chain[:terms-2] {
+			ar.Deletes = append(ar.Deletes, ab.BlockHeight)
+		}
+	}
+
+	// set toggle block height with the one of calculation calcBHs[terms]
+	calcDone := calcBHs[terms]
+	ar.ToggleBH = calcDone + 1
+	if calcDone == 0 {
+		ar.ToggleBH = 0
+	}
+	ar.QueryDBIsZero = idb.info.QueryDBIsZero
+	if idb.info.ToggleBH != ar.ToggleBH {
+		ar.QueryDBIsZero = !ar.QueryDBIsZero
+	}
+
+	// delete calculation results
+	ar.Results = append(ar.Results, calcBHs[:terms]...)
+
+	// Rollback block height. previous calculation is unknown without backup generation of calcBHs[terms-1]
+	ar.CalcDone = calcDone
+	ar.PrevCalcDone = calcDone
+	if len(calcBHs) > terms+1 {
+		ar.PrevCalcDone = calcBHs[terms+1]
 	}
 
-	// rollback account DB
+	return ar, nil
+}
+
+// applyAccountDBRollback rolls back account DB with plan. It can be applied again after crash
+func (idb *IScoreDB) applyAccountDBRollback(ar *AccountRollback) error {
+	log.Printf("Start Rollback account DB to %d", ar.CalcDone)
+
+	for _, r := range ar.Restores {
+		// backup DBs restored already are not found
+		backups, err := filepath.Glob(idb.accountBackupPattern(r.BlockHeight))
+		if err != nil {
+			log.Printf("Failed to get backup account DB")
+			return err
+		}
+		if err = idb.restoreAccountDB(backups, r.PostFix); err != nil {
+			return err
+		}
+		deleteAccountBackup(idb.management, r.BlockHeight)
+	}
+
+	idb.accountLock.Lock()
+	for _, bh := range ar.Deletes {
+		if err := idb.deleteAccountBackupDBs(bh); err != nil {
+			idb.accountLock.Unlock()
+			return err
+		}
+	}
+	idb.accountLock.Unlock()
+
+	idb.setAccountDBToggle(ar.QueryDBIsZero, ar.ToggleBH)
+	crashPoint()
+
+	for _, calcBH := range ar.Results {
+		DeleteCalculationResult(idb.getCalculateResultDB(), calcBH)
+	}
+
+	idb.rollbackAccountDBBlockInfo(ar.CalcDone, ar.PrevCalcDone)
+
+	log.Printf("End rollblack account DB to %d. %d calculations", ar.CalcDone, len(ar.Results))
+	return nil
+}
+
+// restoreAccountDB renames backup DBs to calculate DB and reopens account DB
+func (idb *IScoreDB) restoreAccountDB(backups []string, calcDBPostFix int) error {
+	idb.accountLock.Lock()
+	defer idb.accountLock.Unlock()
+
+	// wait for requests reading query DB
+	idb.waitReadViews(0, 1)
+
 	idb.CloseAccountDB()
+	defer idb.OpenAccountDB()
+
 	rollbackCount := 0
 	for _, f := range backups {
 		var backupBH, index int
@@ -282,7 +465,7 @@ func (idb *IScoreDB) rollbackAccountDB(blockHeight uint64) error {
 		calcDBName := fmt.Sprintf(AccountDBNameFormat, index, idb.info.DBCount, calcDBPostFix)
 
 		// remove calculate DB
-		err = os.RemoveAll(filepath.Join(idb.info.DBRoot, calcDBName))
+		err := os.RemoveAll(filepath.Join(idb.info.DBRoot, calcDBName))
 		if err != nil && os.IsNotExist(err) {
 			log.Printf("Failed to remove old calculate DB")
 			return err
@@ -299,20 +482,10 @@ func (idb *IScoreDB) rollbackAccountDB(blockHeight uint64) error {
 			log.Printf("rename backup DB to query DB. %s -> %s", f, calcDBName)
 			rollbackCount++
 		}
+		crashPoint()
 	}
 	log.Printf("Rollback %d account DB", rollbackCount)
-	idb.OpenAccountDB()
-
-	// set toggle block height with rollback block height
-	idb.toggleAccountDB(blockHeight)
-
-	// delete calculation result
-	DeleteCalculationResult(idb.getCalculateResultDB(), idb.getCalcDoneBH())
-
-	// Rollback block height and block hash
-	idb.rollbackAccountDBBlockInfo()
 
-	log.Printf("End rollblack account DB to %d", blockHeight)
 	return nil
 }
 
@@ -324,10 +497,22 @@ type Context struct {
 	PRepCandidates map[common.Address]*PRepCandidate
 	GV             []*GovernanceVariable
 
+	// DB backend of IISS data written by ICON Service
+	IISSDataBackend string
+
 	stats             *Statistics
 	CancelCalculation *CancelCalculation
 
 	calcDebug *CalcDebug
+
+	// rewards of accounts in calculation for reward breakdown DB
+	rewards *rewardRecorder
+
+	// delegation rewards of P-Reps in calculation for P-Rep report DB
+	pRepReport *pRepReporter
+
+	// journal of calculation in progress. nil in simulation
+	journal *Journal
 }
 
 func (ctx *Context) getGVByBlockHeight(blockHeight uint64) *GovernanceVariable {
@@ -356,18 +541,21 @@ func (ctx *Context) UpdateGovernanceVariable(gvList []*IISSGovernanceVariable) {
 			// write to management DB
 			value, _ := gv.Bytes()
 			bucket.Set(gv.ID(), value)
+			crashPoint()
 		}
 	}
 
-	// delete old value
+	// delete old value which can't be restored by rollback
+	retainedBH := ctx.DB.getRetainedCalcBH()
 	gvLen := len(ctx.GV)
 	deleteOld := false
 	deleteIndex := -1
 	for i := gvLen - 1; i >= 0; i-- {
-		if ctx.GV[i].BlockHeight <= ctx.DB.getPrevCalcDoneBH() {
+		if ctx.GV[i].BlockHeight <= retainedBH {
 			if deleteOld {
 				// delete from management DB
 				bucket.Delete(ctx.GV[i].ID())
+				crashPoint()
 			} else {
 				deleteOld = true
 				deleteIndex = i
@@ -392,17 +580,20 @@ func (ctx *Context) UpdatePRep(prepList []*PRep) {
 		// write to management DB
 		value, _ := prep.Bytes()
 		bucket.Set(prep.ID(), value)
+		crashPoint()
 	}
 
-	// delete old value
+	// delete old value which can't be restored by rollback
+	retainedBH := ctx.DB.getRetainedCalcBH()
 	prepLen := len(ctx.PRep)
 	deleteOld := false
 	deleteIndex := -1
 	for i := prepLen - 1; i >= 0; i-- {
-		if ctx.PRep[i].BlockHeight <= ctx.DB.getPrevCalcDoneBH() {
+		if ctx.PRep[i].BlockHeight <= retainedBH {
 			if deleteOld {
 				// delete from management DB
 				bucket.Delete(ctx.PRep[i].ID())
+				crashPoint()
 			} else {
 				deleteOld = true
 				deleteIndex = i
@@ -440,6 +631,12 @@ func (ctx *Context) UpdatePRepCandidate(iissDB db.Database) {
 				p.Start = tx.BlockHeight
 				p.End = 0
 
+				// write change log for rollback
+				if err = writePRepCandidateLog(ctx.DB.management, &tx, nil); err != nil {
+					log.Printf("Failed to write P-Rep candidate log. %+v", err)
+				}
+				crashPoint()
+
 				// write to memory
 				ctx.PRepCandidates[tx.Address] = p
 
@@ -447,6 +644,7 @@ func (ctx *Context) UpdatePRepCandidate(iissDB db.Database) {
 				bucket, _ := ctx.DB.management.GetBucket(db.PrefixPRepCandidate)
 				data, _ := p.Bytes()
 				bucket.Set(p.ID(), data)
+				crashPoint()
 				log.Printf("P-Rep : register '%s'", tx.Address.String())
 			} else {
 				log.Printf("P-Rep : '%s' was registered already\n", tx.Address.String())
@@ -460,6 +658,12 @@ func (ctx *Context) UpdatePRepCandidate(iissDB db.Database) {
 					continue
 				}
 
+				// write change log for rollback
+				if err = writePRepCandidateLog(ctx.DB.management, &tx, pRep); err != nil {
+					log.Printf("Failed to write P-Rep candidate log. %+v", err)
+				}
+				crashPoint()
+
 				// write to memory
 				pRep.End = tx.BlockHeight
 
@@ -467,6 +671,7 @@ func (ctx *Context) UpdatePRepCandidate(iissDB db.Database) {
 				bucket, _ := ctx.DB.management.GetBucket(db.PrefixPRepCandidate)
 				data, _ := pRep.Bytes()
 				bucket.Set(pRep.ID(), data)
+				crashPoint()
 				log.Printf("P-Rep : unregister '%s'", tx.Address.String())
 			} else {
 				log.Printf("P-Rep :  %s was not registered\n", tx.Address.String())
@@ -479,6 +684,11 @@ func (ctx *Context) UpdatePRepCandidate(iissDB db.Database) {
 	if err != nil {
 		log.Printf("There is error while IISS TX iteration for P-Rep update. %+v", err)
 	}
+
+	// delete old change log which can't be restored by rollback
+	if err = prunePRepCandidateLogs(ctx.DB.management, ctx.DB.getRetainedCalcBH()); err != nil {
+		log.Printf("Failed to delete old P-Rep candidate log. %+v", err)
+	}
 }
 
 func (ctx *Context) RollbackManagementDB(blockHeight uint64) {
@@ -505,6 +715,11 @@ func (ctx *Context) RollbackManagementDB(blockHeight uint64) {
 			ctx.PRep = ctx.PRep[:i]
 		}
 	}
+
+	// Rollback P-Rep candidate
+	if err := ctx.rollbackPRepCandidate(blockHeight); err != nil {
+		log.Printf("Failed to rollback P-Rep candidate. %+v", err)
+	}
 }
 
 func (ctx *Context) Print() {
@@ -532,14 +747,20 @@ func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugC
 	ctx.DB = isDB
 	var err error
 
+	if err = CheckDBBackend(dbType); err != nil {
+		log.Printf("Failed to open I-Score DB. %v", err)
+		return nil, err
+	}
+
 	// Open management DB
-	mngDB := db.Open(dbPath, dbType, dbName)
+	mngDB := db.Open(dbPath, ManagementDBBackend, dbName)
 	isDB.management = mngDB
 
 	// read DB Info.
 	isDB.info, err = NewDBInfo(mngDB, dbPath, dbType, dbName, dbCount)
 	if err != nil {
-		log.Panicf("Failed to load DB Information\n")
+		log.Printf("Failed to load DB Information. %v\n", err)
+		mngDB.Close()
 		return nil, err
 	}
 
@@ -566,6 +787,8 @@ func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugC
 
 	InitCalcDebugConfig(ctx, debugConfigPath)
 
+	ctx.IISSDataBackend = string(db.GoLevelDBBackend)
+
 	// Open calculation result DB
 	isDB.calcResult = db.Open(isDB.info.DBRoot, isDB.info.DBType, "calculation_result")
 
@@ -578,6 +801,9 @@ func NewContext(dbPath string, dbType string, dbName string, dbCount int, debugC
 	// Open claim backup DB
 	isDB.claimBackup = db.Open(isDB.info.DBRoot, isDB.info.DBType, "claim_backup")
 
+	// Open claim history DB
+	isDB.claimHistory = db.Open(isDB.info.DBRoot, isDB.info.DBType, ClaimHistoryDBName)
+
 	// Open account DB
 	isDB.OpenAccountDB()
 
@@ -607,4 +833,17 @@ func CloseIScoreDB(isDB *IScoreDB) {
 
 	// close claim backup DB
 	isDB.claimBackup.Close()
+
+	// close claim history DB
+	isDB.claimHistory.Close()
+
+	// close reward breakdown DB
+	if isDB.rewardBreakdown != nil {
+		isDB.rewardBreakdown.Close()
+	}
+
+	// close P-Rep report DB
+	if isDB.pRepReport != nil {
+		isDB.pRepReport.Close()
+	}
 }
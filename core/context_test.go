@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/db"
@@ -14,15 +15,29 @@ import (
 )
 
 var testDir string
+var testDBBackend = string(db.GoLevelDBBackend)
+
+// run all tests with each DB backend
+func TestMain(m *testing.M) {
+	result := 0
+	for _, backend := range DBBackends {
+		testDBBackend = backend
+		fmt.Printf("Run tests with DB backend %s\n", backend)
+		if code := m.Run(); code != 0 {
+			result = code
+		}
+	}
+	os.Exit(result)
+}
 
 func initTest(dbCount int) *Context{
 	var err error
-	testDir, err = ioutil.TempDir("", "goleveldb")
+	testDir, err = ioutil.TempDir("", testDBBackend)
 	if err != nil {
 		panic(err)
 	}
 
-	ctx, _ := NewContext(testDir, string(db.GoLevelDBBackend), "test", dbCount,
+	ctx, _ := NewContext(testDir, testDBBackend, "test", dbCount,
 		"debugConfigPath")
 
 	return ctx
@@ -56,6 +71,50 @@ func TestContext_NewContext(t *testing.T) {
 	assert.NotNil(t, ctx.GV)
 	assert.NotNil(t, ctx.PRep)
 	assert.NotNil(t, ctx.PRepCandidates)
+	assert.Equal(t, testDBBackend, ctx.DB.info.Backend)
+	assert.Equal(t, string(db.GoLevelDBBackend), ctx.IISSDataBackend)
+}
+
+func TestContext_NewContextDBBackend(t *testing.T) {
+	const dbCount int = 2
+	ctx := initTest(dbCount)
+	defer os.RemoveAll(testDir)
+
+	// write data and reopen with same backend
+	ia := new(IScoreAccount)
+	ia.Address = *common.NewAddressFromString("hx11")
+	ia.BlockHeight = 100
+	ia.IScore.SetUint64(1000)
+	bucket, _ := ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+	CloseIScoreDB(ctx.DB)
+
+	ctx, err := NewContext(testDir, testDBBackend, "test", dbCount, "")
+	assert.NoError(t, err)
+	bucket, _ = ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+	bs, _ := bucket.Get(ia.ID())
+	assert.NotNil(t, bs)
+	CloseIScoreDB(ctx.DB)
+
+	// refuse mismatched DB backend
+	for _, backend := range DBBackends {
+		if backend == testDBBackend {
+			continue
+		}
+		ctx, err = NewContext(testDir, backend, "test", dbCount, "")
+		assert.Nil(t, ctx)
+		assert.Error(t, err)
+		_, ok := err.(*DBBackendMismatchError)
+		assert.True(t, ok)
+	}
+
+	// invalid DB backend
+	ctx, err = NewContext(testDir, string(db.MapDBBackend), "test", dbCount, "")
+	assert.Nil(t, ctx)
+	assert.Error(t, err)
+	ctx, err = NewContext(testDir, "invalid", "test", dbCount, "")
+	assert.Nil(t, ctx)
+	assert.Error(t, err)
 }
 
 func TestContext_GovernanceVariable(t *testing.T) {
@@ -455,7 +514,7 @@ func TestContext_ResetAccountDB(t *testing.T) {
 
 	blockHeight := uint64(1000)
 	oldBlockHeight := ctx.DB.getCalcDoneBH()
-	err = ctx.DB.resetAccountDB(blockHeight, oldBlockHeight)
+	err = ctx.DB.resetAccountDB(blockHeight)
 	assert.NoError(t, err)
 
 	// same query DB
@@ -491,6 +550,72 @@ func TestContext_ResetAccountDB(t *testing.T) {
 	backupDB.Close()
 }
 
+func TestContext_ReadView(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	address := common.NewAddressFromString("hx11")
+	ia := IScoreAccount{Address: *address}
+	ia.BlockHeight = 100
+	ia.IScore.SetUint64(claimMinIScore * 2)
+	bucket, _ := ctx.DB.getQueryDB(*address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+	ia.BlockHeight = 200
+	ia.IScore.SetUint64(claimMinIScore * 3)
+	bucket, _ = ctx.DB.getCalculateDB(*address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+
+	view, err := ctx.DB.newReadView()
+	assert.NoError(t, err)
+
+	// claim after view was made is not in claim DB snapshot
+	claim := Claim{Address: *address}
+	claim.Data.BlockHeight = 100
+	claim.Data.IScore.SetUint64(claimMinIScore)
+	bucket, _ = ctx.DB.getClaimDB().GetBucket(db.PrefixClaim)
+	bucket.Set(claim.ID(), claim.Bytes())
+	c, err := view.getClaim(*address)
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+
+	// view reads pinned query DB after toggle
+	ctx.DB.toggleAccountDB(200)
+	resp := queryAccount(view, *address)
+	assert.Equal(t, uint64(100), resp.BlockHeight)
+	assert.Equal(t, 0, resp.IScore.Cmp(&common.NewHexIntFromUint64(claimMinIScore*2).Int))
+	resp = DoQuery(ctx, *address)
+	assert.Equal(t, uint64(200), resp.BlockHeight)
+	assert.Equal(t, 0, resp.IScore.Cmp(&common.NewHexIntFromUint64(claimMinIScore*2).Int))
+
+	// resetAccountDB waits for release of view
+	done := make(chan error, 1)
+	go func() {
+		done <- ctx.DB.resetAccountDB(200)
+	}()
+	select {
+	case <-done:
+		assert.Fail(t, "resetAccountDB did not wait for read view")
+	case <-time.After(100 * time.Millisecond):
+	}
+	resp = queryAccount(view, *address)
+	assert.Equal(t, uint64(100), resp.BlockHeight)
+
+	view.Release()
+	select {
+	case err = <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "resetAccountDB was not finished")
+	}
+
+	// new view does not wait
+	view, err = ctx.DB.newReadView()
+	assert.NoError(t, err)
+	resp = queryAccount(view, *address)
+	assert.Equal(t, uint64(200), resp.BlockHeight)
+	view.Release()
+}
+
 func TestContext_CurrentBlockInfo(t *testing.T) {
 	ctx := initTest(1)
 	defer finalizeTest(ctx)
@@ -501,9 +626,9 @@ func TestContext_CurrentBlockInfo(t *testing.T) {
 	)
 
 	blockHash1 := make([]byte, BlockHashSize)
-	copy(blockHash1,  []byte(string(blockHeight1)))
+	copy(blockHash1,  []byte(string(rune(blockHeight1))))
 	blockHash2 := make([]byte, BlockHashSize)
-	copy(blockHash2,  []byte(string(blockHeight2)))
+	copy(blockHash2,  []byte(string(rune(blockHeight2))))
 
 	ctx.DB.setCurrentBlockInfo(blockHeight2, blockHash2)
 	assert.True(t, ctx.DB.getCurrentBlockInfo().checkValue(blockHeight2, blockHash2))
@@ -526,10 +651,10 @@ func TestContext_AccountDBBlockHeight(t *testing.T) {
 	assert.Equal(t, blockHeight2, ctx.DB.getCalcDoneBH())
 	assert.Equal(t, blockHeight1, ctx.DB.getPrevCalcDoneBH())
 
-	ctx.DB.rollbackAccountDBBlockInfo()
+	ctx.DB.rollbackAccountDBBlockInfo(blockHeight1, 0)
 
 	assert.Equal(t, blockHeight1, ctx.DB.getCalcDoneBH())
-	assert.Equal(t, blockHeight1, ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(0), ctx.DB.getPrevCalcDoneBH())
 	assert.Equal(t, blockHeight1, ctx.DB.getCalculatingBH())
 }
 
@@ -562,9 +687,9 @@ func TestContext_RollbackAccountDB(t *testing.T) {
 	//assert.Error(t, err)
 
 	// reset account DB to make backup account DB
-	err = ctx.DB.resetAccountDB(blockHeight, ctx.DB.getCalcDoneBH())
+	err = ctx.DB.resetAccountDB(blockHeight)
 	assert.NoError(t, err)
-	WriteCalculationResult(crDB, blockHeight, nil, nil)
+	WriteCalculationResult(crDB, blockHeight, nil, nil, nil)
 	ctx.DB.setCalcDoneBH(blockHeight)
 	ctx.DB.writeToDB()
 	assert.Equal(t, prevBlockHeight, ctx.DB.getPrevCalcDoneBH())
@@ -593,9 +718,10 @@ func TestContext_RollbackAccountDB(t *testing.T) {
 	bs, _ = crBucket.Get(common.Uint64ToBytes(prevBlockHeight))
 	assert.Nil(t, bs)
 
-	// check Rollback block height and block hash
+	// check Rollback block height and block hash. backup account DB has previous calculation block height
 	assert.Equal(t, prevBlockHeight, ctx.DB.getCalcDoneBH())
-	assert.Equal(t, prevBlockHeight, ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(0), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, prevBlockHeight+1, ctx.DB.info.ToggleBH)
 
 	// read from query DB
 	qDB = ctx.DB.getQueryDB(ia.Address)
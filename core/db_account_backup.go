@@ -0,0 +1,357 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const DefaultAccountDBBackups = 1
+
+type AccountBackupData struct {
+	CalcBH uint64 // calculation block height of I-Scores in backup account DB
+}
+
+// AccountBackup is a generation of backup account DB, backup_<BlockHeight>_<index>.
+// resetAccountDB makes it with old query DB at BlockHeight and it has I-Scores calculated at CalcBH.
+type AccountBackup struct {
+	BlockHeight uint64
+	AccountBackupData
+}
+
+func (ab *AccountBackup) ID() []byte {
+	bs := make([]byte, 8)
+	id := common.Uint64ToBytes(ab.BlockHeight)
+	copy(bs[len(bs)-len(id):], id)
+	return bs
+}
+
+func (ab *AccountBackup) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&ab.AccountBackupData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (ab *AccountBackup) String() string {
+	b, err := json.Marshal(ab)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (ab *AccountBackup) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &ab.AccountBackupData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DBName returns name of backup account DB with index in [0, dbCount)
+func (ab *AccountBackup) DBName(index int) string {
+	return fmt.Sprintf(BackupDBNameFormat, ab.BlockHeight, index+1)
+}
+
+func writeAccountBackup(mngDB db.Database, blockHeight uint64, calcBH uint64) error {
+	ab := &AccountBackup{BlockHeight: blockHeight}
+	ab.CalcBH = calcBH
+
+	bucket, _ := mngDB.GetBucket(db.PrefixAccountBackup)
+	bs, err := ab.Bytes()
+	if err != nil {
+		return err
+	}
+	return bucket.Set(ab.ID(), bs)
+}
+
+func deleteAccountBackup(mngDB db.Database, blockHeight uint64) error {
+	ab := &AccountBackup{BlockHeight: blockHeight}
+
+	bucket, _ := mngDB.GetBucket(db.PrefixAccountBackup)
+	return bucket.Delete(ab.ID())
+}
+
+// LoadAccountBackups reads generations of backup account DB in block height order
+func LoadAccountBackups(mngDB db.Database) ([]*AccountBackup, error) {
+	backups := make([]*AccountBackup, 0)
+
+	iter, err := mngDB.GetIterator()
+	if err != nil {
+		return backups, err
+	}
+	// layer DB of simulation can't iterate
+	if iter == nil {
+		return backups, nil
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixAccountBackup))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		ab := new(AccountBackup)
+		if err = ab.SetBytes(iter.Value()); err != nil {
+			break
+		}
+		ab.BlockHeight = common.BytesToUint64(iter.Key()[len(db.PrefixAccountBackup):])
+		backups = append(backups, ab)
+	}
+	iter.Release()
+	if err != nil {
+		return backups, err
+	}
+	if err = iter.Error(); err != nil {
+		log.Printf("There is error while load account DB backup iteration. %+v", err)
+		return backups, err
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].BlockHeight < backups[j].BlockHeight
+	})
+
+	return backups, nil
+}
+
+// FindAccountBackup returns backup account DB which has I-Scores calculated at calcBH
+func FindAccountBackup(backups []*AccountBackup, calcBH uint64) *AccountBackup {
+	for i := len(backups) - 1; i >= 0; i-- {
+		if backups[i].CalcBH == calcBH {
+			return backups[i]
+		}
+	}
+	return nil
+}
+
+// getAccountBackupBHs returns block heights of backup account DBs in DB root in descending order
+func (idb *IScoreDB) getAccountBackupBHs() ([]uint64, error) {
+	files, err := filepath.Glob(filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint64]bool)
+	blockHeights := make([]uint64, 0)
+	for _, f := range files {
+		var blockHeight uint64
+		var index int
+		_, name := filepath.Split(f)
+		if n, _ := fmt.Sscanf(name, BackupDBNameFormat, &blockHeight, &index); n != 2 {
+			continue
+		}
+		if !found[blockHeight] {
+			found[blockHeight] = true
+			blockHeights = append(blockHeights, blockHeight)
+		}
+	}
+	sort.Slice(blockHeights, func(i, j int) bool {
+		return blockHeights[i] > blockHeights[j]
+	})
+
+	return blockHeights, nil
+}
+
+// pruneAccountBackups deletes backup account DBs except the latest backupCount - 1 generations
+// and the generation of blockHeight. Caller must hold write lock of account DB.
+func (idb *IScoreDB) pruneAccountBackups(blockHeight uint64) error {
+	blockHeights, err := idb.getAccountBackupBHs()
+	if err != nil {
+		log.Printf("Failed to get old backup account DB. %v", err)
+		return err
+	}
+
+	retain := idb.getAccountDBBackups() - 1
+	for _, bh := range blockHeights {
+		if bh == blockHeight {
+			continue
+		}
+		if retain > 0 {
+			retain--
+			continue
+		}
+
+		if err = idb.deleteAccountBackupDBs(bh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pruneCommittedAccountBackups deletes old backup account DBs after calculation of blockHeight is committed.
+// They are kept until commit to roll back calculation interrupted by crash
+func (idb *IScoreDB) pruneCommittedAccountBackups(blockHeight uint64) error {
+	idb.accountLock.Lock()
+	defer idb.accountLock.Unlock()
+
+	return idb.pruneAccountBackups(blockHeight)
+}
+
+// deleteAccountBackupDBs deletes backup account DBs made at blockHeight and its management data
+func (idb *IScoreDB) deleteAccountBackupDBs(blockHeight uint64) error {
+	oldBackup := idb.accountBackupPattern(blockHeight)
+	oldBackups, _ := filepath.Glob(oldBackup)
+	log.Printf("delete old backup %d account DBs. %s", len(oldBackups), oldBackup)
+	for _, f := range oldBackups {
+		if err := os.RemoveAll(f); err != nil {
+			log.Printf("Failed to delete old backup account DB %s. %v", f, err)
+			return err
+		}
+	}
+	return deleteAccountBackup(idb.management, blockHeight)
+}
+
+// accountBackupPattern returns file pattern of backup account DBs made at blockHeight
+func (idb *IScoreDB) accountBackupPattern(blockHeight uint64) string {
+	return filepath.Join(idb.info.DBRoot, fmt.Sprintf(BackupDBNamePrefix+"%d_*", blockHeight))
+}
+
+// getRetainedCalcBH returns block height of the oldest calculation which rollback can restore previous one of.
+// GV and P-Rep list before it are not needed except the latest ones.
+func (idb *IScoreDB) getRetainedCalcBH() uint64 {
+	blockHeight := idb.info.PrevCalcDone
+	backups, err := LoadAccountBackups(idb.management)
+	if err != nil {
+		log.Printf("Failed to load backup account DB. %v", err)
+		return blockHeight
+	}
+	// old generations are deleted at commit of calculation in progress
+	if n := idb.getAccountDBBackups(); len(backups) > n {
+		backups = backups[len(backups)-n:]
+	}
+	if len(backups) > 0 && backups[0].CalcBH < blockHeight {
+		blockHeight = backups[0].CalcBH
+	}
+	return blockHeight
+}
+
+// getRollbackChain returns block heights of calculations from CalcDone in descending order and
+// backup account DB generations which can restore them.
+// backups[i] is made by calculation calcBHs[i] and has I-Scores calculated at calcBHs[i+2].
+// Account DB can be rolled back over the latest max(1, len(backups)) calculations.
+func (idb *IScoreDB) getRollbackChain() (calcBHs []uint64, backups []*AccountBackup, err error) {
+	calcBHs = []uint64{idb.info.CalcDone, idb.info.PrevCalcDone}
+	backups = make([]*AccountBackup, 0)
+
+	// previous calculation is unknown
+	if idb.info.PrevCalcDone == idb.info.CalcDone {
+		return calcBHs, backups, nil
+	}
+
+	generations, err := LoadAccountBackups(idb.management)
+	if err != nil {
+		return calcBHs, backups, err
+	}
+	blockHeights, err := idb.getAccountBackupBHs()
+	if err != nil {
+		return calcBHs, backups, err
+	}
+	exist := make(map[uint64]bool, len(blockHeights))
+	for _, bh := range blockHeights {
+		exist[bh] = true
+	}
+
+	for i := len(generations) - 1; i >= 0; i-- {
+		ab := generations[i]
+		n := len(backups)
+		if ab.BlockHeight != calcBHs[n] || ab.CalcBH >= calcBHs[n+1] || !exist[ab.BlockHeight] {
+			break
+		}
+		backups = append(backups, ab)
+		calcBHs = append(calcBHs, ab.CalcBH)
+	}
+
+	return calcBHs, backups, nil
+}
+
+// getRollbackLimitBH returns the highest block height which account DB can't be rolled back to.
+// It is PrevCalcDone with the latest backup generation and goes down with retained generations.
+func (idb *IScoreDB) getRollbackLimitBH() (uint64, error) {
+	calcBHs, backups, err := idb.getRollbackChain()
+	if err != nil {
+		return 0, err
+	}
+	if len(backups) > 1 {
+		return calcBHs[len(backups)], nil
+	}
+	return calcBHs[1], nil
+}
+
+// claimBackupCoversRollback returns false if claim backup period is shorter than block heights which account DB
+// can be rolled back with retained backup account DB generations. Calculation term is estimated with the latest
+// calculation. Rollback to block height lower than claim backup period fails even though account DB can be rolled back
+func (idb *IScoreDB) claimBackupCoversRollback() bool {
+	term := idb.info.CalcDone - idb.info.PrevCalcDone
+	if idb.info.CalcDone < idb.info.PrevCalcDone || term == 0 {
+		return true
+	}
+
+	// account DB can be rolled back to the calculation before retained generations and blocks after it
+	blocks := term * uint64(idb.getAccountDBBackups()+1)
+	period := idb.getClaimBackupPeriod()
+	if blocks > period+1 {
+		log.Printf("Claim backup period %d can't cover %d blocks of %d backup account DB generations with term %d. "+
+			"Set claim backup period to %d or higher", period, blocks, idb.getAccountDBBackups(), term, blocks-1)
+		return false
+	}
+	return true
+}
+
+// readAccountDBAt calls f with account DB of address which has I-Scores calculated at calcBH.
+// It reads calculate DB, query DB and retained backup account DBs. Only the backup account DB of address is opened.
+// Account DB is not toggled, reset or rolled back while f reads account DB.
+func (idb *IScoreDB) readAccountDBAt(calcBH uint64, address common.Address, f func(accountDB db.Database)) (uint16, error) {
+	idb.accountLock.RLock()
+	defer idb.accountLock.RUnlock()
+
+	if idb.isCalculating() {
+		return QueryAtStatusCalculating, nil
+	}
+
+	index := idb.getAccountDBIndex(address)
+	switch calcBH {
+	case idb.info.CalcDone:
+		f(idb._getCalcDBList()[index])
+		return QueryAtStatusOK, nil
+	case idb.info.PrevCalcDone:
+		if idb.info.QueryDBIsZero {
+			f(idb.Account0[index])
+		} else {
+			f(idb.Account1[index])
+		}
+		return QueryAtStatusOK, nil
+	}
+
+	backups, err := LoadAccountBackups(idb.management)
+	if err != nil {
+		return QueryAtStatusFailed, err
+	}
+	backup := FindAccountBackup(backups, calcBH)
+	if backup == nil {
+		return QueryAtStatusInvalidBH, nil
+	}
+
+	if _, err = os.Stat(filepath.Join(idb.info.DBRoot, backup.DBName(index))); err != nil {
+		return QueryAtStatusFailed, err
+	}
+
+	// DB can't be opened twice at the same time
+	idb.backupLock.Lock()
+	defer idb.backupLock.Unlock()
+
+	bDB := db.Open(idb.info.DBRoot, idb.info.DBType, backup.DBName(index))
+	defer bDB.Close()
+	f(bDB)
+
+	return QueryAtStatusOK, nil
+}
@@ -1,11 +1,13 @@
 package core
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"github.com/icon-project/rewardcalculator/common/db"
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/syndtr/goleveldb/leveldb/util"
 )
 type CRData struct {
 	Success bool
@@ -14,6 +16,10 @@ type CRData struct {
 	Beta1 common.HexInt
 	Beta2 common.HexInt
 	Beta3 common.HexInt
+	MerkleRoot []byte
+	// error code and message of failed calculation
+	ErrorCode uint16
+	ErrorMessage string
 }
 
 type CalculationResult struct {
@@ -60,12 +66,14 @@ func NewCalculationResultFromBytes(bs []byte) (*CalculationResult, error) {
 	}
 }
 
-func WriteCalculationResult(crDB db.Database, blockHeight uint64, stats *Statistics, stateHash []byte) {
+func WriteCalculationResult(crDB db.Database, blockHeight uint64, stats *Statistics, stateHash []byte,
+	merkleRoot []byte) {
 	cr := new(CalculationResult)
 
 	cr.Success = true
 	cr.BlockHeight = blockHeight
 	cr.StateHash = stateHash
+	cr.MerkleRoot = merkleRoot
 	if stats != nil {
 		cr.IScore.Set(&stats.TotalReward.Int)
 		cr.Beta1.Set(&stats.Beta1.Int)
@@ -78,6 +86,26 @@ func WriteCalculationResult(crDB db.Database, blockHeight uint64, stats *Statist
 	bucket.Set(cr.ID(), bs)
 }
 
+// WriteCalculationFailure writes failed calculation result with error code and message.
+// Calculation result of blockHeight is not overwritten
+func WriteCalculationFailure(crDB db.Database, blockHeight uint64, code uint16, message string) error {
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+
+	cr := new(CalculationResult)
+	cr.BlockHeight = blockHeight
+	if bucket.Has(cr.ID()) {
+		return nil
+	}
+	cr.ErrorCode = code
+	cr.ErrorMessage = message
+
+	bs, err := cr.Bytes()
+	if err != nil {
+		return err
+	}
+	return bucket.Set(cr.ID(), bs)
+}
+
 func DeleteCalculationResult(crDB db.Database, blockHeight uint64) {
 	cr := new(CalculationResult)
 
@@ -86,3 +114,81 @@ func DeleteCalculationResult(crDB db.Database, blockHeight uint64) {
 	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
 	bucket.Delete(cr.ID())
 }
+
+// CALCULATE_DONE outbox in calculation result DB.
+// CALCULATE_DONE is kept until it is sent to peer, or until peer acknowledges it if ACK_CALCULATE_DONE is enabled.
+// CALCULATE_DONE in outbox is sent to new connection
+func WriteCalculateDoneOutbox(crDB db.Database, done *CalculateDone) error {
+	bs, err := codec.MarshalToBytes(done)
+	if err != nil {
+		return err
+	}
+
+	bucket, _ := crDB.GetBucket(db.PrefixCalcDoneOutbox)
+	return bucket.Set(calculateDoneOutboxKey(done.BlockHeight), bs)
+}
+
+// calculateDoneOutboxKeySize is size of outbox key without prefix.
+// Keys of calculation result are block heights shorter than it, and some of them start with outbox prefix
+const calculateDoneOutboxKeySize = 8
+
+// key of outbox is fixed size to iterate in block height order
+func calculateDoneOutboxKey(blockHeight uint64) []byte {
+	key := make([]byte, calculateDoneOutboxKeySize)
+	binary.BigEndian.PutUint64(key, blockHeight)
+	return key
+}
+
+// ReadCalculateDoneOutbox returns CALCULATE_DONE in outbox in block height order
+func ReadCalculateDoneOutbox(crDB db.Database) ([]*CalculateDone, error) {
+	iter, err := crDB.GetIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	doneList := make([]*CalculateDone, 0)
+	prefix := util.BytesPrefix([]byte(db.PrefixCalcDoneOutbox))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		// skip calculation result
+		if len(iter.Key()) != len(db.PrefixCalcDoneOutbox)+calculateDoneOutboxKeySize {
+			continue
+		}
+		done := new(CalculateDone)
+		if _, err = codec.UnmarshalFromBytes(iter.Value(), done); err != nil {
+			break
+		}
+		doneList = append(doneList, done)
+	}
+	iter.Release()
+	if err != nil {
+		return nil, err
+	}
+	if err = iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return doneList, nil
+}
+
+// DeleteCalculateDoneOutbox deletes CALCULATE_DONE in outbox with block height in [from, to]
+func DeleteCalculateDoneOutbox(crDB db.Database, from uint64, to uint64) (int, error) {
+	doneList, err := ReadCalculateDoneOutbox(crDB)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	bucket, _ := crDB.GetBucket(db.PrefixCalcDoneOutbox)
+	for _, done := range doneList {
+		if done.BlockHeight < from || done.BlockHeight > to {
+			continue
+		}
+		if err = bucket.Delete(calculateDoneOutboxKey(done.BlockHeight)); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
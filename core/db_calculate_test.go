@@ -3,9 +3,11 @@ package core
 import (
 	"encoding/binary"
 	"github.com/icon-project/rewardcalculator/common/db"
+	"math"
 	"testing"
 
 	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -50,6 +52,34 @@ func TestDBCalculate_BytesAndSetBytes(t *testing.T) {
 	assert.Equal(t, bs, bsNew)
 }
 
+func TestDBCalculate_SetBytesWithoutMerkleRoot(t *testing.T) {
+	calculationResult := makeCalcResult()
+
+	// calculation result written before Merkle root was added
+	type crDataV1 struct {
+		Success   bool
+		StateHash []byte
+		IScore    common.HexInt
+		Beta1     common.HexInt
+		Beta2     common.HexInt
+		Beta3     common.HexInt
+	}
+	v1 := crDataV1{
+		Success:   calculationResult.Success,
+		StateHash: calculationResult.StateHash,
+		IScore:    calculationResult.IScore,
+	}
+	bs, err := codec.MarshalToBytes(&v1)
+	assert.NoError(t, err)
+
+	calcResultNew, err := NewCalculationResultFromBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, calculationResult.Success, calcResultNew.Success)
+	assert.Equal(t, 0, calculationResult.IScore.Cmp(&calcResultNew.IScore.Int))
+	assert.Equal(t, calculationResult.StateHash, calcResultNew.StateHash)
+	assert.Equal(t, 0, len(calcResultNew.MerkleRoot))
+}
+
 func TestDBCalculate_NewClaimFromBytes(t *testing.T) {
 	calculationResult := makeCalcResult()
 
@@ -78,7 +108,10 @@ func TestDBCalculate_CalculationResult(t *testing.T) {
 	stateHash := make([]byte, 64)
 	binary.BigEndian.PutUint64(stateHash, calcBlockHeight)
 
-	WriteCalculationResult(crDB, calcBlockHeight, stats, stateHash)
+	merkleRoot := make([]byte, 32)
+	binary.BigEndian.PutUint64(merkleRoot, calcBlockHeight)
+
+	WriteCalculationResult(crDB, calcBlockHeight, stats, stateHash, merkleRoot)
 
 	bucket, err := crDB.GetBucket(db.PrefixCalcResult)
 	assert.NoError(t, err)
@@ -91,9 +124,90 @@ func TestDBCalculate_CalculationResult(t *testing.T) {
 	assert.True(t, calculationResult.Success)
 	assert.Equal(t, 0, calculationResult.IScore.Cmp(&stats.TotalReward.Int))
 	assert.Equal(t, stateHash, calculationResult.StateHash)
+	assert.Equal(t, merkleRoot, calculationResult.MerkleRoot)
 
 	DeleteCalculationResult(crDB, calcBlockHeight)
 
 	bs, _ = bucket.Get(common.Uint64ToBytes(calcBlockHeight))
 	assert.Nil(t, bs)
 }
+
+func TestDBCalculate_CalculateDoneOutbox(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	crDB := ctx.DB.getCalculateResultDB()
+
+	// write CALCULATE_DONE and calculation result
+	blockHeights := []uint64{calcBlockHeight * 2, 255, 256, calcBlockHeight}
+	for _, blockHeight := range blockHeights {
+		done := CalculateDone{Success: true, BlockHeight: blockHeight, StateHash: []byte("state hash")}
+		done.IScore.SetUint64(blockHeight)
+		err := WriteCalculateDoneOutbox(crDB, &done)
+		assert.NoError(t, err)
+	}
+	WriteCalculationResult(crDB, calcBlockHeight, nil, nil, nil)
+
+	// read in block height order
+	doneList, err := ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(doneList))
+	for i, blockHeight := range []uint64{255, 256, calcBlockHeight, calcBlockHeight * 2} {
+		assert.Equal(t, blockHeight, doneList[i].BlockHeight)
+		assert.Equal(t, 0, doneList[i].IScore.Cmp(&common.NewHexIntFromUint64(blockHeight).Int))
+		assert.Equal(t, []byte("state hash"), doneList[i].StateHash)
+	}
+
+	// rollback
+	err = rollbackCalculateDoneOutbox(ctx, calcBlockHeight)
+	assert.NoError(t, err)
+	doneList, err = ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(doneList))
+	assert.Equal(t, calcBlockHeight, doneList[2].BlockHeight)
+
+	// acknowledge
+	err = DoAckCalculateDone(ctx, 256)
+	assert.NoError(t, err)
+	doneList, err = ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(doneList))
+	assert.Equal(t, calcBlockHeight, doneList[0].BlockHeight)
+
+	// calculation result is not changed
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+	bs, _ := bucket.Get(common.Uint64ToBytes(calcBlockHeight))
+	assert.NotNil(t, bs)
+}
+
+func TestDBCalculate_CalculateDoneOutboxWithCalculationResult(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	crDB := ctx.DB.getCalculateResultDB()
+
+	// keys of calculation results at 0x434F, 0x434F00 and 0x434F0000 start with outbox prefix "CO"
+	resultBHs := []uint64{0x434F, 0x434F00, 0x434F0000}
+	for _, blockHeight := range resultBHs {
+		assert.Equal(t, string(db.PrefixCalcDoneOutbox), string(common.Uint64ToBytes(blockHeight)[:2]))
+		WriteCalculationResult(crDB, blockHeight, nil, nil, nil)
+	}
+	done := CalculateDone{Success: true, BlockHeight: 0x434F}
+	assert.NoError(t, WriteCalculateDoneOutbox(crDB, &done))
+
+	doneList, err := ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(doneList))
+	assert.Equal(t, done.BlockHeight, doneList[0].BlockHeight)
+
+	count, err := DeleteCalculateDoneOutbox(crDB, 0, math.MaxUint64)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	doneList, err = ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	// calculation results are not changed
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+	for _, blockHeight := range resultBHs {
+		assert.True(t, bucket.Has(common.Uint64ToBytes(blockHeight)))
+	}
+}
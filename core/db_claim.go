@@ -20,7 +20,9 @@ const (
 	PreCommitIDSize = BlockHeightSize + BlockHashSize + common.AddressBytes
 
 	ClaimBackupIDSize = BlockHeightSize + common.AddressBytes
-	ClaimBackupPeriod = 43120 * 2 - 1
+
+	// claim backup DB keeps claims of DefaultClaimBackupPeriod blocks for rollback
+	DefaultClaimBackupPeriod = 43120 * 2 - 1
 )
 
 type ClaimData struct {
@@ -344,7 +346,7 @@ func deletePreCommit(pcDB db.Database, start []byte, limit []byte) error {
 }
 
 func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claimBackupDB db.Database,
-	blockHeight uint64, blockHash []byte) error {
+	claimHistoryDB db.Database, claimBackupPeriod uint64, blockHeight uint64, blockHash []byte) error {
 	iter, err := preCommitDB.GetIterator()
 	if err != nil {
 		return err
@@ -352,9 +354,11 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 
 	// iterate & get values to write
 	var pc PreCommit
-	var claim Claim
 	bucket, _ := claimDB.GetBucket(db.PrefixIScore)
 	cbBucket, _ := claimBackupDB.GetBucket(db.PrefixIScore)
+	chList := make([]*ClaimRecord, 0)
+	claimList := make([]*Claim, 0)
+	backupList := make([][]byte, 0)
 
 	prefix := MakeIteratorPrefix(db.PrefixClaim, blockHeight, blockHash, BlockHashSize)
 	iter.New(prefix.Start, prefix.Limit)
@@ -364,7 +368,11 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 			break
 		}
 
-		claim = pc.Claim
+		pc.SetID(iter.Key()[len(db.PrefixClaim):])
+		claim := new(Claim)
+		claim.Address = pc.Address
+		claim.Data.BlockHeight = pc.Data.BlockHeight
+		claim.Data.IScore.Set(&pc.Data.IScore.Int)
 		if pc.Confirmed == false || claim.Data.IScore.Sign() == 0 {
 			log.Printf("Do not write precommit data to claim DB. (precommit: %s)", pc.String())
 			continue
@@ -379,20 +387,14 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 			}
 			// update with old I-Score
 			claim.Data.IScore.Add(&claim.Data.IScore.Int, &oldClaim.Data.IScore.Int)
-		}
-
-		// read value original from claim DB
-		if bs, _ := bucket.Get(claim.ID()); bs != nil {
-			// write original value to claim backup DB
-			cbBucket.Set(claim.BackupID(blockHeight - 1), bs)
 		} else {
 			// write empty value to claim backup DB
 			var nilClaim Claim
-			cbBucket.Set(claim.BackupID(blockHeight - 1), nilClaim.Bytes())
+			bs = nilClaim.Bytes()
 		}
-
-		// write to claim DB
-		bucket.Set(claim.ID(), claim.Bytes())
+		chList = append(chList, newClaimRecord(&pc))
+		claimList = append(claimList, claim)
+		backupList = append(backupList, bs)
 	}
 	iter.Release()
 	if err != nil {
@@ -405,7 +407,26 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 		return err
 	}
 
-	err = writeClaimBackupInfo(claimBackupDB, blockHeight)
+	// write claims to claim history before claim DB.
+	// Claims written to claim DB are not written again with same preCommit data after crash,
+	// but claim history can be written again with same keys
+	if err = writeClaimHistory(claimHistoryDB, chList); err != nil {
+		log.Printf("Failed to write claim history. %v", err)
+		return err
+	}
+	crashPoint()
+
+	for i, claim := range claimList {
+		// write original value to claim backup DB
+		cbBucket.Set(claim.BackupID(blockHeight - 1), backupList[i])
+
+		// write to claim DB
+		bucket.Set(claim.ID(), claim.Bytes())
+		metricClaimCommits.Inc()
+		crashPoint()
+	}
+
+	err = writeClaimBackupInfo(claimBackupDB, blockHeight, claimBackupPeriod)
 	if err != nil {
 		return err
 	}
@@ -414,7 +435,7 @@ func writePreCommitToClaimDB(preCommitDB db.Database, claimDB db.Database, claim
 	return flushPreCommit(preCommitDB, blockHeight, nil)
 }
 
-func writeClaimBackupInfo(claimBackupDB db.Database, blockHeight uint64) error {
+func writeClaimBackupInfo(claimBackupDB db.Database, blockHeight uint64, period uint64) error {
 	var cbInfo ClaimBackupInfo
 	cbBucket, _ := claimBackupDB.GetBucket(db.PrefixManagement)
 	bs, err := cbBucket.Get(cbInfo.ID())
@@ -435,8 +456,8 @@ func writeClaimBackupInfo(claimBackupDB db.Database, blockHeight uint64) error {
 	}
 
 	// do garbage collection of claim backup DB
-	if blockHeight > ClaimBackupPeriod + cbInfo.FirstBlockHeight {
-		garbageBlock := blockHeight - ClaimBackupPeriod - 1
+	if blockHeight > period + cbInfo.FirstBlockHeight {
+		garbageBlock := blockHeight - period - 1
 
 		err = garbageCollectClaimBackupDB(claimBackupDB, cbInfo.FirstBlockHeight, garbageBlock)
 		if err != nil {
@@ -506,6 +527,25 @@ func checkClaimDBRollback(cbInfo *ClaimBackupInfo, rollback uint64) (bool, error
 	return true, nil
 }
 
+// loadClaimBackupInfo reads block heights of claims in claim backup DB
+func loadClaimBackupInfo(cbDB db.Database) (*ClaimBackupInfo, error) {
+	cbInfo := new(ClaimBackupInfo)
+	bucket, err := cbDB.GetBucket(db.PrefixManagement)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := bucket.Get(cbInfo.ID())
+	if err != nil || bs == nil {
+		return cbInfo, err
+	}
+	if err = cbInfo.SetBytes(bs); err != nil {
+		return nil, err
+	}
+	return cbInfo, nil
+}
+
+// rollbackClaimDB rolls back claim DB, current block info and claim history to block height to.
+// It is applied again after crash until rollback journal leaves claim phase, so each step must be idempotent
 func rollbackClaimDB(ctx *Context, to uint64, blockHash []byte) error {
 	log.Printf("Start Rollback claim DB to %d", to)
 	idb := ctx.DB
@@ -516,34 +556,47 @@ func rollbackClaimDB(ctx *Context, to uint64, blockHash []byte) error {
 		return err
 	}
 
-	var cbInfo ClaimBackupInfo
-	bs, _ := bucket.Get(cbInfo.ID())
-	cbInfo.SetBytes(bs)
-
-	// check Rollback block height
-	if ok, err := checkClaimDBRollback(&cbInfo, to); ok != true {
+	cbInfo, err := loadClaimBackupInfo(cbDB)
+	if err != nil {
 		return err
 	}
 
-	from := cbInfo.LastBlockHeight
-
-	cBucket, err := cDB.GetBucket(db.PrefixClaim)
+	// check Rollback block height
+	ok, err := checkClaimDBRollback(cbInfo, to)
 	if err != nil {
 		return err
 	}
 
-	for i := from; to <= i; i-- {
-		err = _rollbackClaimDB(cbDB, cBucket, i)
+	from := cbInfo.LastBlockHeight
+	if ok {
+		cBucket, err := cDB.GetBucket(db.PrefixClaim)
 		if err != nil {
 			return err
 		}
-	}
 
-	// update management Info.
-	cbInfo.LastBlockHeight = to
-	bucket.Set(cbInfo.ID(), cbInfo.Bytes())
+		for i := from; to <= i; i-- {
+			err = _rollbackClaimDB(cbDB, cBucket, i)
+			if err != nil {
+				return err
+			}
+		}
+
+		// update management Info.
+		cbInfo.LastBlockHeight = to
+		bucket.Set(cbInfo.ID(), cbInfo.Bytes())
+		crashPoint()
+	}
 
+	// claim DB may be rolled back before crash already, so block info and claim history are rolled back always
 	idb.rollbackCurrentBlockInfo(to, blockHash)
+	crashPoint()
+
+	// claims above rollback block height are reverted
+	if err = rollbackClaimHistory(idb.getClaimHistoryDB(), to); err != nil {
+		log.Printf("Failed to Rollback claim history. %+v", err)
+		return err
+	}
+	crashPoint()
 
 	log.Printf("End Rollback claim DB from %d to %d", from, to)
 	return nil
@@ -586,6 +639,7 @@ func _rollbackClaimDB(cbDB db.Database, cBucket db.Bucket, blockHeight uint64) e
 	}
 	for _, v := range keys {
 		cbBucket.Delete(v)
+		crashPoint()
 	}
 
 	return nil
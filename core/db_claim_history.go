@@ -0,0 +1,228 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	ClaimHistoryDBName = "claim_history"
+
+	ClaimRecordIDSize = common.AddressBytes + BlockHeightSize + BlockHeightSize
+)
+
+type ClaimRecordData struct {
+	BlockHash []byte
+	TXHash    []byte
+	// claimed I-Score
+	IScore common.HexInt
+}
+
+// ClaimRecord is a claim committed to claim DB at BlockHeight
+type ClaimRecord struct {
+	Address     common.Address
+	BlockHeight uint64
+	TXIndex     uint64
+	ClaimRecordData
+}
+
+func (ch *ClaimRecord) ID() []byte {
+	return claimHistoryKey(ch.Address, ch.BlockHeight, ch.TXIndex)
+}
+
+// IndexID returns key of claim history in block height order
+func (ch *ClaimRecord) IndexID() []byte {
+	id := make([]byte, ClaimRecordIDSize)
+	copy(id, claimHistoryBlockHeight(ch.BlockHeight))
+	copy(id[BlockHeightSize:], ch.Address.Bytes())
+	copy(id[BlockHeightSize+common.AddressBytes:], claimHistoryBlockHeight(ch.TXIndex))
+	return id
+}
+
+func (ch *ClaimRecord) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&ch.ClaimRecordData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (ch *ClaimRecord) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d, BlockHash: %s, TXIndex: %d, TXHash: %s, IScore: %s",
+		ch.Address.String(),
+		ch.BlockHeight,
+		hex.EncodeToString(ch.BlockHash),
+		ch.TXIndex,
+		hex.EncodeToString(ch.TXHash),
+		ch.IScore.String())
+}
+
+func (ch *ClaimRecord) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &ch.ClaimRecordData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewClaimRecordFromBytes makes ClaimRecord with key without bucket prefix and value
+func NewClaimRecordFromBytes(key []byte, value []byte) (*ClaimRecord, error) {
+	ch := new(ClaimRecord)
+	if err := ch.SetBytes(value); err != nil {
+		return nil, err
+	}
+	ch.Address = *common.NewAddress(key[:common.AddressBytes])
+	ch.BlockHeight = common.BytesToUint64(key[common.AddressBytes : common.AddressBytes+BlockHeightSize])
+	ch.TXIndex = common.BytesToUint64(key[common.AddressBytes+BlockHeightSize:])
+	return ch, nil
+}
+
+// newClaimRecord makes claim record with precommit data of a claim
+func newClaimRecord(pc *PreCommit) *ClaimRecord {
+	ch := new(ClaimRecord)
+	ch.Address = pc.Address
+	ch.BlockHeight = pc.BlockHeight
+	ch.TXIndex = pc.TXIndex
+	ch.BlockHash = make([]byte, BlockHashSize)
+	copy(ch.BlockHash, pc.BlockHash)
+	ch.TXHash = make([]byte, TXHashSize)
+	copy(ch.TXHash, pc.TXHash)
+	ch.IScore.Set(&pc.Data.IScore.Int)
+	return ch
+}
+
+// fixed size block height to iterate claim history in block height order
+func claimHistoryBlockHeight(blockHeight uint64) []byte {
+	bs := make([]byte, BlockHeightSize)
+	bh := common.Uint64ToBytes(blockHeight)
+	copy(bs[BlockHeightSize-len(bh):], bh)
+	return bs
+}
+
+func claimHistoryKey(address common.Address, blockHeight uint64, txIndex uint64) []byte {
+	id := make([]byte, ClaimRecordIDSize)
+	copy(id, address.Bytes())
+	copy(id[common.AddressBytes:], claimHistoryBlockHeight(blockHeight))
+	copy(id[common.AddressBytes+BlockHeightSize:], claimHistoryBlockHeight(txIndex))
+	return id
+}
+
+// ClaimHistoryIteratorRange returns range to iterate claims of address committed from block height from to to.
+// to is not limited if it is 0
+func ClaimHistoryIteratorRange(address common.Address, from uint64, to uint64) *util.Range {
+	prefix := append([]byte(db.PrefixClaimHistory), address.Bytes()...)
+	r := util.BytesPrefix(prefix)
+	r.Start = append(prefix, claimHistoryBlockHeight(from)...)
+	if to != 0 && to != ^uint64(0) {
+		r.Limit = append(append([]byte{}, prefix...), claimHistoryBlockHeight(to+1)...)
+	}
+	return r
+}
+
+// writeClaimHistory writes claims committed to claim DB
+func writeClaimHistory(chDB db.Database, chList []*ClaimRecord) error {
+	if chDB == nil || len(chList) == 0 {
+		return nil
+	}
+
+	batch, err := chDB.GetBatch()
+	if err != nil {
+		return err
+	}
+	batch.New()
+	for _, ch := range chList {
+		bs, err := ch.Bytes()
+		if err != nil {
+			return err
+		}
+		batch.Set(append([]byte(db.PrefixClaimHistory), ch.ID()...), bs)
+		batch.Set(append([]byte(db.PrefixClaimHistoryIndex), ch.IndexID()...), []byte{})
+	}
+	return batch.Write()
+}
+
+// ReadClaimHistory reads claims of address committed from block height from to to in block height order.
+// to is not limited if it is 0
+func ReadClaimHistory(chDB db.Database, address common.Address, from uint64, to uint64) ([]*ClaimRecord, error) {
+	chList := make([]*ClaimRecord, 0)
+
+	iter, err := chDB.GetIterator()
+	if err != nil {
+		return chList, err
+	}
+
+	r := ClaimHistoryIteratorRange(address, from, to)
+	iter.New(r.Start, r.Limit)
+	for iter.Next() {
+		var ch *ClaimRecord
+		ch, err = NewClaimRecordFromBytes(iter.Key()[len(db.PrefixClaimHistory):], iter.Value())
+		if err != nil {
+			break
+		}
+		chList = append(chList, ch)
+	}
+	iter.Release()
+	if err != nil {
+		return chList, err
+	}
+	if err = iter.Error(); err != nil {
+		return chList, err
+	}
+
+	return chList, nil
+}
+
+// rollbackClaimHistory deletes claims committed above rollback block height
+func rollbackClaimHistory(chDB db.Database, blockHeight uint64) error {
+	if chDB == nil {
+		return nil
+	}
+
+	iter, err := chDB.GetIterator()
+	if err != nil {
+		return err
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixClaimHistoryIndex))
+	start := append([]byte(db.PrefixClaimHistoryIndex), claimHistoryBlockHeight(blockHeight+1)...)
+	keys := make([][]byte, 0)
+	iter.New(start, prefix.Limit)
+	for iter.Next() {
+		key := make([]byte, ClaimRecordIDSize)
+		copy(key, iter.Key()[len(db.PrefixClaimHistoryIndex):])
+		keys = append(keys, key)
+	}
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		log.Printf("There is error while rollback claim history. %v", err)
+		return err
+	}
+
+	bucket, _ := chDB.GetBucket(db.PrefixClaimHistory)
+	indexBucket, _ := chDB.GetBucket(db.PrefixClaimHistoryIndex)
+	for _, key := range keys {
+		address := *common.NewAddress(key[BlockHeightSize : BlockHeightSize+common.AddressBytes])
+		bh := common.BytesToUint64(key[:BlockHeightSize])
+		txIndex := common.BytesToUint64(key[BlockHeightSize+common.AddressBytes:])
+		if err = bucket.Delete(claimHistoryKey(address, bh, txIndex)); err != nil {
+			return err
+		}
+		if err = indexBucket.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (idb *IScoreDB) getClaimHistoryDB() db.Database {
+	return idb.claimHistory
+}
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func commitTestClaim(t *testing.T, ctx *Context, address common.Address, blockHeight uint64, txIndex uint64,
+	iScore uint64) {
+	preCommitTestClaim(t, ctx, address, blockHeight, txIndex, iScore)
+	assert.NoError(t, commitTestBlock(ctx, blockHeight))
+}
+
+// preCommitTestClaim writes confirmed claim of address to preCommit DB
+func preCommitTestClaim(t *testing.T, ctx *Context, address common.Address, blockHeight uint64, txIndex uint64,
+	iScore uint64) {
+	pcDB := ctx.DB.getPreCommitDB()
+	hash := common.Uint64ToBytes(blockHeight)
+
+	pc := newPreCommit(blockHeight, hash, txIndex, hash, address)
+	assert.NoError(t, pc.write(pcDB, common.NewHexIntFromUint64(iScore)))
+	assert.NoError(t, pc.commit(pcDB))
+}
+
+// commitTestBlock writes claims in preCommit DB to claim DB like COMMIT_BLOCK
+func commitTestBlock(ctx *Context, blockHeight uint64) error {
+	return writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
+		ctx.DB.getClaimHistoryDB(), ctx.DB.getClaimBackupPeriod(), blockHeight, common.Uint64ToBytes(blockHeight))
+}
+
+func TestDBClaimHistory_WriteAndRead(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	chDB := ctx.DB.getClaimHistoryDB()
+
+	addr1 := *common.NewAddressFromString("hx11")
+	addr2 := *common.NewAddressFromString("hx22")
+
+	commitTestClaim(t, ctx, addr1, 10, 1, 100)
+	commitTestClaim(t, ctx, addr2, 10, 2, 200)
+	commitTestClaim(t, ctx, addr1, 20, 0, 300)
+	commitTestClaim(t, ctx, addr1, 30, 5, 400)
+
+	// claim DB has cumulative I-Score
+	claim, _ := getClaimFromClaimDB(ctx, addr1)
+	assert.Equal(t, uint64(100+300+400), claim.Data.IScore.Uint64())
+
+	// claim history has each claim
+	chList, err := ReadClaimHistory(chDB, addr1, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(chList))
+	expected := []struct {
+		blockHeight uint64
+		txIndex     uint64
+		iScore      uint64
+	}{{10, 1, 100}, {20, 0, 300}, {30, 5, 400}}
+	for i, ch := range chList {
+		assert.Equal(t, addr1, ch.Address)
+		assert.Equal(t, expected[i].blockHeight, ch.BlockHeight)
+		assert.Equal(t, common.Uint64ToBytes(expected[i].blockHeight), ch.BlockHash[:len(common.Uint64ToBytes(expected[i].blockHeight))])
+		assert.Equal(t, expected[i].txIndex, ch.TXIndex)
+		assert.Equal(t, expected[i].iScore, ch.IScore.Uint64())
+	}
+
+	// block range
+	chList, err = ReadClaimHistory(chDB, addr1, 15, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(chList))
+	assert.Equal(t, uint64(20), chList[0].BlockHeight)
+	chList, err = ReadClaimHistory(chDB, addr1, 10, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(chList))
+	assert.Equal(t, uint64(20), chList[1].BlockHeight)
+
+	// QUERY_CLAIM_HISTORY
+	resp := DoQueryClaimHistory(ctx, &QueryClaimHistoryRequest{Address: addr2})
+	assert.Equal(t, ClaimHistoryStatusOK, resp.Status)
+	assert.Equal(t, 1, len(resp.Claims))
+	assert.Equal(t, uint64(10), resp.Claims[0].BlockHeight)
+	assert.Equal(t, uint64(2), resp.Claims[0].TXIndex)
+	assert.Equal(t, uint64(200), resp.Claims[0].IScore.Uint64())
+
+	// rollback claim DB deletes claims above rollback block height
+	assert.NoError(t, rollbackClaimDB(ctx, 15, common.Uint64ToBytes(15)))
+	chList, err = ReadClaimHistory(chDB, addr1, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(chList))
+	assert.Equal(t, uint64(10), chList[0].BlockHeight)
+	chList, err = ReadClaimHistory(chDB, addr2, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(chList))
+}
+
+func getClaimFromClaimDB(ctx *Context, address common.Address) (*Claim, error) {
+	bucket, _ := ctx.DB.getClaimDB().GetBucket("")
+	bs, err := bucket.Get(address.Bytes())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	return NewClaimFromBytes(bs)
+}
+
+// claimHistoryTestState returns claims and claim history of addresses
+func claimHistoryTestState(t *testing.T, ctx *Context, addresses []common.Address) []string {
+	state := make([]string, 0)
+	for _, address := range addresses {
+		claim, err := getClaimFromClaimDB(ctx, address)
+		assert.NoError(t, err)
+		state = append(state, claim.String())
+
+		chList, err := ReadClaimHistory(ctx.DB.getClaimHistoryDB(), address, 0, 0)
+		assert.NoError(t, err)
+		for _, ch := range chList {
+			state = append(state, ch.String())
+		}
+	}
+	return state
+}
+
+func TestDBClaimHistory_CrashInCommitBlock(t *testing.T) {
+	const blockHeight = 20
+	commit := func(ctx *Context) {
+		commitTestBlock(ctx, blockHeight)
+	}
+	if runJournalTestChild(t, commit) {
+		return
+	}
+	addresses := []common.Address{*common.NewAddressFromString("hx11"), *common.NewAddressFromString("hx22")}
+	ctx := initTest(1)
+	commitTestClaim(t, ctx, addresses[0], 10, 0, 100)
+	preCommitTestClaim(t, ctx, addresses[0], blockHeight, 0, 200)
+	preCommitTestClaim(t, ctx, addresses[1], blockHeight, 1, 300)
+	CloseIScoreDB(ctx.DB)
+	template := testDir
+	defer os.RemoveAll(template)
+
+	// commit block without crash
+	ctx = openJournalTest(t, template)
+	restore := countCrashPoints()
+	assert.NoError(t, commitTestBlock(ctx, blockHeight))
+	steps := restore()
+	expected := claimHistoryTestState(t, ctx, addresses)
+	finalizeTest(ctx)
+	assert.Equal(t, 2+3, len(expected))
+
+	// claim history has all claims after COMMIT_BLOCK is sent again
+	assert.Equal(t, 1+len(addresses), steps)
+	for step := 1; step <= steps; step++ {
+		copyJournalTest(t, template)
+		assert.True(t, crashJournalTest(t, step), "crash at step %d", step)
+
+		ctx = restartJournalTest(t)
+		assert.NoError(t, commitTestBlock(ctx, blockHeight))
+		assert.Equal(t, expected, claimHistoryTestState(t, ctx, addresses), "crash at step %d", step)
+		finalizeTest(ctx)
+	}
+}
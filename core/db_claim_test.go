@@ -304,7 +304,7 @@ func TestDBPreCommit_manage(t *testing.T) {
 	// write to claim DB with commit
 	cDB := ctx.DB.getClaimDB()
 	assert.NoError(t, writePreCommitToClaimDB(pcDB, cDB, ctx.DB.getClaimBackupDB(),
-		tests[0].blockHeight, tests[0].hash))
+		ctx.DB.getClaimHistoryDB(), ctx.DB.getClaimBackupPeriod(), tests[0].blockHeight, tests[0].hash))
 
 	// can't query commited preCommit data
 	pc := newPreCommit(tests[0].blockHeight, tests[0].hash, tests[0].txIndex, tests[0].hash, *tests[0].address)
@@ -477,7 +477,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 
 	cbDB := ctx.DB.getClaimBackupDB()
 
-	err := writeClaimBackupInfo(cbDB, blockHeight)
+	err := writeClaimBackupInfo(cbDB, blockHeight, DefaultClaimBackupPeriod)
 	assert.NoError(t, err)
 
 	var cbInfo ClaimBackupInfo
@@ -491,7 +491,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	assert.Equal(t, blockHeight, cbInfo.LastBlockHeight)
 
 	// write invalid blockHeight
-	err = writeClaimBackupInfo(cbDB, blockHeight - 10)
+	err = writeClaimBackupInfo(cbDB, blockHeight - 10, DefaultClaimBackupPeriod)
 	assert.NoError(t, err)
 	bs, err = cbBucket.Get(cbInfo.ID())
 	assert.NotNil(t, bs)
@@ -502,7 +502,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	assert.Equal(t, blockHeight, cbInfo.LastBlockHeight)
 
 	// write valid blockHeight
-	err = writeClaimBackupInfo(cbDB, blockHeight + 1)
+	err = writeClaimBackupInfo(cbDB, blockHeight + 1, DefaultClaimBackupPeriod)
 	assert.NoError(t, err)
 	bs, err = cbBucket.Get(cbInfo.ID())
 	assert.NotNil(t, bs)
@@ -513,7 +513,7 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	assert.Equal(t, blockHeight + 1, cbInfo.LastBlockHeight)
 
 	// write valid blockHeight
-	err = writeClaimBackupInfo(cbDB, blockHeight + ClaimBackupPeriod + 1)
+	err = writeClaimBackupInfo(cbDB, blockHeight + DefaultClaimBackupPeriod + 1, DefaultClaimBackupPeriod)
 	assert.NoError(t, err)
 	bs, err = cbBucket.Get(cbInfo.ID())
 	assert.NotNil(t, bs)
@@ -521,7 +521,19 @@ func Test_writeClaimBackupInfo(t *testing.T) {
 	err = cbInfo.SetBytes(bs)
 	assert.NoError(t, err)
 	assert.Equal(t, blockHeight + 1, cbInfo.FirstBlockHeight)
-	assert.Equal(t, blockHeight + ClaimBackupPeriod + 1, cbInfo.LastBlockHeight)
+	assert.Equal(t, blockHeight + DefaultClaimBackupPeriod + 1, cbInfo.LastBlockHeight)
+
+	// write with short backup period
+	const period uint64 = 10
+	lastBlockHeight := blockHeight + DefaultClaimBackupPeriod + 1 + period
+	err = writeClaimBackupInfo(cbDB, lastBlockHeight, period)
+	assert.NoError(t, err)
+	bs, err = cbBucket.Get(cbInfo.ID())
+	assert.NoError(t, err)
+	err = cbInfo.SetBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, lastBlockHeight - period, cbInfo.FirstBlockHeight)
+	assert.Equal(t, lastBlockHeight, cbInfo.LastBlockHeight)
 }
 
 func Test_garbageCollectClaimBackupDB(t *testing.T) {
@@ -258,10 +258,10 @@ func (tx *IISSTX) SetBytes(bs []byte) error {
 	return nil
 }
 
-func OpenIISSData(path string) db.Database {
+func OpenIISSData(path string, dbType string) db.Database {
 	dbPath := filepath.Clean(path)
 	dbDir, dbName := filepath.Split(dbPath)
-	return db.Open(dbDir, string(db.GoLevelDBBackend), dbName)
+	return db.Open(dbDir, dbType, dbName)
 }
 
 func LoadIISSData(iissDB db.Database) (*IISSHeader, []*IISSGovernanceVariable, []*PRep) {
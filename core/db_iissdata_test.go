@@ -431,4 +431,87 @@ func TestDBIISS_manageIISSData(t *testing.T) {
 	assert.True(t, f.IsDir())
 
 	os.RemoveAll(rootPath)
-}
\ No newline at end of file
+}
+func TestDBIISSData_ValidateIISSData(t *testing.T) {
+	const (
+		calcDoneBH = uint64(100)
+		termBH     = uint64(200)
+	)
+	_, iissDB := writeHeader(testDBDir, testDB, termBH)
+	defer os.RemoveAll(testDBDir)
+	defer iissDB.Close()
+
+	prepA := &PRepDelegationInfo{Address: *common.NewAddressFromString("hxaa")}
+	prepA.DelegatedAmount.SetUint64(10)
+	prepB := &PRepDelegationInfo{Address: *common.NewAddressFromString("hxbb")}
+	prepB.DelegatedAmount.SetUint64(20)
+
+	// valid IISS data
+	WriteIISSGV(iissDB, calcDoneBH+1, gvIncentiveRep, gvRewardRep, NumMainPRep, NumSubPRep)
+	WriteIISSPRep(iissDB, calcDoneBH+1, 30, []*PRepDelegationInfo{prepA, prepB})
+	WriteIISSBP(iissDB, calcDoneBH+1, "hxaa", []string{"hxbb"})
+	WriteIISSTX(iissDB, 0, "hx11", calcDoneBH+1, TXDataTypeDelegate, []*PRepDelegationInfo{prepA})
+	WriteIISSTX(iissDB, 1, "hx11", termBH, TXDataTypeDelegate, nil)
+	WriteIISSTX(iissDB, 2, "hxaa", termBH, TXDataTypePrepReg, nil)
+	assert.Nil(t, ValidateIISSData(iissDB, calcDoneBH))
+
+	// P-Rep list with invalid TotalDelegation
+	WriteIISSPRep(iissDB, calcDoneBH+2, 100, []*PRepDelegationInfo{prepA, prepB})
+	// BP with contract generator
+	WriteIISSBP(iissDB, calcDoneBH+2, "cxaa", []string{"hxbb"})
+	// TX out of term
+	WriteIISSTX(iissDB, 3, "hx11", calcDoneBH, TXDataTypeDelegate, nil)
+	// TX with unknown data type
+	WriteIISSTX(iissDB, 4, "hx11", termBH, 3, nil)
+	// TX with invalid delegation
+	tx := makeIISSTX(TXDataTypeDelegate, "hx11", nil)
+	tx.Index = 5
+	tx.BlockHeight = termBH
+	tx.Data, _ = common.EncodeAny(uint64(1))
+	writeTX(iissDB, []*IISSTX{tx})
+
+	problems := ValidateIISSData(iissDB, calcDoneBH)
+	assert.Equal(t, 5, len(problems))
+	expected := []IISSDataProblem{
+		{Record: IISSDataRecordPRep, Key: calcDoneBH + 2},
+		{Record: IISSDataRecordBP, Key: calcDoneBH + 2},
+		{Record: IISSDataRecordTX, Key: 3},
+		{Record: IISSDataRecordTX, Key: 4},
+		{Record: IISSDataRecordTX, Key: 5},
+	}
+	for i, p := range problems {
+		assert.Equal(t, expected[i].Record, p.Record, p.String())
+		assert.Equal(t, expected[i].Key, p.Key, p.String())
+	}
+
+	// unsupported header version
+	bucket, _ := iissDB.GetBucket(db.PrefixIISSHeader)
+	header := makeHeader(termBH)
+	header.Version = IISSDataVersion + 1
+	bs, _ := header.Bytes()
+	bucket.Set(header.ID(), bs)
+	problems = ValidateIISSData(iissDB, calcDoneBH)
+	assert.Equal(t, 6, len(problems))
+	assert.Equal(t, IISSDataRecordHeader, problems[0].Record)
+}
+
+func TestDBIISSData_ValidateIISSData_FirstTerm(t *testing.T) {
+	const termBH = uint64(200)
+	_, iissDB := writeHeader(testDBDir, testDB, termBH)
+	defer os.RemoveAll(testDBDir)
+	defer iissDB.Close()
+
+	// first term has data of genesis block
+	WriteIISSGV(iissDB, 0, gvIncentiveRep, gvRewardRep, NumMainPRep, NumSubPRep)
+	WriteIISSBP(iissDB, 0, "hxaa", []string{"hxbb"})
+	WriteIISSTX(iissDB, 0, "hxaa", 0, TXDataTypePrepReg, nil)
+	WriteIISSTX(iissDB, 1, "hx11", termBH, TXDataTypeDelegate, nil)
+	assert.Nil(t, ValidateIISSData(iissDB, 0))
+
+	// TX out of term
+	WriteIISSTX(iissDB, 2, "hx11", termBH+1, TXDataTypeDelegate, nil)
+	problems := ValidateIISSData(iissDB, 0)
+	assert.Equal(t, 1, len(problems))
+	assert.Equal(t, IISSDataRecordTX, problems[0].Record)
+	assert.Equal(t, uint64(2), problems[0].Key)
+}
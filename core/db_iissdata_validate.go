@@ -0,0 +1,256 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// record types of IISSDataProblem
+const (
+	IISSDataRecordHeader = "header"
+	IISSDataRecordGV     = "GV"
+	IISSDataRecordBP     = "BP"
+	IISSDataRecordPRep   = "P-Rep"
+	IISSDataRecordTX     = "TX"
+)
+
+// IISSDataProblem is a problem found in a record of IISS data
+type IISSDataProblem struct {
+	Record string
+	// block height of header, GV, BP and P-Rep list or index of TX
+	Key    uint64
+	Reason string
+}
+
+func (p *IISSDataProblem) String() string {
+	return fmt.Sprintf("%s %d: %s", p.Record, p.Key, p.Reason)
+}
+
+// IISSDataError is returned when IISS data has problems
+type IISSDataError struct {
+	Problems []*IISSDataProblem
+}
+
+func (e *IISSDataError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return fmt.Sprintf("invalid IISS data. %s", strings.Join(problems, ", "))
+}
+
+type iissDataValidator struct {
+	calcDoneBH  uint64
+	blockHeight uint64
+	problems    []*IISSDataProblem
+}
+
+func (v *iissDataValidator) add(record string, key uint64, format string, a ...interface{}) {
+	v.problems = append(v.problems, &IISSDataProblem{Record: record, Key: key, Reason: fmt.Sprintf(format, a...)})
+}
+
+// inTerm checks blockHeight is in calculation term (calcDoneBH, blockHeight].
+// The first term starts with genesis block, so it is [0, blockHeight]
+func (v *iissDataValidator) inTerm(record string, key uint64, blockHeight uint64) {
+	if (blockHeight <= v.calcDoneBH && v.calcDoneBH != 0) || blockHeight > v.blockHeight {
+		v.add(record, key, "block height %d is out of term (%d, %d]", blockHeight, v.calcDoneBH, v.blockHeight)
+	}
+}
+
+// address checks address of P-Rep and block producer is not empty and not contract
+func (v *iissDataValidator) address(record string, key uint64, name string, address *common.Address) {
+	if *address == (common.Address{}) {
+		v.add(record, key, "empty %s address", name)
+	} else if address.IsContract() {
+		v.add(record, key, "%s address %s is contract", name, address.String())
+	}
+}
+
+// ValidateIISSData checks all records of IISS data calculated after calcDoneBH.
+// It returns problems found in IISS data and nil if IISS data is valid
+func ValidateIISSData(iissDB db.Database, calcDoneBH uint64) []*IISSDataProblem {
+	v := &iissDataValidator{calcDoneBH: calcDoneBH}
+
+	header, err := loadIISSHeader(iissDB)
+	if err != nil {
+		v.add(IISSDataRecordHeader, 0, "can't load header. %v", err)
+		return v.problems
+	}
+	v.blockHeight = header.BlockHeight
+	if v.blockHeight == 0 {
+		v.blockHeight = calcDoneBH + 1
+	}
+	v.validateHeader(header)
+
+	records := []struct {
+		prefix   db.BucketID
+		validate func(key []byte, value []byte, prev *uint64)
+	}{
+		{db.PrefixIISSGV, func(key []byte, value []byte, prev *uint64) {
+			v.validateGV(key, value, header.Version, prev)
+		}},
+		{db.PrefixIISSPRep, v.validatePRep},
+		{db.PrefixIISSBPInfo, v.validateBP},
+		{db.PrefixIISSTX, v.validateTX},
+	}
+	for _, r := range records {
+		iter, err := iissDB.GetIterator()
+		if err != nil {
+			v.add(IISSDataRecordHeader, header.BlockHeight, "can't iterate IISS data. %v", err)
+			return v.problems
+		}
+		var prev *uint64
+		prefix := util.BytesPrefix([]byte(r.prefix))
+		iter.New(prefix.Start, prefix.Limit)
+		for iter.Next() {
+			r.validate(iter.Key()[len(r.prefix):], iter.Value(), prev)
+			key := common.BytesToUint64(iter.Key()[len(r.prefix):])
+			prev = &key
+		}
+		iter.Release()
+		if err = iter.Error(); err != nil {
+			v.add(IISSDataRecordHeader, header.BlockHeight, "error while iterate %s. %v", r.prefix, err)
+		}
+	}
+
+	return v.problems
+}
+
+func (v *iissDataValidator) validateHeader(header *IISSHeader) {
+	if header.Version == 0 || header.Version > IISSDataVersion {
+		v.add(IISSDataRecordHeader, header.BlockHeight, "unsupported version %d", header.Version)
+	}
+	if header.Revision > RevisionMax {
+		v.add(IISSDataRecordHeader, header.BlockHeight, "unsupported revision %d", header.Revision)
+	}
+	if header.BlockHeight != 0 && header.BlockHeight <= v.calcDoneBH {
+		v.add(IISSDataRecordHeader, header.BlockHeight, "block height is not higher than calculation %d",
+			v.calcDoneBH)
+	}
+}
+
+func (v *iissDataValidator) validateGV(key []byte, value []byte, version uint64, prev *uint64) {
+	gv := new(IISSGovernanceVariable)
+	gv.BlockHeight = common.BytesToUint64(key)
+	if err := gv.SetBytes(value, version); err != nil {
+		v.add(IISSDataRecordGV, gv.BlockHeight, "can't decode. %v", err)
+		return
+	}
+	if prev != nil && gv.BlockHeight <= *prev {
+		v.add(IISSDataRecordGV, gv.BlockHeight, "block height is not higher than previous GV %d", *prev)
+	}
+	if gv.BlockHeight > v.blockHeight {
+		v.add(IISSDataRecordGV, gv.BlockHeight, "block height is higher than term end %d", v.blockHeight)
+	}
+}
+
+func (v *iissDataValidator) validatePRep(key []byte, value []byte, prev *uint64) {
+	pRep := new(PRep)
+	pRep.BlockHeight = common.BytesToUint64(key)
+	if err := pRep.SetBytes(value); err != nil {
+		v.add(IISSDataRecordPRep, pRep.BlockHeight, "can't decode. %v", err)
+		return
+	}
+	if prev != nil && pRep.BlockHeight <= *prev {
+		v.add(IISSDataRecordPRep, pRep.BlockHeight, "block height is not higher than previous P-Rep list %d",
+			*prev)
+	}
+	if pRep.BlockHeight > v.blockHeight {
+		v.add(IISSDataRecordPRep, pRep.BlockHeight, "block height is higher than term end %d", v.blockHeight)
+	}
+
+	var total big.Int
+	for i := range pRep.List {
+		dg := &pRep.List[i]
+		v.address(IISSDataRecordPRep, pRep.BlockHeight, "P-Rep", &dg.Address)
+		if dg.DelegatedAmount.Sign() < 0 {
+			v.add(IISSDataRecordPRep, pRep.BlockHeight, "negative delegation %s of %s",
+				dg.DelegatedAmount.String(), dg.Address.String())
+		}
+		total.Add(&total, &dg.DelegatedAmount.Int)
+	}
+	if total.Cmp(&pRep.TotalDelegation.Int) != 0 {
+		v.add(IISSDataRecordPRep, pRep.BlockHeight, "sum of delegations %s is not TotalDelegation %s",
+			total.String(), pRep.TotalDelegation.String())
+	}
+}
+
+func (v *iissDataValidator) validateBP(key []byte, value []byte, _ *uint64) {
+	bp := new(IISSBlockProduceInfo)
+	bp.BlockHeight = common.BytesToUint64(key)
+	if err := bp.SetBytes(value); err != nil {
+		v.add(IISSDataRecordBP, bp.BlockHeight, "can't decode. %v", err)
+		return
+	}
+	v.inTerm(IISSDataRecordBP, bp.BlockHeight, bp.BlockHeight)
+	v.address(IISSDataRecordBP, bp.BlockHeight, "generator", &bp.Generator)
+	for i := range bp.Validator {
+		v.address(IISSDataRecordBP, bp.BlockHeight, "validator", &bp.Validator[i])
+	}
+}
+
+func (v *iissDataValidator) validateTX(key []byte, value []byte, _ *uint64) {
+	tx := new(IISSTX)
+	tx.Index = common.BytesToUint64(key)
+	if err := tx.SetBytes(value); err != nil {
+		v.add(IISSDataRecordTX, tx.Index, "can't decode. %v", err)
+		return
+	}
+	v.inTerm(IISSDataRecordTX, tx.Index, tx.BlockHeight)
+	if tx.Address == (common.Address{}) {
+		v.add(IISSDataRecordTX, tx.Index, "empty TX address")
+	}
+
+	switch tx.DataType {
+	case TXDataTypeDelegate:
+		if err := validateDelegationData(tx.Data); err != nil {
+			v.add(IISSDataRecordTX, tx.Index, "invalid delegation. %v", err)
+		}
+	case TXDataTypePrepReg:
+	case TXDataTypePrepUnReg:
+	default:
+		v.add(IISSDataRecordTX, tx.Index, "unknown data type %d", tx.DataType)
+	}
+}
+
+// validateDelegationData checks delegation of IISS TX is list of address and amount pair.
+// Data with nil type means deleting all delegations
+func validateDelegationData(data *codec.TypedObj) error {
+	if data == nil {
+		return fmt.Errorf("no data")
+	}
+	if data.Type == codec.TypeNil {
+		return nil
+	}
+	obj, err := common.DecodeAny(data)
+	if err != nil {
+		return err
+	}
+	delegations, ok := obj.([]interface{})
+	if !ok {
+		return fmt.Errorf("delegation is not list")
+	}
+	for i, d := range delegations {
+		dg, ok := d.([]interface{})
+		if !ok || len(dg) != 2 {
+			return fmt.Errorf("delegation %d is not address and amount pair", i)
+		}
+		if _, ok = dg[0].(*common.Address); !ok {
+			return fmt.Errorf("delegation %d has invalid address", i)
+		}
+		amount, ok := dg[1].(*common.HexInt)
+		if !ok {
+			return fmt.Errorf("delegation %d has invalid amount", i)
+		}
+		if amount.Sign() < 0 {
+			return fmt.Errorf("delegation %d has negative amount %s", i, amount.String())
+		}
+	}
+	return nil
+}
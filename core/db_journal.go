@@ -0,0 +1,326 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// operations recorded in journal
+const (
+	JournalOpCalculate uint64 = 1
+	JournalOpRollback  uint64 = 2
+)
+
+// phases of calculation. Interrupted calculation is rolled back before JournalCalcCommit and rolled forward after
+const (
+	JournalCalcToggle uint64 = 1 // account DB is being toggled
+	JournalCalcReset  uint64 = 2 // old query DB is being renamed to backup account DB
+	JournalCalcTerm   uint64 = 3 // GV, P-Rep list, P-Rep candidates and accounts are being calculated
+	JournalCalcCommit uint64 = 4 // calculation block height is being set and CALCULATE_DONE is being written to outbox
+)
+
+// phases of rollback. Interrupted rollback is rolled forward from the phase
+const (
+	JournalRollbackClaim      uint64 = 1 // claim DB is being rolled back
+	JournalRollbackAccount    uint64 = 2 // account DB is being rolled back with AccountRollback
+	JournalRollbackManagement uint64 = 3 // GV, P-Rep list, P-Rep candidates, rewards and outbox are being rolled back
+)
+
+// AccountRestore restores backup account DB made at BlockHeight to account DB with PostFix
+type AccountRestore struct {
+	BlockHeight uint64
+	PostFix     int
+}
+
+// AccountRollback is a plan to roll back account DB. Each step can be applied again after crash
+type AccountRollback struct {
+	Restores []AccountRestore
+	// backup account DB generations of rolled back calculations
+	Deletes []uint64
+	// calculation results of rolled back calculations
+	Results []uint64
+
+	// DB info after rollback
+	QueryDBIsZero bool
+	ToggleBH      uint64
+	CalcDone      uint64
+	PrevCalcDone  uint64
+}
+
+type JournalData struct {
+	Phase uint64
+	// calculation or rollback block height
+	BlockHeight uint64
+	// rollback block hash
+	BlockHash []byte
+
+	// DB info before calculation
+	QueryDBIsZero bool
+	ToggleBH      uint64
+	CalcDone      uint64
+	PrevCalcDone  uint64
+
+	// valid from JournalRollbackAccount
+	Account AccountRollback
+}
+
+// Journal is a write-ahead record of calculation or rollback in progress.
+// Startup recovers I-Score DB with the journal left by crash
+type Journal struct {
+	Op uint64
+	JournalData
+}
+
+func (j *Journal) ID() []byte {
+	return []byte{byte(j.Op)}
+}
+
+func (j *Journal) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&j.JournalData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (j *Journal) String() string {
+	b, err := json.Marshal(j)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (j *Journal) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &j.JournalData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// crashPoint is called after each step of calculation and rollback which changes I-Score DB.
+// It is called by goroutines writing account DBs in parallel too. Tests replace it to crash at the step
+var crashPoint = func() {}
+
+func writeJournal(mngDB db.Database, j *Journal) error {
+	bucket, _ := mngDB.GetBucket(db.PrefixJournal)
+	bs, err := j.Bytes()
+	if err != nil {
+		return err
+	}
+	if err = bucket.Set(j.ID(), bs); err != nil {
+		return err
+	}
+	crashPoint()
+	return nil
+}
+
+// setJournalPhase writes journal with phase before the phase starts
+func setJournalPhase(mngDB db.Database, j *Journal, phase uint64) {
+	j.Phase = phase
+	if err := writeJournal(mngDB, j); err != nil {
+		log.Printf("Failed to write journal. %s. %v", j.String(), err)
+	}
+}
+
+// readJournal returns journal of operation op and nil if there is no operation in progress
+func readJournal(mngDB db.Database, op uint64) (*Journal, error) {
+	j := &Journal{Op: op}
+	bucket, _ := mngDB.GetBucket(db.PrefixJournal)
+	bs, err := bucket.Get(j.ID())
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	if err = j.SetBytes(bs); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func deleteJournal(mngDB db.Database, op uint64) error {
+	j := &Journal{Op: op}
+	bucket, _ := mngDB.GetBucket(db.PrefixJournal)
+	if err := bucket.Delete(j.ID()); err != nil {
+		return err
+	}
+	crashPoint()
+	return nil
+}
+
+// hasJournal checks there is calculation or rollback to recover
+func hasJournal(mngDB db.Database) bool {
+	bucket, _ := mngDB.GetBucket(db.PrefixJournal)
+	for _, op := range []uint64{JournalOpCalculate, JournalOpRollback} {
+		if bucket.Has((&Journal{Op: op}).ID()) {
+			return true
+		}
+	}
+	return false
+}
+
+// beginCalculation writes journal of calculation at blockHeight with DB info before account DB is toggled
+func (idb *IScoreDB) beginCalculation(blockHeight uint64) *Journal {
+	j := &Journal{Op: JournalOpCalculate}
+	j.BlockHeight = blockHeight
+	j.QueryDBIsZero = idb.info.QueryDBIsZero
+	j.ToggleBH = idb.info.ToggleBH
+	j.CalcDone = idb.info.CalcDone
+	j.PrevCalcDone = idb.info.PrevCalcDone
+	setJournalPhase(idb.management, j, JournalCalcToggle)
+	return j
+}
+
+// recoverJournal rolls back or rolls forward calculation and rollback interrupted by crash.
+// Calculation rolled back is calculated again with IISS data by reloadIISSData
+func recoverJournal(ctx *Context) error {
+	mngDB := ctx.DB.management
+	j, err := readJournal(mngDB, JournalOpRollback)
+	if err != nil {
+		return err
+	}
+	if j != nil {
+		log.Printf("Recover interrupted rollback. %s", j.String())
+		if err = ctx.rollForwardRollback(j); err != nil {
+			return err
+		}
+	}
+
+	j, err = readJournal(mngDB, JournalOpCalculate)
+	if err != nil {
+		return err
+	}
+	if j != nil {
+		log.Printf("Recover interrupted calculation. %s", j.String())
+		if err = ctx.recoverCalculation(j, j.BlockHeight); err != nil {
+			return err
+		}
+	}
+
+	// crash after commit may leave old backup account DBs
+	return ctx.DB.pruneCommittedAccountBackups(ctx.DB.getCalcDoneBH())
+}
+
+// recoverCalculation rolls forward calculation which wrote calculation result and rolls back the others.
+// Calculating block height is set with calculating after rollback
+func (ctx *Context) recoverCalculation(j *Journal, calculating uint64) error {
+	idb := ctx.DB
+	if j.Phase == JournalCalcCommit {
+		idb.info.CalcDone = j.BlockHeight
+		idb.info.PrevCalcDone = j.CalcDone
+		idb.info.Calculating = j.BlockHeight
+		idb.writeToDB()
+		log.Printf("Roll forward calculation %d", j.BlockHeight)
+
+		// CALCULATE_DONE was not sent before crash
+		if err := writeCalculateDoneOfResult(idb.getCalculateResultDB(), j.BlockHeight); err != nil {
+			log.Printf("Failed to write CALCULATE_DONE to outbox. %v", err)
+		}
+		return deleteJournal(idb.management, JournalOpCalculate)
+	}
+
+	if j.Phase >= JournalCalcTerm {
+		ctx.RollbackManagementDB(j.CalcDone)
+		if err := idb.rollbackRewardBreakdown(j.CalcDone); err != nil {
+			return err
+		}
+		if err := idb.rollbackPRepReport(j.CalcDone); err != nil {
+			return err
+		}
+		DeleteCalculationResult(idb.getCalculateResultDB(), j.BlockHeight)
+	}
+
+	if j.Phase >= JournalCalcReset {
+		// old query DB is the calculate DB of toggled account DB
+		backups, err := filepath.Glob(idb.accountBackupPattern(j.BlockHeight))
+		if err != nil {
+			return err
+		}
+		postFix := 1
+		if j.QueryDBIsZero {
+			postFix = 0
+		}
+		if err = idb.restoreAccountDB(backups, postFix); err != nil {
+			return err
+		}
+		if err = deleteAccountBackup(idb.management, j.BlockHeight); err != nil {
+			return err
+		}
+	}
+
+	idb.setAccountDBToggle(j.QueryDBIsZero, j.ToggleBH)
+	idb.info.CalcDone = j.CalcDone
+	idb.info.PrevCalcDone = j.PrevCalcDone
+	idb.info.Calculating = calculating
+	idb.writeToDB()
+	log.Printf("Roll back calculation %d to %d", j.BlockHeight, j.CalcDone)
+
+	return deleteJournal(idb.management, JournalOpCalculate)
+}
+
+// rollForwardRollback rolls back I-Score DB from the phase of interrupted rollback
+func (ctx *Context) rollForwardRollback(j *Journal) error {
+	switch j.Phase {
+	case JournalRollbackClaim:
+		return ctx.rollbackFromClaim(j)
+	case JournalRollbackAccount:
+		return ctx.rollbackFromAccount(j)
+	case JournalRollbackManagement:
+		return ctx.rollbackFromManagement(j)
+	default:
+		return fmt.Errorf("invalid rollback phase %d", j.Phase)
+	}
+}
+
+// setCalculationPhase writes phase of calculation in progress to journal. Simulation has no journal
+func (ctx *Context) setCalculationPhase(phase uint64) {
+	if ctx.journal != nil {
+		setJournalPhase(ctx.DB.management, ctx.journal, phase)
+	}
+}
+
+// endCalculation writes CALCULATE_DONE of calculation committed at blockHeight to outbox and deletes journal.
+// CALCULATE_DONE must be in outbox before journal is deleted, because commit is not rolled forward after it
+func (ctx *Context) endCalculation(blockHeight uint64) {
+	if ctx.journal == nil {
+		return
+	}
+	idb := ctx.DB
+	if err := writeCalculateDoneOfResult(idb.getCalculateResultDB(), blockHeight); err != nil {
+		log.Printf("Failed to write CALCULATE_DONE to outbox. %v", err)
+	}
+	crashPoint()
+
+	if err := deleteJournal(idb.management, JournalOpCalculate); err != nil {
+		log.Printf("Failed to delete journal. %v", err)
+	}
+	ctx.journal = nil
+
+	if err := idb.pruneCommittedAccountBackups(blockHeight); err != nil {
+		log.Printf("Failed to delete old backup account DB. %v", err)
+	}
+}
+
+// writeCalculateDoneOfResult writes CALCULATE_DONE with calculation result of blockHeight to outbox
+func writeCalculateDoneOfResult(crDB db.Database, blockHeight uint64) error {
+	cr := &CalculationResult{BlockHeight: blockHeight}
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+	bs, err := bucket.Get(cr.ID())
+	if err != nil || bs == nil {
+		return err
+	}
+	if err = cr.SetBytes(bs); err != nil {
+		return err
+	}
+
+	done := &CalculateDone{Success: cr.Success, BlockHeight: blockHeight, StateHash: cr.StateHash}
+	done.IScore.Set(&cr.IScore.Int)
+	return WriteCalculateDoneOutbox(crDB, done)
+}
@@ -0,0 +1,414 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+var journalTestAddresses = []string{"hx11", "hxaa"}
+
+// environment variables of child process which crashes in journal test
+const (
+	journalTestCrashEnv   = "RC_JOURNAL_TEST_CRASH"
+	journalTestDirEnv     = "RC_JOURNAL_TEST_DIR"
+	journalTestBackendEnv = "RC_JOURNAL_TEST_BACKEND"
+
+	journalTestCrashCode = 3
+)
+
+// countCrashPoints makes crashPoint count calls.
+// The returned function restores crashPoint and returns the number of calls
+func countCrashPoints() func() int {
+	var count int32
+	crashPoint = func() {
+		atomic.AddInt32(&count, 1)
+	}
+	return func() int {
+		crashPoint = func() {}
+		return int(atomic.LoadInt32(&count))
+	}
+}
+
+// crashJournalTest runs the test in child process with I-Score DB in testDir and returns true if the child
+// exited at step-th call of crashPoint.
+// Child process exits without deferred calls, so calcLock, IISS data and I-Score DB are left like killed process
+// and goroutines writing account DBs stop at any step. Panic can't do both
+func crashJournalTest(t *testing.T, step int) bool {
+	cmd := exec.Command(os.Args[0], "-test.run=^"+t.Name()+"$")
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%d", journalTestCrashEnv, step),
+		journalTestDirEnv+"="+testDir,
+		journalTestBackendEnv+"="+testDBBackend)
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode() == journalTestCrashCode
+	}
+	assert.NoError(t, err)
+	return false
+}
+
+// runJournalTestChild runs f in child process of crashJournalTest and returns true.
+// It returns false in test process
+func runJournalTestChild(t *testing.T, f func(ctx *Context)) bool {
+	env := os.Getenv(journalTestCrashEnv)
+	if env == "" {
+		return false
+	}
+	if os.Getenv(journalTestBackendEnv) != testDBBackend {
+		return true
+	}
+	step, err := strconv.Atoi(env)
+	assert.NoError(t, err)
+
+	testDir = os.Getenv(journalTestDirEnv)
+	ctx, err := NewContext(testDir, testDBBackend, "test", 0, "")
+	assert.NoError(t, err)
+	initJournalTestConfig(ctx)
+
+	var count int32
+	crashPoint = func() {
+		if int(atomic.AddInt32(&count, 1)) == step {
+			os.Exit(journalTestCrashCode)
+		}
+	}
+	f(ctx)
+	crashPoint = func() {}
+	CloseIScoreDB(ctx.DB)
+	return true
+}
+
+// restartJournalTest opens I-Score DB left by crashed child process with recovery on startup
+func restartJournalTest(t *testing.T) *Context {
+	ctx, err := NewContext(testDir, testDBBackend, "test", 0, "")
+	assert.NoError(t, err)
+	initJournalTestConfig(ctx)
+	assert.NoError(t, recoverJournal(ctx))
+	return ctx
+}
+
+func initJournalTestConfig(ctx *Context) {
+	ctx.DB.SetAccountDBBackups(3)
+	ctx.DB.SetRewardBreakdowns(3)
+	ctx.DB.SetPRepReports(3)
+}
+
+func journalTestIISSPath(blockHeight uint64) string {
+	return filepath.Join(testDir, "iiss", fmt.Sprintf("iiss_%d", blockHeight))
+}
+
+// writeJournalTestIISSData writes IISS data of calculation at blockHeight with GV, P-Rep list,
+// P-Rep register TX and delegation TX
+func writeJournalTestIISSData(blockHeight uint64) string {
+	iissDir, name := filepath.Split(journalTestIISSPath(blockHeight))
+	_, iissDB := writeHeader(iissDir, name, blockHeight)
+	defer iissDB.Close()
+
+	gv := makeIISSGV()
+	gv.BlockHeight = blockHeight - 5
+	bucket, _ := iissDB.GetBucket(db.PrefixIISSGV)
+	bs, _ := gv.Bytes()
+	bucket.Set(gv.ID(), bs)
+
+	pRep := makePRep()
+	pRep.BlockHeight = blockHeight - 5
+	bucket, _ = iissDB.GetBucket(db.PrefixIISSPRep)
+	bs, _ = pRep.Bytes()
+	bucket.Set(pRep.ID(), bs)
+
+	delegation := makeIISSTX(TXDataTypeDelegate, "hx11", []DelegateData{
+		{*common.NewAddressFromString("hxaa"), *common.NewHexIntFromUint64(MinDelegation * blockHeight)},
+	})
+	delegation.Index = 1
+	delegation.BlockHeight = blockHeight - 3
+	writeTX(iissDB, []*IISSTX{
+		makePRepCandidateTX(TXDataTypePrepReg, "hxaa", 0, blockHeight-4),
+		delegation,
+	})
+
+	return journalTestIISSPath(blockHeight)
+}
+
+func calculateJournalTest(t *testing.T, ctx *Context, blockHeight uint64) {
+	req := &CalculateRequest{Path: writeJournalTestIISSData(blockHeight), BlockHeight: blockHeight}
+	err, _, _, _ := DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, req, nil, 0)
+	assert.NoError(t, err)
+}
+
+func initJournalTest(t *testing.T, calcBHs ...uint64) *Context {
+	ctx := initTest(2)
+	initJournalTestConfig(ctx)
+	for _, bh := range calcBHs {
+		calculateJournalTest(t, ctx, bh)
+	}
+	return ctx
+}
+
+// makeJournalTestTemplate returns directory of I-Score DB in ctx and IISS data of nextBH.
+// Each crash test opens a copy of it, because reopening DB is slow with some DB backends
+func makeJournalTestTemplate(ctx *Context, nextBH uint64) string {
+	writeJournalTestIISSData(nextBH)
+	CloseIScoreDB(ctx.DB)
+	return testDir
+}
+
+// copyJournalTest copies I-Score DB in template to new testDir
+func copyJournalTest(t *testing.T, template string) {
+	var err error
+	testDir, err = ioutil.TempDir("", testDBBackend)
+	assert.NoError(t, err)
+	err = filepath.Walk(template, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(template, path)
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(testDir, rel), info.Mode())
+		}
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(testDir, rel), bs, info.Mode())
+	})
+	assert.NoError(t, err)
+}
+
+// openJournalTest opens a copy of I-Score DB in template
+func openJournalTest(t *testing.T, template string) *Context {
+	copyJournalTest(t, template)
+	ctx, err := NewContext(testDir, testDBBackend, "test", 0, "")
+	assert.NoError(t, err)
+	initJournalTestConfig(ctx)
+	return ctx
+}
+
+// commitJournalTestClaims commits claims before and after block height 25 rolled back by test
+func commitJournalTestClaims(t *testing.T, ctx *Context) {
+	for i, bh := range []uint64{22, 27, 33, 45} {
+		for j, address := range journalTestAddresses {
+			commitTestClaim(t, ctx, *common.NewAddressFromString(address), bh, uint64(j), uint64(100*(i+1)+j))
+		}
+	}
+}
+
+// journalTestState is I-Score DB state compared after recovery
+type journalTestState struct {
+	Info        DBInfoData
+	GV          []uint64
+	PRep        []uint64
+	Candidates  []string
+	Backups     []string
+	BackupFiles []string
+	Results     map[uint64]string
+	IScores     []string
+	Claims      []string
+	ClaimBackup string
+	Outbox      []string
+	Journal     bool
+}
+
+func readJournalTestState(t *testing.T, ctx *Context) *journalTestState {
+	idb := ctx.DB
+	s := &journalTestState{Info: idb.info.DBInfoData, Results: make(map[uint64]string)}
+
+	gvList, err := LoadGovernanceVariable(idb.management)
+	assert.NoError(t, err)
+	assert.Equal(t, len(gvList), len(ctx.GV))
+	for _, gv := range gvList {
+		s.GV = append(s.GV, gv.BlockHeight)
+	}
+	pRepList, err := LoadPRep(idb.management)
+	assert.NoError(t, err)
+	assert.Equal(t, len(pRepList), len(ctx.PRep))
+	for _, pRep := range pRepList {
+		s.PRep = append(s.PRep, pRep.BlockHeight)
+	}
+	candidates, err := LoadPRepCandidate(idb.management)
+	assert.NoError(t, err)
+	assert.Equal(t, len(candidates), len(ctx.PRepCandidates))
+	for _, pc := range candidates {
+		s.Candidates = append(s.Candidates, pc.String())
+	}
+	sort.Strings(s.Candidates)
+
+	backups, err := LoadAccountBackups(idb.management)
+	assert.NoError(t, err)
+	for _, ab := range backups {
+		s.Backups = append(s.Backups, ab.String())
+	}
+	files, err := filepath.Glob(filepath.Join(idb.info.DBRoot, BackupDBNamePrefix+"*"))
+	assert.NoError(t, err)
+	for _, f := range files {
+		_, name := filepath.Split(f)
+		s.BackupFiles = append(s.BackupFiles, name)
+	}
+
+	bucket, _ := idb.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	for bh := uint64(10); bh <= 50; bh += 10 {
+		if bs, _ := bucket.Get(common.Uint64ToBytes(bh)); bs != nil {
+			cr, err := NewCalculationResultFromBytes(bs)
+			assert.NoError(t, err)
+			s.Results[bh] = fmt.Sprintf("%x", cr.StateHash)
+		}
+	}
+
+	for _, address := range journalTestAddresses {
+		addr := *common.NewAddressFromString(address)
+		for _, aDB := range []db.Database{idb.getQueryDB(addr), idb.getCalculateDB(addr)} {
+			bucket, _ := aDB.GetBucket(db.PrefixIScore)
+			bs, _ := bucket.Get(addr.Bytes())
+			s.IScores = append(s.IScores, fmt.Sprintf("%x", bs))
+		}
+	}
+
+	for _, address := range journalTestAddresses {
+		addr := *common.NewAddressFromString(address)
+		claim, err := getClaimFromClaimDB(ctx, addr)
+		assert.NoError(t, err)
+		if claim != nil {
+			s.Claims = append(s.Claims, claim.String())
+		}
+		chList, err := ReadClaimHistory(idb.getClaimHistoryDB(), addr, 0, 0)
+		assert.NoError(t, err)
+		for _, ch := range chList {
+			s.Claims = append(s.Claims, ch.String())
+		}
+	}
+	cbInfo, err := loadClaimBackupInfo(idb.getClaimBackupDB())
+	assert.NoError(t, err)
+	s.ClaimBackup = cbInfo.String()
+
+	doneList, err := ReadCalculateDoneOutbox(idb.getCalculateResultDB())
+	assert.NoError(t, err)
+	for _, done := range doneList {
+		s.Outbox = append(s.Outbox, done.String())
+	}
+
+	s.Journal = hasJournal(idb.management)
+	return s
+}
+
+func TestDBJournal_CrashInCalculation(t *testing.T) {
+	const calcBH uint64 = 40
+	req := &CalculateRequest{BlockHeight: calcBH}
+	calculate := func(ctx *Context) {
+		req.Path = journalTestIISSPath(calcBH)
+		DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, req, nil, 0)
+	}
+	if runJournalTestChild(t, calculate) {
+		return
+	}
+	template := makeJournalTestTemplate(initJournalTest(t, 10, 20, 30), calcBH)
+	defer os.RemoveAll(template)
+
+	// calculation without crash
+	ctx := openJournalTest(t, template)
+	before := readJournalTestState(t, ctx)
+	restore := countCrashPoints()
+	calculate(ctx)
+	steps := restore()
+	expected := readJournalTestState(t, ctx)
+	finalizeTest(ctx)
+	assert.Equal(t, calcBH, expected.Info.CalcDone)
+	assert.Equal(t, len(before.Outbox)+1, len(expected.Outbox))
+	// the oldest backup account DB generation is deleted after commit
+	assert.Equal(t, before.BackupFiles[2:], expected.BackupFiles[:len(expected.BackupFiles)-2])
+	assert.False(t, expected.Journal)
+
+	// interrupted calculation is rolled back and calculated again, or rolled forward after commit
+	before.Info.Calculating = calcBH
+	assert.True(t, steps > 10)
+	for step := 1; step <= steps; step++ {
+		copyJournalTest(t, template)
+		assert.True(t, crashJournalTest(t, step), "crash at step %d", step)
+
+		ctx = restartJournalTest(t)
+		if ctx.DB.getCalcDoneBH() != calcBH {
+			assert.Equal(t, before, readJournalTestState(t, ctx), "crash at step %d", step)
+
+			// calculate again with IISS data on startup
+			assert.True(t, needIISSDataReload(ctx))
+			reload := &CalculateRequest{Path: journalTestIISSPath(calcBH), BlockHeight: reloadBlockHeight}
+			err, _, _, _ := DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, reload, nil, reloadMsgID)
+			assert.NoError(t, err)
+		}
+		// CALCULATE_DONE of calculation rolled forward is in outbox to send it on connection
+		assert.Equal(t, expected, readJournalTestState(t, ctx), "crash at step %d", step)
+		finalizeTest(ctx)
+	}
+}
+
+func TestDBJournal_CrashInRollback(t *testing.T) {
+	req := &RollBackRequest{BlockHeight: 25, BlockHash: []byte{25}}
+	rollback := func(ctx *Context) {
+		DoRollBack(ctx, req)
+	}
+	if runJournalTestChild(t, rollback) {
+		return
+	}
+	ctx := initJournalTest(t, 10, 20, 30, 40)
+	commitJournalTestClaims(t, ctx)
+	template := makeJournalTestTemplate(ctx, 50)
+	defer os.RemoveAll(template)
+
+	// rollback over 2 calculations and claims without crash
+	ctx = openJournalTest(t, template)
+	before := readJournalTestState(t, ctx)
+	restore := countCrashPoints()
+	assert.NoError(t, DoRollBack(ctx, req))
+	steps := restore()
+	expected := readJournalTestState(t, ctx)
+	finalizeTest(ctx)
+	assert.Equal(t, uint64(20), expected.Info.CalcDone)
+	assert.Equal(t, uint64(10), expected.Info.PrevCalcDone)
+	assert.Equal(t, req.BlockHeight, expected.Info.Current.BlockHeight)
+	assert.Equal(t, len(before.Claims)-3*len(journalTestAddresses), len(expected.Claims))
+	assert.False(t, expected.Journal)
+
+	// interrupted rollback is rolled forward
+	assert.True(t, steps > 10)
+	for step := 1; step <= steps; step++ {
+		copyJournalTest(t, template)
+		assert.True(t, crashJournalTest(t, step), "crash at step %d", step)
+
+		ctx = restartJournalTest(t)
+		assert.Equal(t, expected, readJournalTestState(t, ctx), "crash at step %d", step)
+		finalizeTest(ctx)
+	}
+}
+
+func TestDBJournal_RollbackCanceledCalculation(t *testing.T) {
+	ctx := initJournalTest(t, 10, 20)
+	defer finalizeTest(ctx)
+	before := readJournalTestState(t, ctx)
+
+	// calculation canceled by ROLLBACK leaves journal
+	quit := ctx.CancelCalculation.GetChannel()
+	ctx.CancelCalculation.notifyRollback()
+	req := &CalculateRequest{Path: writeJournalTestIISSData(30), BlockHeight: 30}
+	err, _, _, _ := DoCalculate(quit, ctx, req, nil, 0)
+	assert.True(t, isCalcCancelByRollback(err))
+	assert.True(t, ctx.DB.isCalculating())
+	assert.True(t, hasJournal(ctx.DB.management))
+
+	// rollback above calculation block height rolls back canceled calculation and block info only
+	assert.NoError(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 25, BlockHash: []byte{25}}))
+	before.Info.Current.set(25, []byte{25})
+	assert.Equal(t, before, readJournalTestState(t, ctx))
+
+	// calculation after rollback
+	calculateJournalTest(t, ctx, 30)
+	assert.Equal(t, uint64(30), ctx.DB.getCalcDoneBH())
+	assert.False(t, ctx.DB.isCalculating())
+}
@@ -3,6 +3,7 @@ package core
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"log"
 	"math/big"
 	"path/filepath"
@@ -18,6 +19,9 @@ import (
 const (
 	MaxDBCount  int    = 256
 
+	// Management DB is always goleveldb to check DB backend of other I-Score DBs
+	ManagementDBBackend = string(db.GoLevelDBBackend)
+
 	NumMainPRep uint64 = 22
 	NumSubPRep  uint64 = 78
 )
@@ -56,7 +60,18 @@ type DBInfoDataV2 struct {
 	ToggleBH      uint64	// Latest account DB toggle block height
 }
 
-type DBInfoData DBInfoDataV2
+type DBInfoDataV3 struct {
+	DBCount       int
+	QueryDBIsZero bool
+	Current       BlockInfo // Latest COMMIT_BLOCK block height and hash
+	CalcDone      uint64    // Latest CALCULATE_DONE block height
+	PrevCalcDone  uint64    // Previous CALCULATE_DONE block height
+	Calculating   uint64    // Latest CALCULATE block height
+	ToggleBH      uint64	// Latest account DB toggle block height
+	Backend       string    // DB backend of I-Score DBs
+}
+
+type DBInfoData DBInfoDataV3
 
 type DBInfo struct {
 	DBRoot        string
@@ -126,9 +141,19 @@ func NewDBInfo(mngDB db.Database, dbPath string, dbType string, dbName string, d
 			log.Panicf("Failed to set DB Information structure\n")
 			return nil, err
 		}
+		if dbInfo.Backend == "" {
+			// DB was made with goleveldb before DB backend was written
+			dbInfo.Backend = string(db.GoLevelDBBackend)
+			writeToDB = true
+		}
+		if dbInfo.Backend != dbType {
+			log.Printf("DB backend mismatch. %s was created with %s", dbName, dbInfo.Backend)
+			return nil, &DBBackendMismatchError{dbInfo.Backend, dbType}
+		}
 	} else {
 		// set DB count
 		dbInfo.DBCount = dbCount
+		dbInfo.Backend = dbType
 
 		writeToDB = true
 	}
@@ -145,6 +170,31 @@ func NewDBInfo(mngDB db.Database, dbPath string, dbType string, dbName string, d
 	return dbInfo, nil
 }
 
+type DBBackendMismatchError struct {
+	Backend   string
+	Requested string
+}
+
+func (e *DBBackendMismatchError) Error() string {
+	return fmt.Sprintf("DB backend mismatch. DB was created with %s, but %s requested", e.Backend, e.Requested)
+}
+
+// Backends which can be used for I-Score DB. mapdb is excluded because it does not persist
+var DBBackends = []string{
+	string(db.GoLevelDBBackend),
+	string(db.BadgerDBBackend),
+	string(db.BoltDBBackend),
+}
+
+func CheckDBBackend(dbType string) error {
+	for _, backend := range DBBackends {
+		if dbType == backend {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid DB backend %s. expected one of %v", dbType, DBBackends)
+}
+
 var BigIntTwo = big.NewInt(2)
 var BigInt100 = big.NewInt(100)
 var BigIntIScoreMultiplier = big.NewInt(iScoreMultiplier)
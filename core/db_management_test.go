@@ -46,6 +46,28 @@ func TestDBMNGDBInfo_BytesAndSetBytes(t *testing.T) {
 	assert.Equal(t, bs, bsNew)
 }
 
+func TestDBMNGDBInfo_SetBytesV2(t *testing.T) {
+	var v2 DBInfoDataV2
+	v2.DBCount = 2
+	v2.QueryDBIsZero = true
+	v2.CalcDone = iaBlockHeight
+	v2.PrevCalcDone = iaBlockHeight + 10
+	v2.Calculating = iaBlockHeight
+	v2.ToggleBH = iaBlockHeight + 1
+	bs, _ := codec.MarshalToBytes(&v2)
+
+	var dbInfo DBInfo
+	err := dbInfo.SetBytes(bs)
+	assert.NoError(t, err)
+	assert.Equal(t, v2.DBCount, dbInfo.DBCount)
+	assert.Equal(t, v2.QueryDBIsZero, dbInfo.QueryDBIsZero)
+	assert.Equal(t, v2.CalcDone, dbInfo.CalcDone)
+	assert.Equal(t, v2.PrevCalcDone, dbInfo.PrevCalcDone)
+	assert.Equal(t, v2.Calculating, dbInfo.Calculating)
+	assert.Equal(t, v2.ToggleBH, dbInfo.ToggleBH)
+	assert.Equal(t, "", dbInfo.Backend)
+}
+
 func TestDBMNGDBInfo_NewDBInfo(t *testing.T) {
 	mngDB := db.Open(testDBDir, string(db.GoLevelDBBackend), testDB)
 	defer mngDB.Close()
@@ -92,6 +114,43 @@ func TestDBMNGDBInfo_NewDBInfo(t *testing.T) {
 	assert.Equal(t, dbInfo.CalcDone, dbInfo1.CalcDone)
 	assert.Equal(t, dbInfo.PrevCalcDone, dbInfo1.PrevCalcDone)
 	assert.Equal(t, dbInfo.Calculating, dbInfo1.Calculating)
+	assert.Equal(t, string(db.GoLevelDBBackend), dbInfo1.Backend)
+
+	// refuse mismatched DB backend
+	dbInfo2, err := NewDBInfo(mngDB, testDBDir, string(db.BadgerDBBackend), testDB, 10)
+	assert.Nil(t, dbInfo2)
+	assert.Error(t, err)
+}
+
+func TestDBMNGDBInfo_NewDBInfoWithoutBackend(t *testing.T) {
+	mngDB := db.Open(testDBDir, string(db.GoLevelDBBackend), testDB)
+	defer mngDB.Close()
+	defer os.RemoveAll(testDBDir)
+
+	// write DB Info. without DB backend
+	var v2 DBInfoDataV2
+	v2.DBCount = 2
+	v2.CalcDone = 100
+	bs, _ := codec.MarshalToBytes(&v2)
+	bucket, _ := mngDB.GetBucket(db.PrefixManagement)
+	bucket.Set([]byte(""), bs)
+
+	// DB was made with goleveldb
+	dbInfo, err := NewDBInfo(mngDB, testDBDir, string(db.BoltDBBackend), testDB, 1)
+	assert.Nil(t, dbInfo)
+	assert.Error(t, err)
+
+	dbInfo, err = NewDBInfo(mngDB, testDBDir, string(db.GoLevelDBBackend), testDB, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, v2.DBCount, dbInfo.DBCount)
+	assert.Equal(t, v2.CalcDone, dbInfo.CalcDone)
+	assert.Equal(t, string(db.GoLevelDBBackend), dbInfo.Backend)
+
+	// DB backend was written
+	bs, _ = bucket.Get(dbInfo.ID())
+	var dbInfoNew DBInfo
+	dbInfoNew.SetBytes(bs)
+	assert.Equal(t, string(db.GoLevelDBBackend), dbInfoNew.Backend)
 }
 
 
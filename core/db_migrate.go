@@ -0,0 +1,521 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"golang.org/x/crypto/sha3"
+)
+
+const migrateBatchCount = 1000
+
+// MigrateIScoreDB copies I-Score DB in srcRoot to dstRoot with new account DB count and DB backend.
+// Accounts are re-sharded to new account DBs by getAccountDBIndex().
+// dbCount 0 and empty backend mean the values of source DB.
+func MigrateIScoreDB(srcRoot string, dstRoot string, dbCount int, backend string) error {
+	srcRoot = filepath.Clean(srcRoot)
+	dstRoot = filepath.Clean(dstRoot)
+	if _, err := os.Stat(dstRoot); !os.IsNotExist(err) {
+		return fmt.Errorf("%s already exists", dstRoot)
+	}
+
+	srcBackend, err := ReadDBBackend(srcRoot)
+	if err != nil {
+		return err
+	}
+	srcDir, srcName := filepath.Split(srcRoot)
+	src, err := NewContext(srcDir, srcBackend, srcName, 0, "")
+	if err != nil {
+		return err
+	}
+	defer CloseIScoreDB(src.DB)
+
+	if src.DB.isCalculating() {
+		return fmt.Errorf("can't migrate I-Score DB while calculating. CalcDone: %d, Calculating: %d",
+			src.DB.getCalcDoneBH(), src.DB.getCalculatingBH())
+	}
+	if hasJournal(src.DB.management) {
+		return fmt.Errorf("can't migrate I-Score DB with interrupted calculation or rollback. run icon_rc to recover")
+	}
+
+	if dbCount == 0 {
+		dbCount = src.DB.info.DBCount
+	}
+	if dbCount < 0 || dbCount > MaxDBCount {
+		return fmt.Errorf("invalid DB count %d. MAX: %d", dbCount, MaxDBCount)
+	}
+	if backend == "" {
+		backend = srcBackend
+	}
+
+	log.Printf("Start migrate I-Score DB. %s(%s, %d) -> %s(%s, %d)",
+		srcRoot, srcBackend, src.DB.info.DBCount, dstRoot, backend, dbCount)
+
+	dstDir, dstName := filepath.Split(dstRoot)
+	dst, err := NewContext(dstDir, backend, dstName, dbCount, "")
+	if err != nil {
+		return err
+	}
+	defer CloseIScoreDB(dst.DB)
+
+	// management DB
+	if err = copyManagementDB(src.DB, dst.DB); err != nil {
+		return err
+	}
+
+	// calculation result, preCommit, claim, claim backup and claim history DB
+	copyList := []struct {
+		src db.Database
+		dst db.Database
+	}{
+		{src.DB.calcResult, dst.DB.calcResult},
+		{src.DB.preCommit, dst.DB.preCommit},
+		{src.DB.claim, dst.DB.claim},
+		{src.DB.claimBackup, dst.DB.claimBackup},
+		{src.DB.claimHistory, dst.DB.claimHistory},
+	}
+	for _, v := range copyList {
+		if err = copyDB(v.src, v.dst); err != nil {
+			return err
+		}
+	}
+
+	// DBs of optional features which exist in source I-Score DB
+	for _, v := range openOptionalDBs(src.DB, dst.DB) {
+		if err = copyDB(v.src, v.dst); err != nil {
+			return err
+		}
+	}
+
+	// account DBs
+	if err = reshardAccountDB(src.DB.Account0, dst.DB.Account0, dst.DB.getAccountDBIndex); err != nil {
+		return err
+	}
+	if err = reshardAccountDB(src.DB.Account1, dst.DB.Account1, dst.DB.getAccountDBIndex); err != nil {
+		return err
+	}
+
+	// backup account DBs for rollback
+	if err = reshardBackupAccountDB(src.DB, dst.DB); err != nil {
+		return err
+	}
+
+	// verify
+	if err = verifyMigration(src.DB, dst.DB); err != nil {
+		return err
+	}
+
+	log.Printf("End migrate I-Score DB. %s -> %s", srcRoot, dstRoot)
+	return nil
+}
+
+type optionalDB struct {
+	name string
+	src  db.Database
+	dst  db.Database
+}
+
+// openOptionalDBs opens DBs of optional features in src and dst if they exist in src.
+// They are closed with I-Score DB
+func openOptionalDBs(src *IScoreDB, dst *IScoreDB) []optionalDB {
+	dbList := []struct {
+		name string
+		src  *db.Database
+		dst  *db.Database
+	}{
+		{RewardBreakdownDBName, &src.rewardBreakdown, &dst.rewardBreakdown},
+		{PRepReportDBName, &src.pRepReport, &dst.pRepReport},
+	}
+
+	optionalDBs := make([]optionalDB, 0, len(dbList))
+	for _, v := range dbList {
+		if *v.src == nil {
+			if _, err := os.Stat(filepath.Join(src.info.DBRoot, v.name)); err != nil {
+				continue
+			}
+			*v.src = db.Open(src.info.DBRoot, src.info.DBType, v.name)
+		}
+		if *v.dst == nil {
+			*v.dst = db.Open(dst.info.DBRoot, dst.info.DBType, v.name)
+		}
+		optionalDBs = append(optionalDBs, optionalDB{v.name, *v.src, *v.dst})
+	}
+	return optionalDBs
+}
+
+// ReadDBBackend returns DB backend of I-Score DB in dbRoot
+func ReadDBBackend(dbRoot string) (string, error) {
+	dir, name := filepath.Split(filepath.Clean(dbRoot))
+	if _, err := os.Stat(dbRoot); err != nil {
+		return "", err
+	}
+	mngDB := db.Open(dir, ManagementDBBackend, name)
+	defer mngDB.Close()
+
+	bucket, err := mngDB.GetBucket(db.PrefixManagement)
+	if err != nil {
+		return "", err
+	}
+	dbInfo := new(DBInfo)
+	bs, err := bucket.Get(dbInfo.ID())
+	if err != nil {
+		return "", err
+	}
+	if bs == nil {
+		return "", fmt.Errorf("there is no DB Information in %s", dbRoot)
+	}
+	if err = dbInfo.SetBytes(bs); err != nil {
+		return "", err
+	}
+	if dbInfo.Backend == "" {
+		return string(db.GoLevelDBBackend), nil
+	}
+	return dbInfo.Backend, nil
+}
+
+func copyManagementDB(src *IScoreDB, dst *IScoreDB) error {
+	dbInfo := new(DBInfo)
+	infoKey := append([]byte(db.PrefixManagement), dbInfo.ID()...)
+
+	err := writeEntries(dst.management, func(f func(key []byte, value []byte) error) error {
+		return iterateSorted([]db.Database{src.management}, nil, nil, func(key []byte, value []byte) error {
+			if bytes.Equal(key, infoKey) {
+				return nil
+			}
+			return f(key, value)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// DB Info. with new DB count and backend
+	dbCount := dst.info.DBCount
+	backend := dst.info.Backend
+	dst.info.DBInfoData = src.info.DBInfoData
+	dst.info.DBCount = dbCount
+	dst.info.Backend = backend
+	dst.writeToDB()
+
+	return nil
+}
+
+func copyDB(src db.Database, dst db.Database) error {
+	return writeEntries(dst, func(f func(key []byte, value []byte) error) error {
+		return iterateSorted([]db.Database{src}, nil, nil, f)
+	})
+}
+
+func reshardAccountDB(src []db.Database, dst []db.Database, getIndex func(address common.Address) int) error {
+	batches := make([]db.Batch, len(dst))
+	for i, dstDB := range dst {
+		batches[i], _ = dstDB.GetBatch()
+		batches[i].New()
+	}
+
+	err := iterateSorted(src, nil, nil, func(key []byte, value []byte) error {
+		address := common.NewAddress(key[len(db.PrefixIScore):])
+		batch := batches[getIndex(*address)]
+		batch.Set(key, value)
+		if batch.Len() >= migrateBatchCount {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		if err = batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	return nil
+}
+
+// getBackupBlockHeights returns block heights of backup account DBs in DB root
+func getBackupBlockHeights(dbRoot string) ([]uint64, error) {
+	backups, err := filepath.Glob(filepath.Join(dbRoot, BackupDBNamePrefix+"*"))
+	if err != nil {
+		return nil, err
+	}
+
+	bhMap := make(map[uint64]bool)
+	for _, f := range backups {
+		var backupBH uint64
+		var index int
+		_, backupName := filepath.Split(f)
+		if _, err = fmt.Sscanf(backupName, BackupDBNameFormat, &backupBH, &index); err != nil {
+			continue
+		}
+		bhMap[backupBH] = true
+	}
+
+	blockHeights := make([]uint64, 0, len(bhMap))
+	for bh := range bhMap {
+		blockHeights = append(blockHeights, bh)
+	}
+	sort.Slice(blockHeights, func(i, j int) bool { return blockHeights[i] < blockHeights[j] })
+
+	return blockHeights, nil
+}
+
+func openBackupAccountDB(idb *IScoreDB, blockHeight uint64) []db.Database {
+	backups := make([]db.Database, idb.info.DBCount)
+	for i := range backups {
+		backups[i] = db.Open(idb.info.DBRoot, idb.info.Backend, fmt.Sprintf(BackupDBNameFormat, blockHeight, i+1))
+	}
+	return backups
+}
+
+func closeDBList(dbList []db.Database) {
+	for _, v := range dbList {
+		v.Close()
+	}
+}
+
+func reshardBackupAccountDB(src *IScoreDB, dst *IScoreDB) error {
+	blockHeights, err := getBackupBlockHeights(src.info.DBRoot)
+	if err != nil {
+		return err
+	}
+
+	for _, blockHeight := range blockHeights {
+		srcBackups := openBackupAccountDB(src, blockHeight)
+		dstBackups := openBackupAccountDB(dst, blockHeight)
+		err = reshardAccountDB(srcBackups, dstBackups, dst.getAccountDBIndex)
+		closeDBList(srcBackups)
+		closeDBList(dstBackups)
+		if err != nil {
+			return err
+		}
+		log.Printf("Migrate %d backup account DBs of %d", len(dstBackups), blockHeight)
+	}
+
+	return nil
+}
+
+func verifyMigration(src *IScoreDB, dst *IScoreDB) error {
+	type verifyData struct {
+		name  string
+		src   []db.Database
+		dst   []db.Database
+		slice *util.Range
+	}
+	all := &util.Range{}
+	verifyList := []verifyData{
+		{"account DB 0", src.Account0, dst.Account0, all},
+		{"account DB 1", src.Account1, dst.Account1, all},
+		{"calculation result DB", []db.Database{src.calcResult}, []db.Database{dst.calcResult}, all},
+		{"preCommit DB", []db.Database{src.preCommit}, []db.Database{dst.preCommit}, all},
+		{"claim DB", []db.Database{src.claim}, []db.Database{dst.claim}, all},
+		{"claim backup DB", []db.Database{src.claimBackup}, []db.Database{dst.claimBackup}, all},
+		{"claim history DB", []db.Database{src.claimHistory}, []db.Database{dst.claimHistory}, all},
+	}
+	for _, v := range openOptionalDBs(src, dst) {
+		verifyList = append(verifyList, verifyData{v.name + " DB", []db.Database{v.src}, []db.Database{v.dst}, all})
+	}
+	for _, prefix := range []db.BucketID{db.PrefixGovernanceVariable, db.PrefixPRep, db.PrefixPRepCandidate,
+		db.PrefixPRepCandidateLog} {
+		verifyList = append(verifyList, verifyData{
+			"management DB " + string(prefix),
+			[]db.Database{src.management},
+			[]db.Database{dst.management},
+			util.BytesPrefix([]byte(prefix)),
+		})
+	}
+
+	for _, v := range verifyList {
+		if err := verifyDBHash(v.name, v.src, v.dst, v.slice); err != nil {
+			return err
+		}
+	}
+
+	// state hash of accounts in calculate DB and query DB
+	crDB := src.getCalculateResultDB()
+	if err := verifyStateHash("calculate DB", src.GetCalcDBList(), dst.GetCalcDBList(), crDB,
+		src.getCalcDoneBH()); err != nil {
+		return err
+	}
+	if err := verifyStateHash("query DB", src.getQueryDBList(), dst.getQueryDBList(), crDB,
+		src.getPrevCalcDoneBH()); err != nil {
+		return err
+	}
+
+	// backup account DBs
+	blockHeights, err := getBackupBlockHeights(src.info.DBRoot)
+	if err != nil {
+		return err
+	}
+	for _, blockHeight := range blockHeights {
+		srcBackups := openBackupAccountDB(src, blockHeight)
+		dstBackups := openBackupAccountDB(dst, blockHeight)
+		name := fmt.Sprintf("backup account DB %d", blockHeight)
+		err = verifyDBHash(name, srcBackups, dstBackups, all)
+		if err == nil {
+			err = verifyStateHash(name, srcBackups, dstBackups, nil, 0)
+		}
+		closeDBList(srcBackups)
+		closeDBList(dstBackups)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func verifyDBHash(name string, src []db.Database, dst []db.Database, slice *util.Range) error {
+	srcHash, srcCount, err := hashSortedEntries(src, slice.Start, slice.Limit)
+	if err != nil {
+		return err
+	}
+	dstHash, dstCount, err := hashSortedEntries(dst, slice.Start, slice.Limit)
+	if err != nil {
+		return err
+	}
+	if srcCount != dstCount || !bytes.Equal(srcHash, dstHash) {
+		return fmt.Errorf("failed to verify %s. source: %d, %x. migrated: %d, %x",
+			name, srcCount, srcHash, dstCount, dstHash)
+	}
+	log.Printf("Verified %s. %d entries, %x", name, dstCount, dstHash)
+	return nil
+}
+
+// verifyStateHash compares StateHashV2 of accounts in src and dst.
+// It is compared with the state hash of calculation result at blockHeight too if crDB is not nil
+func verifyStateHash(name string, src []db.Database, dst []db.Database, crDB db.Database, blockHeight uint64) error {
+	srcHash, srcCount, err := CalculateStateHashV2(src)
+	if err != nil {
+		return err
+	}
+	dstHash, dstCount, err := CalculateStateHashV2(dst)
+	if err != nil {
+		return err
+	}
+	if srcCount != dstCount || !bytes.Equal(srcHash, dstHash) {
+		return fmt.Errorf("failed to verify state hash of %s. source: %d, %x. migrated: %d, %x",
+			name, srcCount, srcHash, dstCount, dstHash)
+	}
+	log.Printf("Verified state hash of %s. %d accounts, %x", name, dstCount, dstHash)
+
+	if crDB == nil || blockHeight == 0 {
+		return nil
+	}
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+	bs, err := bucket.Get(common.Uint64ToBytes(blockHeight))
+	if err != nil || bs == nil {
+		return err
+	}
+	cr, err := NewCalculationResultFromBytes(bs)
+	if err != nil {
+		return err
+	}
+	// calculation result of revision < RevisionStateHashV2 has StateHashV1 which depends on account DB count
+	if bytes.Equal(cr.StateHash, dstHash) {
+		log.Printf("Verified state hash of %s with calculation result of %d", name, blockHeight)
+	} else {
+		log.Printf("State hash of calculation result of %d is not StateHashV2. %x", blockHeight, cr.StateHash)
+	}
+	return nil
+}
+
+// hashSortedEntries returns hash of entries in DBs sorted by key and the number of entries.
+// The hash does not depend on how entries are distributed to DBs
+func hashSortedEntries(dbList []db.Database, start []byte, limit []byte) ([]byte, uint64, error) {
+	h := sha3.NewShake256()
+	var count uint64
+	err := iterateSorted(dbList, start, limit, func(key []byte, value []byte) error {
+		h.Write(key)
+		h.Write(value)
+		count++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hash := make([]byte, 64)
+	h.Read(hash)
+	return hash, count, nil
+}
+
+// iterateSorted calls f with entries of all DBs in dbList in order of key
+func iterateSorted(dbList []db.Database, start []byte, limit []byte, f func(key []byte, value []byte) error) error {
+	iters := make([]db.Iterator, len(dbList))
+	valid := make([]bool, len(dbList))
+	for i, v := range dbList {
+		iter, err := v.GetIterator()
+		if err != nil {
+			return err
+		}
+		iter.New(start, limit)
+		iters[i] = iter
+		valid[i] = iter.Next()
+	}
+	defer func() {
+		for _, iter := range iters {
+			iter.Release()
+		}
+	}()
+
+	for {
+		next := -1
+		for i, iter := range iters {
+			if valid[i] && (next == -1 || bytes.Compare(iter.Key(), iters[next].Key()) < 0) {
+				next = i
+			}
+		}
+		if next == -1 {
+			break
+		}
+		if err := f(iters[next].Key(), iters[next].Value()); err != nil {
+			return err
+		}
+		valid[next] = iters[next].Next()
+	}
+
+	for _, iter := range iters {
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEntries writes entries given by iterate to dst with batch
+func writeEntries(dst db.Database, iterate func(f func(key []byte, value []byte) error) error) error {
+	batch, err := dst.GetBatch()
+	if err != nil {
+		return err
+	}
+	batch.New()
+
+	err = iterate(func(key []byte, value []byte) error {
+		batch.Set(key, value)
+		if batch.Len() >= migrateBatchCount {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return batch.Write()
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeMigrateTestAccounts(dbList []db.Database, getIndex func(address common.Address) int,
+	count int, blockHeight uint64) []*IScoreAccount {
+	accounts := make([]*IScoreAccount, 0, count)
+	for i := 0; i < count; i++ {
+		ia := new(IScoreAccount)
+		ia.Address = *common.NewAddressFromString(fmt.Sprintf("hx%040x", i*7+1))
+		ia.IScore.SetUint64(uint64(i * 100))
+		ia.BlockHeight = blockHeight
+		bucket, _ := dbList[getIndex(ia.Address)].GetBucket(db.PrefixIScore)
+		bucket.Set(ia.ID(), ia.Bytes())
+		accounts = append(accounts, ia)
+	}
+	return accounts
+}
+
+func checkMigrateTestAccounts(t *testing.T, dbList []db.Database, getIndex func(address common.Address) int,
+	accounts []*IScoreAccount) {
+	for _, ia := range accounts {
+		bucket, _ := dbList[getIndex(ia.Address)].GetBucket(db.PrefixIScore)
+		bs, err := bucket.Get(ia.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, ia.Bytes(), bs)
+	}
+}
+
+func TestDBMigrate_MigrateIScoreDB(t *testing.T) {
+	const (
+		srcDBCount   = 2
+		dstDBCount   = 5
+		accountCount = 50
+		calcBH       = uint64(100)
+		backupBH     = uint64(90)
+	)
+	ctx := initTest(srcDBCount)
+	defer os.RemoveAll(testDir)
+	srcRoot := ctx.DB.info.DBRoot
+	dstRoot := filepath.Join(testDir, "migrated")
+
+	// write data
+	ctx.DB.setCalculatingBH(calcBH)
+	ctx.DB.setCalcDoneBH(calcBH)
+	ctx.DB.setCurrentBlockInfo(calcBH+10, testHash)
+	ctx.DB.toggleAccountDB(calcBH)
+	queryAccounts := writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex,
+		accountCount, calcBH-10)
+	calcAccounts := writeMigrateTestAccounts(ctx.DB.GetCalcDBList(), ctx.DB.getAccountDBIndex,
+		accountCount/2, calcBH)
+
+	gv := makeGV(calcBH)
+	gvBucket, _ := ctx.DB.management.GetBucket(db.PrefixGovernanceVariable)
+	bs, _ := gv.Bytes()
+	gvBucket.Set(gv.ID(), bs)
+
+	claim := makeClaim()
+	claimBucket, _ := ctx.DB.getClaimDB().GetBucket(db.PrefixClaim)
+	claimBucket.Set(claim.ID(), claim.Bytes())
+
+	stats := new(Statistics)
+	stats.Increase("Accounts", uint64(accountCount))
+	stateHash, _, _ := CalculateStateHashV2(ctx.DB.GetCalcDBList())
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), calcBH, stats, stateHash, nil)
+
+	ctx.DB.SetRewardBreakdowns(1)
+	rr := ctx.DB.newRewardRecorder()
+	rr.addBeta1(ctx.DB.getAccountDBIndex(claim.Address), claim.Address, big.NewInt(10))
+	assert.NoError(t, ctx.DB.writeRewardBreakdown(calcBH, rr))
+
+	ctx.DB.SetPRepReports(1)
+	pr := ctx.DB.newPRepReporter()
+	pRep := *common.NewAddressFromString("hx22")
+	pr.recorder(ctx.DB.getAccountDBIndex(claim.Address), claim.Address, 1)(pRep, common.NewHexIntFromUint64(20))
+	assert.NoError(t, ctx.DB.writePRepReport(calcBH, pr))
+
+	backups := make([]db.Database, srcDBCount)
+	for i := range backups {
+		backups[i] = db.Open(srcRoot, ctx.DB.info.DBType, fmt.Sprintf(BackupDBNameFormat, backupBH, i+1))
+	}
+	backupAccounts := writeMigrateTestAccounts(backups, ctx.DB.getAccountDBIndex, accountCount, backupBH)
+	closeDBList(backups)
+
+	srcInfo := ctx.DB.info.DBInfoData
+	CloseIScoreDB(ctx.DB)
+
+	// migrate to another DB backend
+	backend := DBBackends[0]
+	if backend == testDBBackend {
+		backend = DBBackends[1]
+	}
+	err := MigrateIScoreDB(srcRoot, dstRoot, dstDBCount, backend)
+	assert.NoError(t, err)
+
+	// migrate to existing DB
+	err = MigrateIScoreDB(srcRoot, dstRoot, dstDBCount, backend)
+	assert.Error(t, err)
+
+	// check migrated DB
+	dbBackend, err := ReadDBBackend(dstRoot)
+	assert.NoError(t, err)
+	assert.Equal(t, backend, dbBackend)
+
+	dir, name := filepath.Split(dstRoot)
+	dst, err := NewContext(dir, backend, name, 0, "")
+	assert.NoError(t, err)
+	defer CloseIScoreDB(dst.DB)
+
+	assert.Equal(t, dstDBCount, dst.DB.info.DBCount)
+	assert.Equal(t, dstDBCount, len(dst.DB.Account0))
+	assert.Equal(t, backend, dst.DB.info.Backend)
+	assert.Equal(t, srcInfo.QueryDBIsZero, dst.DB.info.QueryDBIsZero)
+	assert.Equal(t, srcInfo.CalcDone, dst.DB.info.CalcDone)
+	assert.Equal(t, srcInfo.PrevCalcDone, dst.DB.info.PrevCalcDone)
+	assert.Equal(t, srcInfo.Calculating, dst.DB.info.Calculating)
+	assert.Equal(t, srcInfo.ToggleBH, dst.DB.info.ToggleBH)
+	assert.True(t, srcInfo.Current.equal(&dst.DB.info.Current))
+
+	checkMigrateTestAccounts(t, dst.DB.getQueryDBList(), dst.DB.getAccountDBIndex, queryAccounts)
+	checkMigrateTestAccounts(t, dst.DB.GetCalcDBList(), dst.DB.getAccountDBIndex, calcAccounts)
+
+	assert.Equal(t, 1, len(dst.GV))
+	assert.Equal(t, gv.BlockHeight, dst.GV[0].BlockHeight)
+
+	claimBucket, _ = dst.DB.getClaimDB().GetBucket(db.PrefixClaim)
+	bs, _ = claimBucket.Get(claim.ID())
+	assert.Equal(t, claim.Bytes(), bs)
+
+	crBucket, _ := dst.DB.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	bs, _ = crBucket.Get(common.Uint64ToBytes(calcBH))
+	assert.NotNil(t, bs)
+	dstHash, _, err := CalculateStateHashV2(dst.DB.GetCalcDBList())
+	assert.NoError(t, err)
+	assert.Equal(t, stateHash, dstHash)
+
+	dst.DB.SetRewardBreakdowns(1)
+	breakdowns, err := ReadRewardBreakdowns(dst.DB.getRewardBreakdownDB(), claim.Address, calcBH)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(breakdowns))
+	assert.Equal(t, int64(10), breakdowns[0].Beta1.Int64())
+
+	dst.DB.SetPRepReports(1)
+	reports, err := ReadPRepReports(dst.DB.getPRepReportDB(), calcBH)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, pRep, reports[0].Address)
+	assert.Equal(t, int64(20), reports[0].Beta3.Int64())
+
+	backupBHs, err := getBackupBlockHeights(dst.DB.info.DBRoot)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{backupBH}, backupBHs)
+	backups = openBackupAccountDB(dst.DB, backupBH)
+	assert.Equal(t, dstDBCount, len(backups))
+	checkMigrateTestAccounts(t, backups, dst.DB.getAccountDBIndex, backupAccounts)
+	closeDBList(backups)
+}
+
+func TestDBMigrate_MigrateIScoreDBCalculating(t *testing.T) {
+	ctx := initTest(1)
+	defer os.RemoveAll(testDir)
+	srcRoot := ctx.DB.info.DBRoot
+
+	ctx.DB.setCalculatingBH(100)
+	CloseIScoreDB(ctx.DB)
+
+	err := MigrateIScoreDB(srcRoot, filepath.Join(testDir, "migrated"), 2, "")
+	assert.Error(t, err)
+}
+
+func TestDBMigrate_hashSortedEntries(t *testing.T) {
+	ctx := initTest(4)
+	defer finalizeTest(ctx)
+
+	accounts := writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 20, 10)
+	hash4, count, err := hashSortedEntries(ctx.DB.getQueryDBList(), nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(accounts)), count)
+
+	// same accounts in one DB
+	oneDB := ctx.DB.GetCalcDBList()[:1]
+	writeMigrateTestAccounts(oneDB, func(common.Address) int { return 0 }, 20, 10)
+	hash1, count, err := hashSortedEntries(oneDB, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(accounts)), count)
+	assert.Equal(t, hash4, hash1)
+}
+
+func TestDBMigrate_verifyStateHash(t *testing.T) {
+	ctx := initTest(4)
+	defer finalizeTest(ctx)
+
+	writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 20, 10)
+	oneDB := ctx.DB.GetCalcDBList()[:1]
+	accounts := writeMigrateTestAccounts(oneDB, func(common.Address) int { return 0 }, 20, 10)
+	assert.NoError(t, verifyStateHash("test", ctx.DB.getQueryDBList(), oneDB, nil, 0))
+
+	// compare with calculation result
+	stateHash, _, _ := CalculateStateHashV2(oneDB)
+	crDB := ctx.DB.getCalculateResultDB()
+	WriteCalculationResult(crDB, 10, new(Statistics), stateHash, nil)
+	assert.NoError(t, verifyStateHash("test", ctx.DB.getQueryDBList(), oneDB, crDB, 10))
+
+	// different I-Score
+	ia := accounts[0]
+	ia.IScore.SetUint64(1)
+	bucket, _ := oneDB[0].GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+	assert.Error(t, verifyStateHash("test", ctx.DB.getQueryDBList(), oneDB, crDB, 10))
+}
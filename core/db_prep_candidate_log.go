@@ -0,0 +1,188 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const PRepCandidateLogIDSize = BlockHeightSize + BlockHeightSize
+
+type PRepCandidateLogData struct {
+	Address common.Address
+	// P-Rep candidate before change. Exist is false if it was not registered
+	Exist bool
+	PRepCandidateData
+}
+
+// PRepCandidateLog is a change of P-Rep candidate by IISS TX at BlockHeight
+type PRepCandidateLog struct {
+	BlockHeight uint64
+	TXIndex     uint64
+	PRepCandidateLogData
+}
+
+func (pl *PRepCandidateLog) ID() []byte {
+	id := make([]byte, PRepCandidateLogIDSize)
+	bh := common.Uint64ToBytes(pl.BlockHeight)
+	copy(id[BlockHeightSize-len(bh):], bh)
+	index := common.Uint64ToBytes(pl.TXIndex)
+	copy(id[PRepCandidateLogIDSize-len(index):], index)
+	return id
+}
+
+func (pl *PRepCandidateLog) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&pl.PRepCandidateLogData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (pl *PRepCandidateLog) String() string {
+	b, err := json.Marshal(pl)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (pl *PRepCandidateLog) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &pl.PRepCandidateLogData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewPRepCandidateLogFromBytes makes PRepCandidateLog with key without bucket prefix and value
+func NewPRepCandidateLogFromBytes(key []byte, value []byte) (*PRepCandidateLog, error) {
+	pl := new(PRepCandidateLog)
+	if err := pl.SetBytes(value); err != nil {
+		return nil, err
+	}
+	pl.BlockHeight = common.BytesToUint64(key[:BlockHeightSize])
+	pl.TXIndex = common.BytesToUint64(key[BlockHeightSize:])
+	return pl, nil
+}
+
+// writePRepCandidateLog writes P-Rep candidate of address before it is changed by tx
+func writePRepCandidateLog(mngDB db.Database, tx *IISSTX, prev *PRepCandidate) error {
+	pl := &PRepCandidateLog{BlockHeight: tx.BlockHeight, TXIndex: tx.Index}
+	pl.Address = tx.Address
+	if prev != nil {
+		pl.Exist = true
+		pl.PRepCandidateData = prev.PRepCandidateData
+	}
+
+	bucket, _ := mngDB.GetBucket(db.PrefixPRepCandidateLog)
+	bs, err := pl.Bytes()
+	if err != nil {
+		return err
+	}
+	return bucket.Set(pl.ID(), bs)
+}
+
+// loadPRepCandidateLogs reads P-Rep candidate changes above block height from in change order
+func loadPRepCandidateLogs(mngDB db.Database, from uint64) ([]*PRepCandidateLog, error) {
+	logs := make([]*PRepCandidateLog, 0)
+
+	iter, err := mngDB.GetIterator()
+	if err != nil {
+		return logs, err
+	}
+	// layer DB of simulation can't iterate
+	if iter == nil {
+		return logs, nil
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixPRepCandidateLog))
+	start := (&PRepCandidateLog{BlockHeight: from + 1}).ID()
+	iter.New(append(prefix.Start, start...), prefix.Limit)
+	for iter.Next() {
+		var pl *PRepCandidateLog
+		pl, err = NewPRepCandidateLogFromBytes(iter.Key()[len(db.PrefixPRepCandidateLog):], iter.Value())
+		if err != nil {
+			break
+		}
+		logs = append(logs, pl)
+	}
+	iter.Release()
+	if err != nil {
+		return logs, err
+	}
+	if err = iter.Error(); err != nil {
+		log.Printf("There is error while load P-Rep candidate log iteration. %+v", err)
+		return logs, err
+	}
+
+	return logs, nil
+}
+
+// prunePRepCandidateLogs deletes P-Rep candidate changes at and below blockHeight which rollback can't reach
+func prunePRepCandidateLogs(mngDB db.Database, blockHeight uint64) error {
+	iter, err := mngDB.GetIterator()
+	if err != nil {
+		return err
+	}
+	if iter == nil {
+		return nil
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixPRepCandidateLog))
+	limit := (&PRepCandidateLog{BlockHeight: blockHeight + 1}).ID()
+	keys := make([][]byte, 0)
+	iter.New(prefix.Start, append([]byte(db.PrefixPRepCandidateLog), limit...))
+	for iter.Next() {
+		key := make([]byte, PRepCandidateLogIDSize)
+		copy(key, iter.Key()[len(db.PrefixPRepCandidateLog):])
+		keys = append(keys, key)
+	}
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		return err
+	}
+
+	bucket, _ := mngDB.GetBucket(db.PrefixPRepCandidateLog)
+	for _, key := range keys {
+		if err = bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackPRepCandidate reverts P-Rep candidate changes above blockHeight in reverse order
+func (ctx *Context) rollbackPRepCandidate(blockHeight uint64) error {
+	mngDB := ctx.DB.management
+	logs, err := loadPRepCandidateLogs(mngDB, blockHeight)
+	if err != nil {
+		return err
+	}
+
+	bucket, _ := mngDB.GetBucket(db.PrefixPRepCandidate)
+	logBucket, _ := mngDB.GetBucket(db.PrefixPRepCandidateLog)
+	for i := len(logs) - 1; i >= 0; i-- {
+		pl := logs[i]
+		if pl.Exist {
+			p := &PRepCandidate{Address: pl.Address, PRepCandidateData: pl.PRepCandidateData}
+			ctx.PRepCandidates[p.Address] = p
+			data, _ := p.Bytes()
+			bucket.Set(p.ID(), data)
+		} else {
+			delete(ctx.PRepCandidates, pl.Address)
+			bucket.Delete(pl.Address.Bytes())
+		}
+		log.Printf("P-Rep : rollback '%s' changed at %d", pl.Address.String(), pl.BlockHeight)
+		if err = logBucket.Delete(pl.ID()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
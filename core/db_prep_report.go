@@ -0,0 +1,386 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const PRepReportDBName = "prep_report"
+
+type PRepReportData struct {
+	// Beta3 paid to delegators of P-Rep
+	Beta3 common.HexInt
+	// the number of delegators who get Beta3
+	Delegators uint64
+	// the number of delegators excluded by MinDelegation
+	Excluded uint64
+}
+
+// PRepReport has delegation reward of P-Rep calculated at BlockHeight
+type PRepReport struct {
+	BlockHeight uint64
+	Address     common.Address
+	PRepReportData
+}
+
+func (pr *PRepReport) ID() []byte {
+	return pRepReportKey(pr.BlockHeight, pr.Address.Bytes())
+}
+
+func (pr *PRepReport) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&pr.PRepReportData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (pr *PRepReport) String() string {
+	b, err := json.Marshal(pr)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (pr *PRepReport) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &pr.PRepReportData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewPRepReportFromBytes makes PRepReport with key without bucket prefix and value
+func NewPRepReportFromBytes(key []byte, value []byte) (*PRepReport, error) {
+	pr := new(PRepReport)
+	if err := pr.SetBytes(value); err != nil {
+		return nil, err
+	}
+	pr.BlockHeight = common.BytesToUint64(key[:8])
+	pr.Address = *common.NewAddress(key[8:])
+	return pr, nil
+}
+
+// key of P-Rep report is fixed size block height and P-Rep address to iterate P-Reps of a calculation
+func pRepReportKey(blockHeight uint64, address []byte) []byte {
+	return append(rewardTermKey(blockHeight), address...)
+}
+
+// PRepReportIteratorPrefix returns prefix to iterate P-Rep reports calculated at blockHeight
+func PRepReportIteratorPrefix(blockHeight uint64) *util.Range {
+	return util.BytesPrefix(append([]byte(db.PrefixPRepReport), rewardTermKey(blockHeight)...))
+}
+
+// pRepDelegation is key of delegation reward entry in pRepReporter
+type pRepDelegation struct {
+	pRep      common.Address
+	delegator common.Address
+}
+
+type pRepReportEntry struct {
+	beta3 big.Int
+	// positive if delegation is less than MinDelegation at calculation block height
+	excluded int64
+}
+
+// pRepReporter aggregates delegation rewards of delegators to P-Reps in a calculation with account DB index.
+// Entries of an account DB index are updated by the goroutine for the account DB only.
+// All methods do nothing with nil pRepReporter
+type pRepReporter struct {
+	entries []map[pRepDelegation]*pRepReportEntry
+}
+
+func newPRepReporter(dbCount int) *pRepReporter {
+	r := &pRepReporter{entries: make([]map[pRepDelegation]*pRepReportEntry, dbCount)}
+	for i := range r.entries {
+		r.entries[i] = make(map[pRepDelegation]*pRepReportEntry)
+	}
+	return r
+}
+
+// recorder returns function to record delegations of delegator for calculateIScore.
+// sign is -1 when I-Score of delegations is subtracted. It returns nil with nil pRepReporter
+func (r *pRepReporter) recorder(index int, delegator common.Address,
+	sign int64) func(pRep common.Address, reward *common.HexInt) {
+	if r == nil {
+		return nil
+	}
+	return func(pRep common.Address, reward *common.HexInt) {
+		key := pRepDelegation{pRep: pRep, delegator: delegator}
+		entry, ok := r.entries[index][key]
+		if !ok {
+			entry = new(pRepReportEntry)
+			r.entries[index][key] = entry
+		}
+		if reward == nil {
+			entry.excluded += sign
+		} else if sign < 0 {
+			entry.beta3.Sub(&entry.beta3, &reward.Int)
+		} else {
+			entry.beta3.Add(&entry.beta3, &reward.Int)
+		}
+	}
+}
+
+// merge sums up delegation rewards of each P-Rep.
+// Delegators who get Beta3 and delegators excluded by MinDelegation without Beta3 are counted
+func (r *pRepReporter) merge(blockHeight uint64) []*PRepReport {
+	merged := make(map[common.Address]*PRepReport)
+	for _, entries := range r.entries {
+		for key, entry := range entries {
+			counted := entry.beta3.Sign() > 0
+			excluded := !counted && entry.excluded > 0
+			if !counted && !excluded {
+				continue
+			}
+
+			pr, ok := merged[key.pRep]
+			if !ok {
+				pr = &PRepReport{BlockHeight: blockHeight, Address: key.pRep}
+				merged[key.pRep] = pr
+			}
+			if counted {
+				pr.Beta3.Add(&pr.Beta3.Int, &entry.beta3)
+				pr.Delegators++
+			} else {
+				pr.Excluded++
+			}
+		}
+	}
+
+	reports := make([]*PRepReport, 0, len(merged))
+	for _, pr := range merged {
+		reports = append(reports, pr)
+	}
+	return reports
+}
+
+// write writes P-Rep reports and calculation block height to P-Rep report DB.
+// Calculation block height is written at last, so readers see reports of completely written calculation.
+func (r *pRepReporter) write(prDB db.Database, blockHeight uint64) error {
+	reports := r.merge(blockHeight)
+
+	batch, err := prDB.GetBatch()
+	if err != nil {
+		return err
+	}
+	batch.New()
+	for _, pr := range reports {
+		bs, err := pr.Bytes()
+		if err != nil {
+			return err
+		}
+		batch.Set(append([]byte(db.PrefixPRepReport), pr.ID()...), bs)
+
+		if batch.Len() >= writeBatchCount {
+			if err = batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if batch.Len() > 0 {
+		if err = batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+
+	bucket, _ := prDB.GetBucket(db.PrefixPRepReportTerm)
+	return bucket.Set(rewardTermKey(blockHeight), common.Uint64ToBytes(uint64(len(reports))))
+}
+
+// LoadPRepReportTerms returns calculation block heights in P-Rep report DB in ascending order
+func LoadPRepReportTerms(prDB db.Database) ([]uint64, error) {
+	blockHeights := make([]uint64, 0)
+
+	iter, err := prDB.GetIterator()
+	if err != nil {
+		return blockHeights, err
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixPRepReportTerm))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		blockHeights = append(blockHeights, common.BytesToUint64(iter.Key()[len(db.PrefixPRepReportTerm):]))
+	}
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		log.Printf("There is error while load P-Rep report iteration. %+v", err)
+		return blockHeights, err
+	}
+
+	sort.Slice(blockHeights, func(i, j int) bool {
+		return blockHeights[i] < blockHeights[j]
+	})
+
+	return blockHeights, nil
+}
+
+// ReadPRepReports reads P-Rep reports calculated at blockHeight in P-Rep address order
+func ReadPRepReports(prDB db.Database, blockHeight uint64) ([]*PRepReport, error) {
+	reports := make([]*PRepReport, 0)
+
+	iter, err := prDB.GetIterator()
+	if err != nil {
+		return reports, err
+	}
+
+	prefix := PRepReportIteratorPrefix(blockHeight)
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		var pr *PRepReport
+		pr, err = NewPRepReportFromBytes(iter.Key()[len(db.PrefixPRepReport):], iter.Value())
+		if err != nil {
+			break
+		}
+		reports = append(reports, pr)
+	}
+	iter.Release()
+	if err != nil {
+		return reports, err
+	}
+	if err = iter.Error(); err != nil {
+		return reports, err
+	}
+
+	return reports, nil
+}
+
+// ReadPRepReportDB reads P-Rep reports calculated at blockHeight from P-Rep report DB in dbRoot.
+// It reads reports of the latest calculation if blockHeight is 0
+func ReadPRepReportDB(dbRoot string, blockHeight uint64) (uint64, []*PRepReport, error) {
+	dbRoot = filepath.Clean(dbRoot)
+	backend, err := ReadDBBackend(dbRoot)
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err = os.Stat(filepath.Join(dbRoot, PRepReportDBName)); err != nil {
+		return 0, nil, err
+	}
+	prDB := db.Open(dbRoot, backend, PRepReportDBName)
+	defer prDB.Close()
+
+	if blockHeight == 0 {
+		terms, err := LoadPRepReportTerms(prDB)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(terms) == 0 {
+			return 0, nil, nil
+		}
+		blockHeight = terms[len(terms)-1]
+	}
+
+	reports, err := ReadPRepReports(prDB, blockHeight)
+	return blockHeight, reports, err
+}
+
+// DeletePRepReport deletes calculation block height and P-Rep reports calculated at blockHeight
+func DeletePRepReport(prDB db.Database, blockHeight uint64) error {
+	bucket, _ := prDB.GetBucket(db.PrefixPRepReportTerm)
+	if err := bucket.Delete(rewardTermKey(blockHeight)); err != nil {
+		return err
+	}
+
+	reports, err := ReadPRepReports(prDB, blockHeight)
+	if err != nil {
+		return err
+	}
+	bucket, _ = prDB.GetBucket(db.PrefixPRepReport)
+	for _, pr := range reports {
+		if err = bucket.Delete(pr.ID()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetPRepReports opens P-Rep report DB and keeps P-Rep reports of the latest count calculations.
+// P-Rep report is disabled if count is 0
+func (idb *IScoreDB) SetPRepReports(count int) {
+	if count <= 0 {
+		return
+	}
+	idb.pRepReports = count
+	if idb.pRepReport == nil {
+		idb.pRepReport = db.Open(idb.info.DBRoot, idb.info.DBType, PRepReportDBName)
+	}
+}
+
+func (idb *IScoreDB) getPRepReportDB() db.Database {
+	return idb.pRepReport
+}
+
+// newPRepReporter returns nil if P-Rep report is disabled
+func (idb *IScoreDB) newPRepReporter() *pRepReporter {
+	if idb.pRepReport == nil {
+		return nil
+	}
+	return newPRepReporter(idb.info.DBCount)
+}
+
+// writePRepReport writes P-Rep reports of calculation and deletes reports of old calculations
+func (idb *IScoreDB) writePRepReport(blockHeight uint64, r *pRepReporter) error {
+	prDB := idb.getPRepReportDB()
+	if prDB == nil || r == nil {
+		return nil
+	}
+
+	// delete reports of interrupted calculation
+	if err := DeletePRepReport(prDB, blockHeight); err != nil {
+		return err
+	}
+
+	if err := r.write(prDB, blockHeight); err != nil {
+		return err
+	}
+
+	terms, err := LoadPRepReportTerms(prDB)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(terms)-idb.pRepReports; i++ {
+		if err = DeletePRepReport(prDB, terms[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackPRepReport deletes P-Rep reports of calculations above rollback block height
+func (idb *IScoreDB) rollbackPRepReport(blockHeight uint64) error {
+	prDB := idb.getPRepReportDB()
+	if prDB == nil {
+		return nil
+	}
+
+	terms, err := LoadPRepReportTerms(prDB)
+	if err != nil {
+		return err
+	}
+	for _, bh := range terms {
+		if bh <= blockHeight {
+			continue
+		}
+		if err = DeletePRepReport(prDB, bh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"os"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBPRepReport_CalculateIISSTX(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetPRepReports(2)
+
+	// set GV
+	gv := new(GovernanceVariable)
+	gv.BlockHeight = 0
+	gv.MainPRepCount.SetUint64(NumMainPRep)
+	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.CalculatedIncentiveRep.SetUint64(1)
+	gv.RewardRep.SetUint64(minRewardRep)
+	gv.setReward()
+	ctx.GV = append(ctx.GV, gv)
+
+	// set P-Rep candidate
+	prepA := &PRepCandidate{Address: *common.NewAddressFromString("hxaa")}
+	ctx.PRepCandidates[prepA.Address] = prepA
+	prepB := &PRepCandidate{Address: *common.NewAddressFromString("hxbb")}
+	ctx.PRepCandidates[prepB.Address] = prepB
+
+	// write IISS TX
+	iissDBDir := testDBDir + "/iiss"
+	iissDB := db.Open(iissDBDir, string(db.GoLevelDBBackend), testDB)
+	defer iissDB.Close()
+	defer os.RemoveAll(iissDBDir)
+	iconist := *common.NewAddressFromString("hx11")
+	small := *common.NewAddressFromString("hx22")
+	txList := make([]*IISSTX, 0)
+
+	// TX 0: iconist delegates MinDelegation to prepA and 2 * MinDelegation to prepB at block height 10
+	tx := makeIISSTX(TXDataTypeDelegate, iconist.String(), []DelegateData{
+		{prepA.Address, *common.NewHexIntFromUint64(MinDelegation)},
+		{prepB.Address, *common.NewHexIntFromUint64(MinDelegation * 2)},
+	})
+	tx.Index = 0
+	tx.BlockHeight = 10
+	txList = append(txList, tx)
+
+	// TX 1: iconist moves delegation of prepB to iconist at block height 20
+	tx = makeIISSTX(TXDataTypeDelegate, iconist.String(), []DelegateData{
+		{prepA.Address, *common.NewHexIntFromUint64(MinDelegation)},
+		{iconist, *common.NewHexIntFromUint64(MinDelegation)},
+	})
+	tx.Index = 1
+	tx.BlockHeight = 20
+	txList = append(txList, tx)
+
+	// TX 2: iconist deletes delegation at block height 30
+	tx = makeIISSTX(TXDataTypeDelegate, iconist.String(), nil)
+	tx.Index = 2
+	tx.BlockHeight = 30
+	txList = append(txList, tx)
+
+	// TX 3: small delegation to prepA is excluded by MinDelegation
+	tx = makeIISSTX(TXDataTypeDelegate, small.String(), []DelegateData{
+		{prepA.Address, *common.NewHexIntFromUint64(MinDelegation - 1)},
+	})
+	tx.Index = 3
+	tx.BlockHeight = 40
+	txList = append(txList, tx)
+
+	writeTX(iissDB, txList)
+
+	// calculate IISS TX with P-Rep report
+	ctx.pRepReport = ctx.DB.newPRepReporter()
+	calculateIISSTX(ctx, iissDB, 100, false)
+	assert.NoError(t, ctx.DB.writePRepReport(100, ctx.pRepReport))
+	ctx.pRepReport = nil
+
+	reports, err := ReadPRepReports(ctx.DB.getPRepReportDB(), 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(reports))
+	for _, pr := range reports {
+		assert.Equal(t, uint64(100), pr.BlockHeight)
+		switch pr.Address {
+		case prepA.Address:
+			assert.Equal(t, uint64(MinDelegation*(30-10)*minRewardRep/rewardDivider), pr.Beta3.Uint64())
+			assert.Equal(t, uint64(1), pr.Delegators)
+			assert.Equal(t, uint64(1), pr.Excluded)
+		case prepB.Address:
+			assert.Equal(t, uint64(2*MinDelegation*(20-10)*minRewardRep/rewardDivider), pr.Beta3.Uint64())
+			assert.Equal(t, uint64(1), pr.Delegators)
+			assert.Equal(t, uint64(0), pr.Excluded)
+		default:
+			t.Errorf("invalid P-Rep report %s", pr.String())
+		}
+	}
+}
+
+func TestDBPRepReport_WriteAndPrune(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	// disabled
+	assert.Nil(t, ctx.DB.newPRepReporter())
+	assert.NoError(t, ctx.DB.writePRepReport(10, nil))
+
+	ctx.DB.SetPRepReports(2)
+	pRep := *common.NewAddressFromString("hxaa")
+	for _, bh := range []uint64{10, 20, 30} {
+		r := ctx.DB.newPRepReporter()
+		record := r.recorder(0, *common.NewAddressFromString("hx11"), 1)
+		record(pRep, common.NewHexIntFromUint64(bh))
+		assert.NoError(t, ctx.DB.writePRepReport(bh, r))
+	}
+
+	prDB := ctx.DB.getPRepReportDB()
+	terms, err := LoadPRepReportTerms(prDB)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{20, 30}, terms)
+	reports, err := ReadPRepReports(prDB, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(reports))
+
+	// rollback deletes reports of calculations above rollback block height
+	assert.NoError(t, ctx.DB.rollbackPRepReport(25))
+	terms, err = LoadPRepReportTerms(prDB)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{20}, terms)
+	reports, err = ReadPRepReports(prDB, 30)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(reports))
+	reports, err = ReadPRepReports(prDB, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, uint64(20), reports[0].Beta3.Uint64())
+	assert.Equal(t, uint64(1), reports[0].Delegators)
+}
@@ -0,0 +1,373 @@
+package core
+
+import (
+	"encoding/json"
+	"log"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const RewardBreakdownDBName = "reward_breakdown"
+
+type RewardBreakdownData struct {
+	Beta1 common.HexInt
+	Beta2 common.HexInt
+	Beta3 common.HexInt
+}
+
+// RewardBreakdown has I-Score of account calculated at BlockHeight. I-Score is the reward of the calculation
+// term, not the total I-Score of account.
+type RewardBreakdown struct {
+	BlockHeight uint64
+	Address     common.Address
+	RewardBreakdownData
+}
+
+func (rb *RewardBreakdown) ID() []byte {
+	return rewardBreakdownKey(rb.BlockHeight, rb.Address.Bytes())
+}
+
+func (rb *RewardBreakdown) Bytes() ([]byte, error) {
+	var bytes []byte
+	if bs, err := codec.MarshalToBytes(&rb.RewardBreakdownData); err != nil {
+		return nil, err
+	} else {
+		bytes = bs
+	}
+	return bytes, nil
+}
+
+func (rb *RewardBreakdown) String() string {
+	b, err := json.Marshal(rb)
+	if err != nil {
+		return "Can't covert Message to json"
+	}
+	return string(b)
+}
+
+func (rb *RewardBreakdown) SetBytes(bs []byte) error {
+	_, err := codec.UnmarshalFromBytes(bs, &rb.RewardBreakdownData)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// IScore returns sum of Beta1, Beta2 and Beta3
+func (rb *RewardBreakdown) IScore() *common.HexInt {
+	iScore := new(common.HexInt)
+	iScore.Add(&rb.Beta1.Int, &rb.Beta2.Int)
+	iScore.Add(&iScore.Int, &rb.Beta3.Int)
+	return iScore
+}
+
+// NewRewardBreakdownFromBytes makes RewardBreakdown with key without bucket prefix and value
+func NewRewardBreakdownFromBytes(key []byte, value []byte) (*RewardBreakdown, error) {
+	rb := new(RewardBreakdown)
+	if err := rb.SetBytes(value); err != nil {
+		return nil, err
+	}
+	rb.BlockHeight = common.BytesToUint64(key[:8])
+	rb.Address = *common.NewAddress(key[8:])
+	return rb, nil
+}
+
+// key of reward breakdown is fixed size block height and address to iterate accounts of a calculation
+func rewardBreakdownKey(blockHeight uint64, address []byte) []byte {
+	key := rewardTermKey(blockHeight)
+	return append(key, address...)
+}
+
+func rewardTermKey(blockHeight uint64) []byte {
+	key := make([]byte, 8)
+	bh := common.Uint64ToBytes(blockHeight)
+	copy(key[len(key)-len(bh):], bh)
+	return key
+}
+
+// RewardBreakdownIteratorPrefix returns prefix to iterate rewards of accounts calculated at blockHeight
+func RewardBreakdownIteratorPrefix(blockHeight uint64) *util.Range {
+	return util.BytesPrefix(append([]byte(db.PrefixRewardBreakdown), rewardTermKey(blockHeight)...))
+}
+
+// rewardRecorder keeps rewards of accounts in a calculation with account DB index.
+// Rewards of an account DB index are updated by the goroutine for the account DB only.
+// All methods do nothing with nil rewardRecorder
+type rewardRecorder struct {
+	accounts []map[common.Address]*RewardBreakdownData
+}
+
+func newRewardRecorder(dbCount int) *rewardRecorder {
+	rr := &rewardRecorder{accounts: make([]map[common.Address]*RewardBreakdownData, dbCount)}
+	for i := range rr.accounts {
+		rr.accounts[i] = make(map[common.Address]*RewardBreakdownData)
+	}
+	return rr
+}
+
+func (rr *rewardRecorder) get(index int, address common.Address) *RewardBreakdownData {
+	data, ok := rr.accounts[index][address]
+	if !ok {
+		data = new(RewardBreakdownData)
+		rr.accounts[index][address] = data
+	}
+	return data
+}
+
+func (rr *rewardRecorder) addBeta1(index int, address common.Address, reward *big.Int) {
+	if rr == nil {
+		return
+	}
+	data := rr.get(index, address)
+	data.Beta1.Add(&data.Beta1.Int, reward)
+}
+
+func (rr *rewardRecorder) addBeta2(index int, address common.Address, reward *big.Int) {
+	if rr == nil {
+		return
+	}
+	data := rr.get(index, address)
+	data.Beta2.Add(&data.Beta2.Int, reward)
+}
+
+func (rr *rewardRecorder) addBeta3(index int, address common.Address, reward *big.Int) {
+	if rr == nil {
+		return
+	}
+	data := rr.get(index, address)
+	data.Beta3.Add(&data.Beta3.Int, reward)
+}
+
+func (rr *rewardRecorder) count() int {
+	count := 0
+	for _, accounts := range rr.accounts {
+		count += len(accounts)
+	}
+	return count
+}
+
+// write writes rewards of accounts and calculation block height to reward breakdown DB.
+// Calculation block height is written at last, so readers see rewards of completely written calculation.
+func (rr *rewardRecorder) write(rbDB db.Database, blockHeight uint64) error {
+	batch, err := rbDB.GetBatch()
+	if err != nil {
+		return err
+	}
+	batch.New()
+
+	for _, accounts := range rr.accounts {
+		for address, data := range accounts {
+			rb := RewardBreakdown{BlockHeight: blockHeight, Address: address, RewardBreakdownData: *data}
+			bs, err := rb.Bytes()
+			if err != nil {
+				return err
+			}
+			batch.Set(append([]byte(db.PrefixRewardBreakdown), rb.ID()...), bs)
+
+			if batch.Len() >= writeBatchCount {
+				if err = batch.Write(); err != nil {
+					return err
+				}
+				batch.Reset()
+			}
+		}
+	}
+	if batch.Len() > 0 {
+		if err = batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+
+	bucket, _ := rbDB.GetBucket(db.PrefixRewardTerm)
+	return bucket.Set(rewardTermKey(blockHeight), common.Uint64ToBytes(uint64(rr.count())))
+}
+
+// LoadRewardTerms returns calculation block heights in reward breakdown DB in ascending order
+func LoadRewardTerms(rbDB db.Database) ([]uint64, error) {
+	blockHeights := make([]uint64, 0)
+
+	iter, err := rbDB.GetIterator()
+	if err != nil {
+		return blockHeights, err
+	}
+
+	prefix := util.BytesPrefix([]byte(db.PrefixRewardTerm))
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		blockHeights = append(blockHeights, common.BytesToUint64(iter.Key()[len(db.PrefixRewardTerm):]))
+	}
+	iter.Release()
+	if err = iter.Error(); err != nil {
+		log.Printf("There is error while load reward breakdown iteration. %+v", err)
+		return blockHeights, err
+	}
+
+	sort.Slice(blockHeights, func(i, j int) bool {
+		return blockHeights[i] < blockHeights[j]
+	})
+
+	return blockHeights, nil
+}
+
+// ReadRewardBreakdowns reads rewards of address calculated at blockHeight.
+// It reads rewards of all calculations in reward breakdown DB if blockHeight is 0
+func ReadRewardBreakdowns(rbDB db.Database, address common.Address, blockHeight uint64) ([]*RewardBreakdown, error) {
+	rbList := make([]*RewardBreakdown, 0)
+
+	terms, err := LoadRewardTerms(rbDB)
+	if err != nil {
+		return rbList, err
+	}
+
+	bucket, _ := rbDB.GetBucket(db.PrefixRewardBreakdown)
+	for _, bh := range terms {
+		if blockHeight != 0 && blockHeight != bh {
+			continue
+		}
+		key := rewardBreakdownKey(bh, address.Bytes())
+		bs, err := bucket.Get(key)
+		if err != nil {
+			return rbList, err
+		}
+		if bs == nil {
+			continue
+		}
+		rb, err := NewRewardBreakdownFromBytes(key, bs)
+		if err != nil {
+			return rbList, err
+		}
+		rbList = append(rbList, rb)
+	}
+
+	return rbList, nil
+}
+
+// DeleteRewardBreakdown deletes calculation block height and rewards of accounts calculated at blockHeight
+func DeleteRewardBreakdown(rbDB db.Database, blockHeight uint64) error {
+	bucket, _ := rbDB.GetBucket(db.PrefixRewardTerm)
+	if err := bucket.Delete(rewardTermKey(blockHeight)); err != nil {
+		return err
+	}
+
+	iter, err := rbDB.GetIterator()
+	if err != nil {
+		return err
+	}
+	batch, err := rbDB.GetBatch()
+	if err != nil {
+		return err
+	}
+	batch.New()
+
+	prefix := RewardBreakdownIteratorPrefix(blockHeight)
+	iter.New(prefix.Start, prefix.Limit)
+	for iter.Next() {
+		key := make([]byte, len(iter.Key()))
+		copy(key, iter.Key())
+		batch.Delete(key)
+
+		if batch.Len() >= writeBatchCount {
+			if err = batch.Write(); err != nil {
+				break
+			}
+			batch.Reset()
+		}
+	}
+	iter.Release()
+	if err != nil {
+		return err
+	}
+	if err = iter.Error(); err != nil {
+		return err
+	}
+	if batch.Len() > 0 {
+		if err = batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+
+	return nil
+}
+
+// SetRewardBreakdowns opens reward breakdown DB and keeps rewards of accounts in the latest count calculations.
+// Reward breakdown is disabled if count is 0
+func (idb *IScoreDB) SetRewardBreakdowns(count int) {
+	if count <= 0 {
+		return
+	}
+	idb.breakdowns = count
+	if idb.rewardBreakdown == nil {
+		idb.rewardBreakdown = db.Open(idb.info.DBRoot, idb.info.DBType, RewardBreakdownDBName)
+	}
+}
+
+func (idb *IScoreDB) getRewardBreakdownDB() db.Database {
+	return idb.rewardBreakdown
+}
+
+// newRewardRecorder returns nil if reward breakdown is disabled
+func (idb *IScoreDB) newRewardRecorder() *rewardRecorder {
+	if idb.rewardBreakdown == nil {
+		return nil
+	}
+	return newRewardRecorder(idb.info.DBCount)
+}
+
+// writeRewardBreakdown writes rewards of calculation and deletes rewards of old calculations
+func (idb *IScoreDB) writeRewardBreakdown(blockHeight uint64, rr *rewardRecorder) error {
+	rbDB := idb.getRewardBreakdownDB()
+	if rbDB == nil || rr == nil {
+		return nil
+	}
+
+	// delete rewards of interrupted calculation
+	if err := DeleteRewardBreakdown(rbDB, blockHeight); err != nil {
+		return err
+	}
+
+	if err := rr.write(rbDB, blockHeight); err != nil {
+		return err
+	}
+
+	terms, err := LoadRewardTerms(rbDB)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(terms)-idb.breakdowns; i++ {
+		if err = DeleteRewardBreakdown(rbDB, terms[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rollbackRewardBreakdown deletes rewards of calculations above rollback block height
+func (idb *IScoreDB) rollbackRewardBreakdown(blockHeight uint64) error {
+	rbDB := idb.getRewardBreakdownDB()
+	if rbDB == nil {
+		return nil
+	}
+
+	terms, err := LoadRewardTerms(rbDB)
+	if err != nil {
+		return err
+	}
+	for _, bh := range terms {
+		if bh <= blockHeight {
+			continue
+		}
+		if err = DeleteRewardBreakdown(rbDB, bh); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
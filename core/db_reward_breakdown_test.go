@@ -0,0 +1,98 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+var rewardBreakdownTestAddress = common.NewAddressFromString("hx11")
+
+// writeRewardBreakdownTest emulates calculation which gives Beta1, Beta2 and Beta3 to rewardBreakdownTestAddress
+func writeRewardBreakdownTest(t *testing.T, ctx *Context, blockHeight uint64) {
+	rr := ctx.DB.newRewardRecorder()
+	assert.NotNil(t, rr)
+
+	index := ctx.DB.getAccountDBIndex(*rewardBreakdownTestAddress)
+	rr.addBeta1(index, *rewardBreakdownTestAddress, big.NewInt(int64(blockHeight)))
+	rr.addBeta2(index, *rewardBreakdownTestAddress, big.NewInt(int64(blockHeight*2)))
+	rr.addBeta3(index, *rewardBreakdownTestAddress, big.NewInt(int64(blockHeight*3)))
+	// I-Score of old delegation is subtracted in IISS TX phase
+	rr.addBeta3(index, *rewardBreakdownTestAddress, big.NewInt(-int64(blockHeight)))
+
+	other := common.NewAddressFromString("hx22")
+	rr.addBeta3(ctx.DB.getAccountDBIndex(*other), *other, big.NewInt(1))
+
+	assert.NoError(t, ctx.DB.writeRewardBreakdown(blockHeight, rr))
+}
+
+func assertRewardBreakdown(t *testing.T, ctx *Context, blockHeights []uint64) {
+	resp := DoQueryRewardBreakdown(ctx, *rewardBreakdownTestAddress, 0)
+	assert.Equal(t, RewardBreakdownStatusOK, resp.Status)
+	assert.Equal(t, len(blockHeights), len(resp.Rewards))
+	for i, bh := range blockHeights {
+		if i >= len(resp.Rewards) {
+			break
+		}
+		rt := resp.Rewards[i]
+		assert.Equal(t, bh, rt.BlockHeight)
+		assert.Equal(t, 0, rt.Beta1.Cmp(&common.NewHexIntFromUint64(bh).Int))
+		assert.Equal(t, 0, rt.Beta2.Cmp(&common.NewHexIntFromUint64(bh*2).Int))
+		assert.Equal(t, 0, rt.Beta3.Cmp(&common.NewHexIntFromUint64(bh*2).Int))
+		assert.Equal(t, 0, rt.IScore.Cmp(&common.NewHexIntFromUint64(bh*5).Int))
+	}
+}
+
+func TestDBRewardBreakdown_Disabled(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	assert.Nil(t, ctx.DB.newRewardRecorder())
+	assert.NoError(t, ctx.DB.writeRewardBreakdown(10, nil))
+
+	resp := DoQueryRewardBreakdown(ctx, *rewardBreakdownTestAddress, 0)
+	assert.Equal(t, RewardBreakdownStatusDisabled, resp.Status)
+}
+
+func TestDBRewardBreakdown_WriteAndPrune(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetRewardBreakdowns(3)
+
+	for _, bh := range []uint64{10, 20, 30, 40} {
+		writeRewardBreakdownTest(t, ctx, bh)
+	}
+
+	// rewards of the latest 3 calculations
+	terms, err := LoadRewardTerms(ctx.DB.getRewardBreakdownDB())
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{20, 30, 40}, terms)
+	assertRewardBreakdown(t, ctx, []uint64{20, 30, 40})
+
+	// query with calculation block height
+	resp := DoQueryRewardBreakdown(ctx, *rewardBreakdownTestAddress, 30)
+	assert.Equal(t, 1, len(resp.Rewards))
+	assert.Equal(t, uint64(30), resp.Rewards[0].BlockHeight)
+	resp = DoQueryRewardBreakdown(ctx, *rewardBreakdownTestAddress, 10)
+	assert.Equal(t, RewardBreakdownStatusOK, resp.Status)
+	assert.Equal(t, 0, len(resp.Rewards))
+
+	// no reward
+	resp = DoQueryRewardBreakdown(ctx, *common.NewAddressFromString("hx33"), 0)
+	assert.Equal(t, 0, len(resp.Rewards))
+
+	// rewrite interrupted calculation
+	writeRewardBreakdownTest(t, ctx, 40)
+	assertRewardBreakdown(t, ctx, []uint64{20, 30, 40})
+
+	// rollback deletes rewards of calculations above rollback block height
+	assert.NoError(t, ctx.DB.rollbackRewardBreakdown(35))
+	assertRewardBreakdown(t, ctx, []uint64{20, 30})
+	iter, _ := ctx.DB.getRewardBreakdownDB().GetIterator()
+	prefix := RewardBreakdownIteratorPrefix(40)
+	iter.New(prefix.Start, prefix.Limit)
+	assert.False(t, iter.Next())
+	iter.Release()
+}
@@ -1,33 +1,59 @@
 package core
 
 import (
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/icon-project/rewardcalculator/common/db"
 	"github.com/icon-project/rewardcalculator/common/ipc"
 	"log"
 	"math"
+	"net/http"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	DebugAddress = "/tmp/.icon-rc-monitor.sock"
 )
 
+// delay of reconnection in client mode. It is doubled on every failure up to reconnectMaxDelay
+var (
+	reconnectMinDelay = 500 * time.Millisecond
+	reconnectMaxDelay = 30 * time.Second
+)
+
+var errManagerClosed = errors.New("manager closed")
+
 type RcConfig struct {
-	IISSDataDir   string `json:"IISSData"`
-	DBDir         string `json:"IScoreDB"`
-	IpcNet        string `json:"IPCNet"`
-	IpcAddr       string `json:"IPCAddress"`
-	ClientMode    bool   `json:"ClientMode"`
-	DBCount       int    `json:"DBCount"`
-	Monitor       bool   `json:"Monitor"`
-	LogFile       string `json:"LogFile"`
-	LogMaxSize    int    `json:"LogMaxSize"`
-	LogMaxBackups int    `json:"LogMaxBackups"`
-	CalcDebugConf string `json:"CalcDebugConf"`
-	FileName      string
+	IISSDataDir    string `json:"IISSData"`
+	DBDir          string `json:"IScoreDB"`
+	IpcNet         string `json:"IPCNet"`
+	IpcAddr        string `json:"IPCAddress"`
+	ClientMode     bool   `json:"ClientMode"`
+	DBCount        int    `json:"DBCount"`
+	Monitor        bool   `json:"Monitor"`
+	LogFile        string `json:"LogFile"`
+	LogMaxSize     int    `json:"LogMaxSize"`
+	LogMaxBackups  int    `json:"LogMaxBackups"`
+	CalcDebugConf  string `json:"CalcDebugConf"`
+	MetricsAddress string `json:"MetricsAddress"`
+	DBBackend      string `json:"DBBackend"`
+	IISSBackend    string `json:"IISSDataBackend"`
+	IpcCert        string `json:"IPCCertFile"`
+	IpcKey         string `json:"IPCKeyFile"`
+	IpcCA          string `json:"IPCCAFile"`
+	QueryAddr      string `json:"QueryAddress"`
+	DBBackups      int    `json:"AccountDBBackups"`
+	ClaimPeriod    uint64 `json:"ClaimBackupPeriod"`
+	Breakdowns     int    `json:"RewardBreakdowns"`
+	PRepReports    int    `json:"PRepReports"`
+	MerkleProof    bool   `json:"MerkleProof"`
+	CalcDoneAck    bool   `json:"CalculateDoneAck"`
+	FileName       string
 }
 
 func (cfg *RcConfig) Print() {
@@ -50,19 +76,41 @@ type manager struct {
 	monitorMode bool
 	server      ipc.Server
 	conn        ipc.Connection
+	connLock    sync.Mutex
+	handlers    map[ipc.Connection]*msgHandler
+	writer      ipc.Connection
+	connChanged chan struct{}
+	dial        func() (ipc.Connection, error)
+	quit        chan struct{}
+	closeOnce   sync.Once
+	outboxLock  sync.Mutex
+	// keep CALCULATE_DONE in outbox until ACK_CALCULATE_DONE
+	calcDoneAck bool
 
 	ctx       *Context
 	waitGroup *sync.WaitGroup
+
+	metrics *http.Server
+	query   *http.Server
 }
 
 func (m *manager) Loop() error {
 	if m.clientMode {
 		for {
-			err := m.conn.HandleMessage()
-			if err != nil {
-				log.Printf("Failed to handle message err=%+v", err)
-				m.Close()
-				return err
+			conn, _ := m.getConn()
+			err := conn.HandleMessage()
+			if m.isClosed() {
+				return nil
+			}
+			if err == nil {
+				continue
+			}
+			log.Printf("Failed to handle message err=%+v", err)
+
+			// peer may be restarted. reconnect and send READY again
+			m.disconnect(conn)
+			if err = m.reconnect(); err != nil {
+				return nil
 			}
 		}
 	} else {
@@ -71,10 +119,21 @@ func (m *manager) Loop() error {
 }
 
 func (m *manager) Close() error {
+	m.closeOnce.Do(func() { close(m.quit) })
 	m.ctx.CancelCalculation.notifyExit()
 	m.WaitMsgTasksDone()
+	if m.metrics != nil {
+		m.metrics.Close()
+	}
+	if m.query != nil {
+		StopQueryServer(m.query)
+	}
 	if m.clientMode {
-		m.conn.Close()
+		m.connLock.Lock()
+		if m.conn != nil {
+			m.conn.Close()
+		}
+		m.connLock.Unlock()
 	} else {
 		if err := m.server.Close(); err != nil {
 			log.Printf("Failed to close IPC server err=%+v", err)
@@ -88,18 +147,157 @@ func (m *manager) Close() error {
 
 // ConnectionHandler.OnConnect
 func (m *manager) OnConnect(c ipc.Connection) error {
-	_, err := newConnection(m, c)
-	return err
+	// CALCULATE_DONE is not sent while sending READY and CALCULATE_DONE in outbox
+	m.outboxLock.Lock()
+	defer m.outboxLock.Unlock()
+
+	mh, err := newConnection(m, c)
+	if err != nil {
+		return err
+	}
+	m.addConnection(c, mh)
+
+	// connection to server gets CALCULATE_DONE in outbox after READY.
+	// In server mode, it is sent when connection becomes the writer connection
+	if m.clientMode && !m.monitorMode {
+		mh.flushCalculateDone(c)
+	}
+	if m.clientMode {
+		// connection to server is the writer connection
+		m.acquireWriter(c)
+		if !m.setConn(c) {
+			m.OnClose(c)
+			return errManagerClosed
+		}
+	}
+	return nil
 }
 
 // ConnectionHandler.OnClose
 func (m *manager) OnClose(c ipc.Connection) error {
-	// TODO finalize connection
-	// use sync.WaitGroup ?
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
 
+	delete(m.handlers, c)
+	if m.writer == c {
+		m.writer = nil
+		log.Printf("Release writer connection")
+	}
 	return nil
 }
 
+func (m *manager) addConnection(c ipc.Connection, mh *msgHandler) {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+
+	m.handlers[c] = mh
+}
+
+func (m *manager) hasWriter() bool {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+
+	return m.writer != nil
+}
+
+// getWriter returns the writer connection in server mode. It is nil if there is no writer connection
+func (m *manager) getWriter() ipc.Connection {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+
+	return m.writer
+}
+
+// acquireWriter makes c the writer connection if there is no writer connection.
+// Only the writer connection can send messages which modify I-Score DB. Other connections are read-only.
+// It returns true if c is the writer connection and whether c became the writer connection with this call
+func (m *manager) acquireWriter(c ipc.Connection) (writer bool, acquired bool) {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+
+	if m.writer == nil {
+		if _, ok := m.handlers[c]; ok {
+			m.writer = c
+			acquired = true
+			log.Printf("Connection becomes writer connection")
+		}
+	}
+	return m.writer == c, acquired
+}
+
+func (m *manager) isClosed() bool {
+	select {
+	case <-m.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// getConn returns connection to server in client mode and channel closed when connection is changed.
+// connection is nil while reconnecting
+func (m *manager) getConn() (ipc.Connection, <-chan struct{}) {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+	return m.conn, m.connChanged
+}
+
+func (m *manager) setConn(conn ipc.Connection) bool {
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+	if m.isClosed() {
+		return false
+	}
+	m.conn = conn
+	close(m.connChanged)
+	m.connChanged = make(chan struct{})
+	return true
+}
+
+func (m *manager) disconnect(conn ipc.Connection) {
+	m.OnClose(conn)
+	conn.Close()
+
+	m.connLock.Lock()
+	defer m.connLock.Unlock()
+	if m.conn == conn {
+		m.conn = nil
+		close(m.connChanged)
+		m.connChanged = make(chan struct{})
+	}
+}
+
+// reconnect connects to server with backoff until manager is closed.
+// READY message with current block information and CALCULATE_DONE in outbox are sent to server on connection
+func (m *manager) reconnect() error {
+	delay := reconnectMinDelay
+	for {
+		select {
+		case <-m.quit:
+			return errManagerClosed
+		case <-time.After(delay):
+		}
+
+		conn, err := m.dial()
+		if err == nil {
+			if err = m.OnConnect(conn); err == nil {
+				log.Printf("Reconnected to server")
+				return nil
+			}
+			conn.Close()
+			if err == errManagerClosed {
+				return err
+			}
+		}
+
+		log.Printf("Failed to reconnect to server. retry after %v. err=%+v", delay, err)
+		delay *= 2
+		if delay > reconnectMaxDelay {
+			delay = reconnectMaxDelay
+		}
+	}
+}
+
 func (m *manager) AddMsgTask() {
 	m.waitGroup.Add(1)
 }
@@ -118,32 +316,70 @@ func InitManager(cfg *RcConfig) (*manager, error) {
 	waitGroup := new(sync.WaitGroup)
 	m := new(manager)
 	m.clientMode = cfg.ClientMode
+	m.calcDoneAck = cfg.CalcDoneAck
 	m.waitGroup = waitGroup
+	m.connChanged = make(chan struct{})
+	m.quit = make(chan struct{})
+	m.handlers = make(map[ipc.Connection]*msgHandler)
 
 	// Initialize DB and load context values
-	m.ctx, err = NewContext(cfg.DBDir, string(db.GoLevelDBBackend), "IScore", cfg.DBCount, cfg.CalcDebugConf)
+	if cfg.DBBackend == "" {
+		cfg.DBBackend = string(db.GoLevelDBBackend)
+	}
+	m.ctx, err = NewContext(cfg.DBDir, cfg.DBBackend, "IScore", cfg.DBCount, cfg.CalcDebugConf)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.IISSBackend != "" {
+		m.ctx.IISSDataBackend = cfg.IISSBackend
+	}
+	m.ctx.DB.SetAccountDBBackups(cfg.DBBackups)
+	m.ctx.DB.SetClaimBackupPeriod(cfg.ClaimPeriod)
+	m.ctx.DB.SetRewardBreakdowns(cfg.Breakdowns)
+	m.ctx.DB.SetPRepReports(cfg.PRepReports)
+	m.ctx.DB.SetMerkleProof(cfg.MerkleProof)
+
+	// recover calculation and rollback interrupted by crash
+	if err = recoverJournal(m.ctx); err != nil {
+		log.Printf("Failed to recover I-Score DB. %v", err)
+		return nil, err
+	}
 
+	m.ctx.DB.claimBackupCoversRollback()
 	m.ctx.Print()
 
 	// find IISS data and reload
 	go reloadIISSData(m.ctx, cfg.IISSDataDir)
 
 	// Initialize ipc channel
+	tlsConfig, err := newIPCTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 	if m.clientMode {
 		// connect to server
-		conn, err := ipc.Dial(cfg.IpcNet, cfg.IpcAddr)
+		m.dial = func() (ipc.Connection, error) {
+			if tlsConfig != nil {
+				return ipc.DialTLS(cfg.IpcNet, cfg.IpcAddr, tlsConfig)
+			}
+			return ipc.Dial(cfg.IpcNet, cfg.IpcAddr)
+		}
+		conn, err := m.dial()
 		if err != nil {
 			return nil, err
 		}
-		m.OnConnect(conn)
-		m.conn = conn
+		if err = m.OnConnect(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
 	} else {
 		// IPC Server
 		srv := ipc.NewServer()
-		err = srv.Listen(cfg.IpcNet, cfg.IpcAddr)
+		if tlsConfig != nil {
+			err = srv.ListenTLS(cfg.IpcNet, cfg.IpcAddr, tlsConfig)
+		} else {
+			err = srv.Listen(cfg.IpcNet, cfg.IpcAddr)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -157,6 +393,7 @@ func InitManager(cfg *RcConfig) (*manager, error) {
 		monitor.ctx = m.ctx
 		monitor.monitorMode = true
 		monitor.waitGroup = waitGroup
+		monitor.handlers = make(map[ipc.Connection]*msgHandler)
 
 		srv := ipc.NewServer()
 		err = srv.Listen("unix", DebugAddress)
@@ -169,9 +406,41 @@ func InitManager(cfg *RcConfig) (*manager, error) {
 		go monitor.Loop()
 	}
 
+	// Initialize metrics endpoint
+	if cfg.MetricsAddress != "" {
+		m.metrics, err = StartMetricsServer(m.ctx, cfg.MetricsAddress)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize read-only query endpoint
+	if cfg.QueryAddr != "" {
+		m.query, err = StartQueryServer(m.ctx, cfg.QueryAddr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return m, err
 }
 
+// newIPCTLSConfig returns TLS configuration for IPC channel. It returns nil if certificate is not configured
+func newIPCTLSConfig(cfg *RcConfig) (*tls.Config, error) {
+	if cfg.IpcCert == "" && cfg.IpcKey == "" && cfg.IpcCA == "" {
+		if strings.HasPrefix(cfg.IpcNet, "tcp") {
+			log.Printf("IPC channel %s:%s is not authenticated. Configure certificates to use TLS",
+				cfg.IpcNet, cfg.IpcAddr)
+		}
+		return nil, nil
+	}
+	if cfg.IpcCert == "" || cfg.IpcKey == "" || cfg.IpcCA == "" {
+		return nil, fmt.Errorf("certificate, key and CA certificate are required for IPC TLS")
+	}
+
+	return ipc.NewTLSConfig(cfg.IpcCert, cfg.IpcKey, cfg.IpcCA, !cfg.ClientMode, "")
+}
+
 const reloadBlockHeight = math.MaxUint64
 const reloadMsgID = math.MaxUint32
 
@@ -182,7 +451,9 @@ func reloadIISSData(ctx *Context, dir string) {
 		req.BlockHeight = reloadBlockHeight
 
 		log.Printf("Reload IISS Data. %s", req.Path)
-		err, _, _, _ := DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, reloadMsgID)
+		startTime := time.Now()
+		err, _, stats, _ := DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, reloadMsgID)
+		observeCalculation(time.Since(startTime), stats, err)
 
 		if err != nil {
 			log.Printf("Failed to reload IISS Data. %s. %v", req.Path, err)
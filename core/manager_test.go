@@ -0,0 +1,476 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessage struct {
+	msg  uint
+	data []byte
+}
+
+// testServer plays ICON Service which accepts connection of Reward Calculator in client mode
+type testServer struct {
+	server ipc.Server
+	conns  chan ipc.Connection
+	msgs   chan testMessage
+}
+
+func newTestServer(t *testing.T, address string) *testServer {
+	s := &testServer{
+		server: ipc.NewServer(),
+		conns:  make(chan ipc.Connection, 10),
+		msgs:   make(chan testMessage, 10),
+	}
+	assert.NoError(t, s.server.Listen("unix", address))
+	s.server.SetHandler(s)
+	go s.server.Loop()
+	return s
+}
+
+func (s *testServer) OnConnect(c ipc.Connection) error {
+	c.SetHandler(MsgReady, s)
+	c.SetHandler(MsgCalculateDone, s)
+	c.SetHandler(MsgAckCalculateDone, s)
+	s.conns <- c
+	return nil
+}
+
+func (s *testServer) OnClose(c ipc.Connection) error {
+	return nil
+}
+
+func (s *testServer) HandleMessage(c ipc.Connection, msg uint, id uint32, data []byte) error {
+	s.msgs <- testMessage{msg: msg, data: data}
+	return nil
+}
+
+func (s *testServer) receive(t *testing.T, msg uint, buf interface{}) {
+	select {
+	case m := <-s.msgs:
+		assert.Equal(t, MsgToString(msg), MsgToString(m.msg))
+		if buf != nil {
+			_, err := codec.MP.UnmarshalFromBytes(m.data, buf)
+			assert.NoError(t, err)
+		}
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout", "no %s message", MsgToString(msg))
+	}
+}
+
+func TestManager_ClientModeReconnect(t *testing.T) {
+	minDelay := reconnectMinDelay
+	reconnectMinDelay = 10 * time.Millisecond
+	defer func() { reconnectMinDelay = minDelay }()
+
+	dir, err := ioutil.TempDir("", "manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &RcConfig{
+		IISSDataDir: dir,
+		DBDir:       dir,
+		IpcNet:      "unix",
+		IpcAddr:     filepath.Join(dir, "ipc.sock"),
+		ClientMode:  true,
+		DBCount:     1,
+		DBBackend:   testDBBackend,
+	}
+
+	s := newTestServer(t, cfg.IpcAddr)
+	m, err := InitManager(cfg)
+	assert.NoError(t, err)
+	defer m.Close()
+
+	var ready ResponseVersion
+	s.receive(t, MsgReady, &ready)
+	assert.Equal(t, uint64(0), ready.BlockHeight)
+	go m.Loop()
+
+	// set current block information
+	blockInfo := BlockInfo{BlockHeight: 100}
+	copy(blockInfo.BlockHash[:], "block hash")
+	m.ctx.DB.setCurrentBlockInfo(blockInfo.BlockHeight, blockInfo.BlockHash[:])
+
+	// server restarts
+	conn := <-s.conns
+	s.server.Close()
+	conn.Close()
+
+	// send CALCULATE_DONE while server is down. It is kept in outbox
+	done := CalculateDone{Success: true, BlockHeight: 50}
+	m.notifyCalculateDone(conn, &done)
+
+	// READY with current block information and CALCULATE_DONE after reconnection
+	s = newTestServer(t, cfg.IpcAddr)
+	defer s.server.Close()
+	s.receive(t, MsgReady, &ready)
+	assert.Equal(t, blockInfo.BlockHeight, ready.BlockHeight)
+	assert.Equal(t, blockInfo.BlockHash, ready.BlockHash)
+
+	var received CalculateDone
+	s.receive(t, MsgCalculateDone, &received)
+	assert.True(t, received.Success)
+	assert.Equal(t, done.BlockHeight, received.BlockHeight)
+}
+
+// startClientModeTest starts manager in client mode which connects to test server
+func startClientModeTest(t *testing.T, dir string, calcDoneAck bool) (*manager, *testServer, *RcConfig) {
+	cfg := &RcConfig{
+		IISSDataDir: dir,
+		DBDir:       dir,
+		IpcNet:      "unix",
+		IpcAddr:     filepath.Join(dir, "ipc.sock"),
+		ClientMode:  true,
+		DBCount:     1,
+		DBBackend:   testDBBackend,
+		CalcDoneAck: calcDoneAck,
+	}
+
+	s := newTestServer(t, cfg.IpcAddr)
+	m, err := InitManager(cfg)
+	assert.NoError(t, err)
+
+	var ready ResponseVersion
+	s.receive(t, MsgReady, &ready)
+	go m.Loop()
+	return m, s, cfg
+}
+
+// restartTestServer closes connection and test server and starts test server again
+func restartTestServer(t *testing.T, s *testServer, conn ipc.Connection, cfg *RcConfig) *testServer {
+	s.server.Close()
+	conn.Close()
+	s = newTestServer(t, cfg.IpcAddr)
+	var ready ResponseVersion
+	s.receive(t, MsgReady, &ready)
+	return s
+}
+
+func (s *testServer) receiveNothing(t *testing.T) {
+	select {
+	case msg := <-s.msgs:
+		assert.Fail(t, "unexpected message", "%s", MsgToString(msg.msg))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestManager_CalculateDoneOutbox(t *testing.T) {
+	minDelay := reconnectMinDelay
+	reconnectMinDelay = 10 * time.Millisecond
+	defer func() { reconnectMinDelay = minDelay }()
+
+	dir, err := ioutil.TempDir("", "manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m, s, cfg := startClientModeTest(t, dir, false)
+	defer m.Close()
+	crDB := m.ctx.DB.getCalculateResultDB()
+
+	// CALCULATE_DONE sent to peer is deleted from outbox
+	conn := <-s.conns
+	done := CalculateDone{Success: true, BlockHeight: 50}
+	assert.NoError(t, m.notifyCalculateDone(conn, &done))
+	s.receive(t, MsgCalculateDone, nil)
+	doneList, err := ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	// CALCULATE_DONE while server is down is kept in outbox
+	s.server.Close()
+	conn.Close()
+	done.BlockHeight = 60
+	m.notifyCalculateDone(conn, &done)
+	doneList, err = ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(doneList))
+
+	// and deleted after it is sent to new connection
+	s = newTestServer(t, cfg.IpcAddr)
+	var ready ResponseVersion
+	s.receive(t, MsgReady, &ready)
+	var received CalculateDone
+	s.receive(t, MsgCalculateDone, &received)
+	assert.Equal(t, done.BlockHeight, received.BlockHeight)
+	assert.Eventually(t, func() bool {
+		doneList, err = ReadCalculateDoneOutbox(crDB)
+		return err == nil && len(doneList) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// CALCULATE_DONE is not sent again
+	s = restartTestServer(t, s, <-s.conns, cfg)
+	defer s.server.Close()
+	s.receiveNothing(t)
+}
+
+func TestManager_CalculateDoneAck(t *testing.T) {
+	minDelay := reconnectMinDelay
+	reconnectMinDelay = 10 * time.Millisecond
+	defer func() { reconnectMinDelay = minDelay }()
+
+	dir, err := ioutil.TempDir("", "manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m, s, cfg := startClientModeTest(t, dir, true)
+	defer m.Close()
+	crDB := m.ctx.DB.getCalculateResultDB()
+
+	// CALCULATE_DONE is kept in outbox until peer acknowledges it
+	conn := <-s.conns
+	done := CalculateDone{Success: true, BlockHeight: 50}
+	assert.NoError(t, m.notifyCalculateDone(conn, &done))
+	s.receive(t, MsgCalculateDone, nil)
+
+	s = restartTestServer(t, s, conn, cfg)
+	var received CalculateDone
+	s.receive(t, MsgCalculateDone, &received)
+	assert.True(t, received.Success)
+	assert.Equal(t, done.BlockHeight, received.BlockHeight)
+
+	// acknowledge CALCULATE_DONE
+	conn = <-s.conns
+	assert.NoError(t, conn.Send(MsgAckCalculateDone, 1, &done.BlockHeight))
+	s.receive(t, MsgAckCalculateDone, nil)
+	doneList, err := ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	// CALCULATE_DONE is not sent again after acknowledgement
+	s = restartTestServer(t, s, conn, cfg)
+	defer s.server.Close()
+	s.receiveNothing(t)
+}
+
+func TestManager_WriterConnection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &RcConfig{
+		IISSDataDir: dir,
+		DBDir:       dir,
+		IpcNet:      "unix",
+		IpcAddr:     filepath.Join(dir, "ipc.sock"),
+		DBCount:     1,
+		DBBackend:   testDBBackend,
+	}
+
+	m, err := InitManager(cfg)
+	assert.NoError(t, err)
+	defer m.Close()
+	go m.Loop()
+
+	writer, err := InitRCIPC(cfg.IpcNet, cfg.IpcAddr)
+	assert.NoError(t, err)
+	reader, err := InitRCIPC(cfg.IpcNet, cfg.IpcAddr)
+	assert.NoError(t, err)
+	defer FiniRCIPC(reader)
+
+	// the first connection sending message which modifies I-Score DB becomes writer
+	resp, err := writer.SendInit(0)
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	// other connections are read-only
+	resp, err = reader.SendInit(0)
+	assert.NoError(t, err)
+	assert.False(t, resp.Success)
+
+	calcResp, err := reader.SendCalculate(filepath.Join(dir, "iiss"), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, CalcRespStatusReadOnly, calcResp.Status)
+
+	commitResp, err := reader.SendCommitBlock(true, 10, "")
+	assert.NoError(t, err)
+	assert.False(t, commitResp.Success)
+	assert.Equal(t, uint64(0), m.ctx.DB.getCurrentBlockInfo().BlockHeight)
+
+	version, err := reader.SendVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, IPCVersion, version.Version)
+
+	status, err := reader.SendQueryCalculateStatus()
+	assert.NoError(t, err)
+	assert.NotNil(t, status)
+
+	// release writer connection on close
+	FiniRCIPC(writer)
+	for i := 0; i < 100 && m.hasWriter(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	resp, err = reader.SendInit(0)
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+}
+
+func TestManager_WriterConnectionOutbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &RcConfig{
+		IISSDataDir: dir,
+		DBDir:       dir,
+		IpcNet:      "unix",
+		IpcAddr:     filepath.Join(dir, "ipc.sock"),
+		DBCount:     1,
+		DBBackend:   testDBBackend,
+	}
+
+	m, err := InitManager(cfg)
+	assert.NoError(t, err)
+	defer m.Close()
+
+	// CALCULATE_DONE which was not sent to restarted peer
+	crDB := m.ctx.DB.getCalculateResultDB()
+	done := CalculateDone{Success: true, BlockHeight: 50}
+	assert.NoError(t, WriteCalculateDoneOutbox(crDB, &done))
+	go m.Loop()
+
+	// new connections don't get CALCULATE_DONE in outbox on connection
+	var reader, writer ipc.Connection
+	for i := 0; i < 100; i++ {
+		if reader, err = ipc.Dial(cfg.IpcNet, cfg.IpcAddr); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+	defer reader.Close()
+	doneList, err := ReceiveReady(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	writer, err = ipc.Dial(cfg.IpcNet, cfg.IpcAddr)
+	assert.NoError(t, err)
+	defer writer.Close()
+	doneList, err = ReceiveReady(writer)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	// read-only connection does not get CALCULATE_DONE in outbox
+	var status QueryCalculateStatusResponse
+	assert.NoError(t, SendAndReceive(reader, MsgQueryCalculateStatus, 1, nil, &status))
+
+	// writer connection gets CALCULATE_DONE in outbox before the response of its first message
+	var blockHeight uint64
+	assert.NoError(t, writer.Send(MsgINIT, 1, &blockHeight))
+	var received CalculateDone
+	msg, _, err := writer.Receive(&received)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(MsgCalculateDone), msg)
+	assert.Equal(t, done.BlockHeight, received.BlockHeight)
+	var resp ResponseInit
+	msg, _, err = writer.Receive(&resp)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(MsgINIT), msg)
+	assert.True(t, resp.Success)
+
+	doneList, err = ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	// read-only connection still does not get CALCULATE_DONE
+	var initResp ResponseInit
+	assert.NoError(t, reader.SendAndReceive(MsgINIT, 2, &blockHeight, &initResp))
+	assert.False(t, initResp.Success)
+}
+
+// initWriterTest dials server and makes the connection the writer connection with INIT
+func initWriterTest(t *testing.T, m *manager, cfg *RcConfig) ipc.Connection {
+	conn, err := ipc.Dial(cfg.IpcNet, cfg.IpcAddr)
+	assert.NoError(t, err)
+	_, err = ReceiveReady(conn)
+	assert.NoError(t, err)
+
+	var blockHeight uint64
+	var resp ResponseInit
+	assert.NoError(t, SendAndReceive(conn, MsgINIT, 1, &blockHeight, &resp))
+	assert.True(t, resp.Success)
+	assert.True(t, m.hasWriter())
+	return conn
+}
+
+func TestManager_WriterChangedWhileCalculating(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manager")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := &RcConfig{
+		IISSDataDir: dir,
+		DBDir:       dir,
+		IpcNet:      "unix",
+		IpcAddr:     filepath.Join(dir, "ipc.sock"),
+		DBCount:     1,
+		DBBackend:   testDBBackend,
+	}
+
+	m, err := InitManager(cfg)
+	assert.NoError(t, err)
+	defer m.Close()
+	go m.Loop()
+	for i := 0; i < 100; i++ {
+		if _, err = os.Stat(cfg.IpcAddr); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	crDB := m.ctx.DB.getCalculateResultDB()
+
+	// writer connection which sent CALCULATE is closed while calculating
+	conn := initWriterTest(t, m, cfg)
+	calcConn := m.getWriter()
+	conn.Close()
+	assert.Eventually(t, func() bool { return !m.hasWriter() }, time.Second, 10*time.Millisecond)
+
+	// new writer connection gets CALCULATE_DONE of calculation finished after it became the writer
+	conn = initWriterTest(t, m, cfg)
+	done := CalculateDone{Success: true, BlockHeight: 50}
+	assert.NoError(t, m.notifyCalculateDone(calcConn, &done))
+	var received CalculateDone
+	msg, _, err := conn.Receive(&received)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(MsgCalculateDone), msg)
+	assert.Equal(t, done.BlockHeight, received.BlockHeight)
+	doneList, err := ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(doneList))
+
+	// CALCULATE_DONE is kept in outbox without writer connection
+	conn.Close()
+	assert.Eventually(t, func() bool { return !m.hasWriter() }, time.Second, 10*time.Millisecond)
+	done = CalculateDone{Success: true, BlockHeight: 60}
+	assert.NoError(t, m.notifyCalculateDone(calcConn, &done))
+
+	// CALCULATE_DONE of rejected CALCULATE does not replace it
+	rejected := CalculateDone{Success: false, BlockHeight: 60, ErrorCode: CalcErrorDuplicateBH}
+	assert.NoError(t, m.notifyCalculateDone(calcConn, &rejected))
+	doneList, err = ReadCalculateDoneOutbox(crDB)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(doneList))
+	assert.True(t, doneList[0].Success)
+
+	// next writer connection gets it before the response of its first message
+	conn, err = ipc.Dial(cfg.IpcNet, cfg.IpcAddr)
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = ReceiveReady(conn)
+	assert.NoError(t, err)
+	var blockHeight uint64
+	assert.NoError(t, conn.Send(MsgINIT, 1, &blockHeight))
+	msg, _, err = conn.Receive(&received)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(MsgCalculateDone), msg)
+	assert.Equal(t, done.BlockHeight, received.BlockHeight)
+	assert.True(t, received.Success)
+}
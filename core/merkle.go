@@ -0,0 +1,227 @@
+package core
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"golang.org/x/crypto/sha3"
+)
+
+// Merkle tree of I-Score accounts
+//
+// - leaves : IScoreAccount.BytesForHash() of all accounts in account DB sorted by address
+// - leaf hash : SHA3-256(0x00 || leaf)
+// - node hash : SHA3-256(0x01 || left child hash || right child hash)
+// - the last node of a level which has odd number of nodes is moved up to the upper level as is
+// - root of tree without leaf is nil
+// Merkle root is made and QUERY_ISCORE_PROOF is served only if it is enabled with SetMerkleProof()
+const (
+	merkleLeafPrefix byte = 0
+	merkleNodePrefix byte = 1
+)
+
+type MerkleProofNode struct {
+	Hash []byte
+	Left bool // sibling node is on the left
+}
+
+type MerkleTree struct {
+	levels [][][]byte
+}
+
+func merkleLeafHash(leaf []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func merkleNodeHash(left []byte, right []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// NewMerkleTree makes Merkle tree with hashes of leaves
+func NewMerkleTree(leafHashes [][]byte) *MerkleTree {
+	tree := new(MerkleTree)
+	level := leafHashes
+	tree.levels = append(tree.levels, level)
+	for len(level) > 1 {
+		upper := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				upper = append(upper, merkleNodeHash(level[i], level[i+1]))
+			} else {
+				upper = append(upper, level[i])
+			}
+		}
+		tree.levels = append(tree.levels, upper)
+		level = upper
+	}
+	return tree
+}
+
+func (t *MerkleTree) Root() []byte {
+	top := t.levels[len(t.levels)-1]
+	if len(top) == 0 {
+		return nil
+	}
+	return top[0]
+}
+
+// Proof returns sibling nodes from leaf of index to root
+func (t *MerkleTree) Proof(index int) []MerkleProofNode {
+	if index < 0 || index >= len(t.levels[0]) {
+		return nil
+	}
+
+	proof := make([]MerkleProofNode, 0, len(t.levels))
+	for _, level := range t.levels[:len(t.levels)-1] {
+		if index%2 == 1 {
+			proof = append(proof, MerkleProofNode{Hash: level[index-1], Left: true})
+		} else if index+1 < len(level) {
+			proof = append(proof, MerkleProofNode{Hash: level[index+1], Left: false})
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof checks that leaf is in Merkle tree of root with proof
+func VerifyMerkleProof(root []byte, leaf []byte, proof []MerkleProofNode) bool {
+	hash := merkleLeafHash(leaf)
+	for _, node := range proof {
+		if node.Left {
+			hash = merkleNodeHash(node.Hash, hash)
+		} else {
+			hash = merkleNodeHash(hash, node.Hash)
+		}
+	}
+	return bytes.Equal(root, hash)
+}
+
+// accountMerkleTree is Merkle tree of accounts calculated at blockHeight
+type accountMerkleTree struct {
+	blockHeight uint64
+	tree        *MerkleTree
+	// addresses of leaves
+	addresses []common.Address
+}
+
+// index returns leaf index of address. It returns -1 if there is no account of address
+func (t *accountMerkleTree) index(address common.Address) int {
+	i := sort.Search(len(t.addresses), func(i int) bool {
+		return bytes.Compare(t.addresses[i].Bytes(), address.Bytes()) >= 0
+	})
+	if i < len(t.addresses) && t.addresses[i].Equal(&address) {
+		return i
+	}
+	return -1
+}
+
+// newAccountMerkleTree makes Merkle tree with accounts in account DBs calculated at blockHeight
+func newAccountMerkleTree(dbList []db.Database, blockHeight uint64) (*accountMerkleTree, error) {
+	addresses := make([]common.Address, 0)
+	leafHashes := make([][]byte, 0)
+
+	prefix := util.BytesPrefix([]byte(db.PrefixIScore))
+	err := iterateSorted(dbList, prefix.Start, prefix.Limit, func(key []byte, value []byte) error {
+		ia, err := NewIScoreAccountFromBytes(value)
+		if err != nil {
+			return err
+		}
+		ia.Address = *common.NewAddress(key[len(db.PrefixIScore):])
+
+		addresses = append(addresses, ia.Address)
+		leafHashes = append(leafHashes, merkleLeafHash(ia.BytesForHash()))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &accountMerkleTree{blockHeight: blockHeight, tree: NewMerkleTree(leafHashes), addresses: addresses}, nil
+}
+
+// CalculateMerkleRoot returns Merkle root of accounts in account DBs
+func CalculateMerkleRoot(dbList []db.Database) ([]byte, error) {
+	t, err := newAccountMerkleTree(dbList, 0)
+	if err != nil {
+		return nil, err
+	}
+	return t.tree.Root(), nil
+}
+
+// the number of Merkle trees in cache. Calculate DB and query DB
+const merkleTreeCacheSize = 2
+
+// merkleTreeCache keeps Merkle trees of the latest calculations, so QUERY_ISCORE_PROOF does not scan account DBs
+type merkleTreeCache struct {
+	lock  sync.Mutex
+	trees []*accountMerkleTree
+}
+
+// get returns Merkle tree of blockHeight. It is made with dbList if it is not in cache
+func (c *merkleTreeCache) get(blockHeight uint64, dbList []db.Database) (*accountMerkleTree, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, t := range c.trees {
+		if t.blockHeight == blockHeight {
+			return t, nil
+		}
+	}
+
+	t, err := newAccountMerkleTree(dbList, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	c._add(t)
+	return t, nil
+}
+
+func (c *merkleTreeCache) add(t *accountMerkleTree) {
+	c.lock.Lock()
+	c._add(t)
+	c.lock.Unlock()
+}
+
+// _add adds Merkle tree and removes the oldest one if cache is full
+func (c *merkleTreeCache) _add(t *accountMerkleTree) {
+	trees := make([]*accountMerkleTree, 0, merkleTreeCacheSize+1)
+	for _, v := range c.trees {
+		if v.blockHeight != t.blockHeight {
+			trees = append(trees, v)
+		}
+	}
+	trees = append(trees, t)
+	sort.Slice(trees, func(i, j int) bool { return trees[i].blockHeight > trees[j].blockHeight })
+	if len(trees) > merkleTreeCacheSize {
+		trees = trees[:merkleTreeCacheSize]
+	}
+	c.trees = trees
+}
+
+// clear removes all Merkle trees. Account DBs of the same block height are changed by rollback
+func (c *merkleTreeCache) clear() {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	c.trees = nil
+	c.lock.Unlock()
+}
+
+// SetMerkleProof enables Merkle root of accounts in calculation result and QUERY_ISCORE_PROOF
+func (idb *IScoreDB) SetMerkleProof(enable bool) {
+	if enable && idb.merkleTrees == nil {
+		idb.merkleTrees = new(merkleTreeCache)
+	}
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerkle_MerkleTree(t *testing.T) {
+	// empty tree
+	tree := NewMerkleTree(nil)
+	assert.Nil(t, tree.Root())
+	assert.Nil(t, tree.Proof(0))
+
+	for count := 1; count <= 9; count++ {
+		leaves := make([][]byte, count)
+		leafHashes := make([][]byte, count)
+		for i := range leaves {
+			leaves[i] = []byte(fmt.Sprintf("leaf %d", i))
+			leafHashes[i] = merkleLeafHash(leaves[i])
+		}
+		tree = NewMerkleTree(leafHashes)
+		root := tree.Root()
+		assert.Equal(t, 32, len(root))
+
+		for i := range leaves {
+			proof := tree.Proof(i)
+			assert.True(t, VerifyMerkleProof(root, leaves[i], proof), "count %d, index %d", count, i)
+
+			// invalid leaf
+			assert.False(t, VerifyMerkleProof(root, []byte("invalid"), proof))
+
+			// invalid proof
+			if len(proof) > 0 {
+				proof[0].Left = !proof[0].Left
+				assert.False(t, VerifyMerkleProof(root, leaves[i], proof))
+			}
+		}
+		assert.Nil(t, tree.Proof(count))
+	}
+
+	// root of one leaf is hash of leaf
+	tree = NewMerkleTree([][]byte{merkleLeafHash([]byte("leaf"))})
+	assert.Equal(t, merkleLeafHash([]byte("leaf")), tree.Root())
+
+	// root of three leaves
+	h := [][]byte{merkleLeafHash([]byte{0}), merkleLeafHash([]byte{1}), merkleLeafHash([]byte{2})}
+	tree = NewMerkleTree(h)
+	assert.Equal(t, merkleNodeHash(merkleNodeHash(h[0], h[1]), h[2]), tree.Root())
+}
+
+func TestMerkle_newAccountMerkleTree(t *testing.T) {
+	ctx := initTest(4)
+	defer finalizeTest(ctx)
+
+	accounts := writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 20, 10)
+	target := accounts[7]
+	tree, err := newAccountMerkleTree(ctx.DB.getQueryDBList(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), tree.blockHeight)
+	index := tree.index(target.Address)
+	assert.True(t, index >= 0)
+	assert.True(t, VerifyMerkleProof(tree.tree.Root(), target.BytesForHash(), tree.tree.Proof(index)))
+
+	// same accounts in one DB
+	oneDB := ctx.DB.GetCalcDBList()[:1]
+	writeMigrateTestAccounts(oneDB, func(common.Address) int { return 0 }, 20, 10)
+	root, err := CalculateMerkleRoot(oneDB)
+	assert.NoError(t, err)
+	assert.Equal(t, tree.tree.Root(), root)
+
+	// no account
+	assert.Equal(t, -1, tree.index(*common.NewAddressFromString("hx1234")))
+}
+
+func TestMerkle_merkleTreeCache(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 20, 10)
+	cache := new(merkleTreeCache)
+	tree, err := cache.get(10, ctx.DB.getQueryDBList())
+	assert.NoError(t, err)
+
+	// Merkle tree in cache is not made again
+	cached, err := cache.get(10, nil)
+	assert.NoError(t, err)
+	assert.True(t, tree == cached)
+
+	// keep the latest trees
+	for _, bh := range []uint64{20, 30} {
+		cache.add(&accountMerkleTree{blockHeight: bh, tree: NewMerkleTree(nil)})
+	}
+	assert.Equal(t, merkleTreeCacheSize, len(cache.trees))
+	assert.Equal(t, uint64(30), cache.trees[0].blockHeight)
+	assert.Equal(t, uint64(20), cache.trees[1].blockHeight)
+
+	cache.clear()
+	assert.Equal(t, 0, len(cache.trees))
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsNamespace = "icon_rc"
+	MetricsPath      = "/metrics"
+)
+
+var (
+	// Calculation
+	metricCalcDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculation",
+		Name:      "duration_seconds",
+		Help:      "Duration of the latest CALCULATE",
+	})
+	metricCalcAccounts = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculation",
+		Name:      "accounts",
+		Help:      "The number of accounts processed in the latest CALCULATE",
+	})
+	metricCalcReward = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculation",
+		Name:      "iscore",
+		Help:      "I-Score calculated in the latest CALCULATE",
+	}, []string{"type"})
+	metricCalcTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "calculation",
+		Name:      "total",
+		Help:      "The number of CALCULATE processed",
+	}, []string{"result"})
+
+	// IPC
+	metricIPCMessages = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "ipc",
+		Name:      "messages_total",
+		Help:      "The number of IPC messages received",
+	}, []string{"msg"})
+	metricIPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "ipc",
+		Name:      "errors_total",
+		Help:      "The number of IPC messages failed to handle",
+	}, []string{"msg"})
+	metricIPCLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "ipc",
+		Name:      "latency_seconds",
+		Help:      "Latency of IPC message handling",
+		Buckets:   []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 60, 300, 1800},
+	}, []string{"msg"})
+
+	// Claim
+	metricClaims = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "claim",
+		Name:      "precommit_total",
+		Help:      "The number of claims written to preCommit DB",
+	})
+	metricClaimCommits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "claim",
+		Name:      "commit_total",
+		Help:      "The number of claims committed from preCommit DB to claim DB",
+	})
+)
+
+func newMetricsRegistry(ctx *Context) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	registry.MustRegister(metricCalcDuration, metricCalcAccounts, metricCalcReward, metricCalcTotal)
+	registry.MustRegister(metricIPCMessages, metricIPCErrors, metricIPCLatency)
+	registry.MustRegister(metricClaims, metricClaimCommits)
+
+	// DB Info. is read from context when scraped
+	blockHeights := map[string]func() uint64{
+		"calc_done":   ctx.DB.getCalcDoneBH,
+		"calculating": ctx.DB.getCalculatingBH,
+		"current": func() uint64 {
+			return ctx.DB.getCurrentBlockInfo().BlockHeight
+		},
+	}
+	for name, f := range blockHeights {
+		getter := f
+		registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   metricsNamespace,
+			Subsystem:   "db",
+			Name:        "block_height",
+			Help:        "Block heights in DB Info.",
+			ConstLabels: prometheus.Labels{"type": name},
+		}, func() float64 {
+			return float64(getter())
+		}))
+	}
+
+	return registry
+}
+
+func newMetricsHandler(ctx *Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, promhttp.HandlerFor(newMetricsRegistry(ctx), promhttp.HandlerOpts{}))
+	return mux
+}
+
+// StartMetricsServer serves metrics with HTTP on address in background
+func StartMetricsServer(ctx *Context, address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{Handler: newMetricsHandler(ctx)}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server stopped. %v", err)
+		}
+	}()
+	log.Printf("Serve metrics at http://%s%s", address, MetricsPath)
+
+	return srv, nil
+}
+
+func hexIntToFloat64(value *common.HexInt) float64 {
+	f, _ := new(big.Float).SetInt(&value.Int).Float64()
+	return f
+}
+
+func observeCalculation(elapsed time.Duration, stats *Statistics, err error) {
+	if err != nil {
+		metricCalcTotal.WithLabelValues("failure").Inc()
+		return
+	}
+	metricCalcTotal.WithLabelValues("success").Inc()
+	metricCalcDuration.Set(elapsed.Seconds())
+	if stats == nil {
+		return
+	}
+	metricCalcAccounts.Set(float64(stats.Accounts))
+	metricCalcReward.WithLabelValues("total").Set(hexIntToFloat64(&stats.TotalReward))
+	metricCalcReward.WithLabelValues("beta1").Set(hexIntToFloat64(&stats.Beta1))
+	metricCalcReward.WithLabelValues("beta2").Set(hexIntToFloat64(&stats.Beta2))
+	metricCalcReward.WithLabelValues("beta3").Set(hexIntToFloat64(&stats.Beta3))
+}
+
+func observeIPCMessage(msg uint, start time.Time, err error) {
+	name := MsgToString(msg)
+	metricIPCMessages.WithLabelValues(name).Inc()
+	metricIPCLatency.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		metricIPCErrors.WithLabelValues(name).Inc()
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func metricValue(m prometheus.Metric) float64 {
+	var pb dto.Metric
+	m.Write(&pb)
+	switch {
+	case pb.Gauge != nil:
+		return pb.Gauge.GetValue()
+	case pb.Counter != nil:
+		return pb.Counter.GetValue()
+	}
+	return 0
+}
+
+func TestMetrics_observeCalculation(t *testing.T) {
+	stats := new(Statistics)
+	stats.Accounts = 10
+	stats.Beta1.SetUint64(1)
+	stats.Beta2.SetUint64(2)
+	stats.Beta3.SetUint64(3)
+	stats.TotalReward.SetUint64(6)
+
+	failure := metricValue(metricCalcTotal.WithLabelValues("failure"))
+	observeCalculation(time.Second, nil, errors.New("failed"))
+	assert.Equal(t, failure+1, metricValue(metricCalcTotal.WithLabelValues("failure")))
+
+	observeCalculation(2*time.Second, stats, nil)
+	assert.Equal(t, float64(2), metricValue(metricCalcDuration))
+	assert.Equal(t, float64(10), metricValue(metricCalcAccounts))
+	assert.Equal(t, float64(1), metricValue(metricCalcReward.WithLabelValues("beta1")))
+	assert.Equal(t, float64(2), metricValue(metricCalcReward.WithLabelValues("beta2")))
+	assert.Equal(t, float64(3), metricValue(metricCalcReward.WithLabelValues("beta3")))
+	assert.Equal(t, float64(6), metricValue(metricCalcReward.WithLabelValues("total")))
+}
+
+func TestMetrics_observeIPCMessage(t *testing.T) {
+	msg := MsgToString(MsgQuery)
+	count := metricValue(metricIPCMessages.WithLabelValues(msg))
+	errCount := metricValue(metricIPCErrors.WithLabelValues(msg))
+
+	observeIPCMessage(MsgQuery, time.Now(), nil)
+	observeIPCMessage(MsgQuery, time.Now(), errors.New("failed"))
+
+	assert.Equal(t, count+2, metricValue(metricIPCMessages.WithLabelValues(msg)))
+	assert.Equal(t, errCount+1, metricValue(metricIPCErrors.WithLabelValues(msg)))
+}
+
+func TestMetrics_newMetricsHandler(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	ctx.DB.setCalcDoneBH(100)
+	ctx.DB.setCalculatingBH(200)
+	ctx.DB.setCurrentBlockInfo(150, testHash)
+
+	req := httptest.NewRequest(http.MethodGet, MetricsPath, nil)
+	rec := httptest.NewRecorder()
+	newMetricsHandler(ctx).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.True(t, strings.Contains(body, `icon_rc_db_block_height{type="calc_done"} 100`), body)
+	assert.True(t, strings.Contains(body, `icon_rc_db_block_height{type="calculating"} 200`), body)
+	assert.True(t, strings.Contains(body, `icon_rc_db_block_height{type="current"} 150`), body)
+}
+
+func TestMetrics_StartMetricsServer(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	srv, err := StartMetricsServer(ctx, "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer srv.Close()
+
+	// invalid address
+	_, err = StartMetricsServer(ctx, "invalid address")
+	assert.Error(t, err)
+}
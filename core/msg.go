@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/icon-project/rewardcalculator/common"
 	"github.com/icon-project/rewardcalculator/common/codec"
@@ -27,6 +28,12 @@ const (
 	MsgQueryCalculateResult      = 7
 	MsgRollBack                  = 8
 	MsgINIT                      = 9
+	MsgQueryIScoreProof          = 10
+	MsgAckCalculateDone          = 11
+	MsgBatchQuery                = 12
+	MsgQueryAt                   = 13
+	MsgQueryRewardBreakdown      = 14
+	MsgQueryClaimHistory         = 15
 
 	MsgNotify        = 100
 	MsgReady         = MsgNotify + 0
@@ -61,6 +68,18 @@ func MsgToString(msg uint) string {
 		return "ROLLBACK"
 	case MsgINIT:
 		return "INIT"
+	case MsgQueryIScoreProof:
+		return "QUERY_ISCORE_PROOF"
+	case MsgAckCalculateDone:
+		return "ACK_CALCULATE_DONE"
+	case MsgBatchQuery:
+		return "BATCH_QUERY"
+	case MsgQueryAt:
+		return "QUERY_AT"
+	case MsgQueryRewardBreakdown:
+		return "QUERY_REWARD_BREAKDOWN"
+	case MsgQueryClaimHistory:
+		return "QUERY_CLAIM_HISTORY"
 	case MsgDebug:
 		return "DEBUG"
 	default:
@@ -91,6 +110,11 @@ func newConnection(m *manager, c ipc.Connection) (*msgHandler, error) {
 	c.SetHandler(MsgQuery, handler)
 	c.SetHandler(MsgQueryCalculateStatus, handler)
 	c.SetHandler(MsgQueryCalculateResult, handler)
+	c.SetHandler(MsgQueryIScoreProof, handler)
+	c.SetHandler(MsgBatchQuery, handler)
+	c.SetHandler(MsgQueryAt, handler)
+	c.SetHandler(MsgQueryRewardBreakdown, handler)
+	c.SetHandler(MsgQueryClaimHistory, handler)
 	if m.monitorMode == true {
 		c.SetHandler(MsgDebug, handler)
 	} else {
@@ -100,6 +124,7 @@ func newConnection(m *manager, c ipc.Connection) (*msgHandler, error) {
 		c.SetHandler(MsgCommitClaim, handler)
 		c.SetHandler(MsgRollBack, handler)
 		c.SetHandler(MsgINIT, handler)
+		c.SetHandler(MsgAckCalculateDone, handler)
 	}
 
 	// send READY message to peer
@@ -116,36 +141,67 @@ func newConnection(m *manager, c ipc.Connection) (*msgHandler, error) {
 
 func (mh *msgHandler) HandleMessage(c ipc.Connection, msg uint, id uint32, data []byte) error {
 	log.Printf("Get message. (msg:%s, id:%d)", MsgToString(msg), id)
+	if isWriteMessage(msg) && !mh.acquireWriter(c) {
+		// do not modify I-Score DB with read-only connection
+		start := time.Now()
+		err := mh.refuse(c, msg, id, data)
+		observeIPCMessage(msg, start, err)
+		return err
+	}
 	switch msg {
 	case MsgVersion:
-		go mh.version(c, id)
+		mh.run(msg, func() error { return mh.version(c, id) })
 	case MsgClaim:
-		go mh.claim(c, id, data)
+		mh.run(msg, func() error { return mh.claim(c, id, data) })
 	case MsgQuery:
-		go mh.query(c, id, data)
+		mh.run(msg, func() error { return mh.query(c, id, data) })
 	case MsgCalculate:
-		go mh.calculate(c, id, data)
+		mh.run(msg, func() error { return mh.calculate(c, id, data) })
 	case MsgCommitBlock:
-		go mh.commitBlock(c, id, data)
+		mh.run(msg, func() error { return mh.commitBlock(c, id, data) })
 	case MsgDebug:
-		go mh.debug(c, id, data)
+		mh.run(msg, func() error { return mh.debug(c, id, data) })
 	case MsgCommitClaim:
-		go mh.commitClaim(c, id, data)
+		mh.run(msg, func() error { return mh.commitClaim(c, id, data) })
 	case MsgQueryCalculateStatus:
-		go mh.queryCalculateStatus(c, id, data)
+		mh.run(msg, func() error { return mh.queryCalculateStatus(c, id, data) })
 	case MsgQueryCalculateResult:
-		go mh.queryCalculateResult(c, id, data)
+		mh.run(msg, func() error { return mh.queryCalculateResult(c, id, data) })
+	case MsgQueryIScoreProof:
+		mh.run(msg, func() error { return mh.queryIScoreProof(c, id, data) })
 	case MsgRollBack:
 		// do not process other messages while process Rollback message
-		return mh.rollback(c, id, data)
+		start := time.Now()
+		err := mh.rollback(c, id, data)
+		observeIPCMessage(msg, start, err)
+		return err
 	case MsgINIT:
-		go mh.init(c, id, data)
+		mh.run(msg, func() error { return mh.init(c, id, data) })
+	case MsgAckCalculateDone:
+		mh.run(msg, func() error { return mh.ackCalculateDone(c, id, data) })
+	case MsgBatchQuery:
+		mh.run(msg, func() error { return mh.batchQuery(c, id, data) })
+	case MsgQueryAt:
+		mh.run(msg, func() error { return mh.queryAt(c, id, data) })
+	case MsgQueryRewardBreakdown:
+		mh.run(msg, func() error { return mh.queryRewardBreakdown(c, id, data) })
+	case MsgQueryClaimHistory:
+		mh.run(msg, func() error { return mh.queryClaimHistory(c, id, data) })
 	default:
 		return errors.Errorf("UnknownMessage(%d)", msg)
 	}
 	return nil
 }
 
+// run handles message in new goroutine and records IPC metrics
+func (mh *msgHandler) run(msg uint, handle func() error) {
+	go func() {
+		start := time.Now()
+		err := handle()
+		observeIPCMessage(msg, start, err)
+	}()
+}
+
 type ResponseVersion struct {
 	Version     uint64
 	BlockHeight uint64
@@ -204,26 +260,31 @@ func (mh *msgHandler) query(c ipc.Connection, id uint32, data []byte) error {
 }
 
 func DoQuery(ctx *Context, addr common.Address) *ResponseQuery {
+	view, err := ctx.DB.newReadView()
+	if err != nil {
+		log.Printf("Failed to query I-Score of %s. %v", addr.String(), err)
+		return &ResponseQuery{Address: addr}
+	}
+	defer view.Release()
+
+	return queryAccount(view, addr)
+}
+
+// queryAccount reads I-Score of addr from query DB and subtracts claimed I-Score in claim DB of view
+func queryAccount(view *readView, addr common.Address) *ResponseQuery {
 	var claim *Claim = nil
 	var ia *IScoreAccount = nil
-	isDB := ctx.DB
 
 	// make response
 	var resp ResponseQuery
 	resp.Address = addr
 
 	// read from claim DB
-	cDB := isDB.getClaimDB()
-	bucket, _ := cDB.GetBucket(db.PrefixIScore)
-	bs, _ := bucket.Get(addr.Bytes())
-	if bs != nil {
-		claim, _ = NewClaimFromBytes(bs)
-	}
+	claim, _ = view.getClaim(addr)
 
 	// read from Query DB
-	qDB := isDB.getQueryDB(addr)
-	bucket, _ = qDB.GetBucket(db.PrefixIScore)
-	bs, _ = bucket.Get(addr.Bytes())
+	bucket, _ := view.getQueryDB(addr).GetBucket(db.PrefixIScore)
+	bs, _ := bucket.Get(addr.Bytes())
 	if bs != nil {
 		ia, _ = NewIScoreAccountFromBytes(bs)
 		resp.BlockHeight = ia.BlockHeight
@@ -243,6 +304,53 @@ func DoQuery(ctx *Context, addr common.Address) *ResponseQuery {
 	return &resp
 }
 
+type ResponseBatchQuery struct {
+	Accounts []ResponseQuery
+}
+
+func (rq *ResponseBatchQuery) String() string {
+	return fmt.Sprintf("Accounts: %d", len(rq.Accounts))
+}
+
+func (mh *msgHandler) batchQuery(c ipc.Connection, id uint32, data []byte) error {
+	var addrs []common.Address
+	mh.mgr.AddMsgTask()
+	if _, err := codec.MP.UnmarshalFromBytes(data, &addrs); err != nil {
+		mh.mgr.DoneMsgTask()
+		return err
+	}
+	log.Printf("\t BATCH_QUERY request: %d addresses", len(addrs))
+
+	resp := DoBatchQuery(mh.mgr.ctx, addrs)
+
+	mh.mgr.DoneMsgTask()
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgBatchQuery), id, resp.String())
+	return c.Send(MsgBatchQuery, id, resp)
+}
+
+// DoBatchQuery queries I-Score of addresses.
+// All I-Scores are read from the same query DB and claim DB snapshot
+func DoBatchQuery(ctx *Context, addrs []common.Address) *ResponseBatchQuery {
+	resp := new(ResponseBatchQuery)
+	resp.Accounts = make([]ResponseQuery, len(addrs))
+
+	view, err := ctx.DB.newReadView()
+	if err != nil {
+		log.Printf("Failed to query I-Score of %d addresses. %v", len(addrs), err)
+		for i, addr := range addrs {
+			resp.Accounts[i].Address = addr
+		}
+		return resp
+	}
+	defer view.Release()
+
+	for i, addr := range addrs {
+		resp.Accounts[i] = *queryAccount(view, addr)
+	}
+
+	return resp
+}
+
 type ResponseInit struct {
 	Success     bool
 	BlockHeight uint64
@@ -37,15 +37,20 @@ type CalculateRequest struct {
 	Path        string
 	BlockHeight uint64
 	BlockHash   []byte
+	// calculate to throwaway calculate DBs and respond with CalculateDryRunResponse
+	DryRun bool
 }
 
 func (cr *CalculateRequest) String() string {
-	return fmt.Sprintf("Path: %s, BlockHeight: %d", cr.Path, cr.BlockHeight)
+	return fmt.Sprintf("Path: %s, BlockHeight: %d, DryRun: %s", cr.Path, cr.BlockHeight,
+		strconv.FormatBool(cr.DryRun))
 }
 
 type CalculateResponse struct {
 	Status      uint16
 	BlockHeight uint64
+	// reason of rejection
+	Reason string
 }
 
 const (
@@ -54,6 +59,7 @@ const (
 	CalcRespStatusDoing       uint16 = 2
 	CalcRespStatusInvalidBH   uint16 = 3
 	CalcRespStatusDuplicateBH uint16 = 4
+	CalcRespStatusReadOnly    uint16 = 5
 )
 
 func CalcRespStatusToString(status uint16) string {
@@ -68,13 +74,16 @@ func CalcRespStatusToString(status uint16) string {
 		return "Invalid block height"
 	case CalcRespStatusDuplicateBH:
 		return "Duplicate block height"
+	case CalcRespStatusReadOnly:
+		return "Read-only connection"
 	default:
 		return "Unknown status"
 	}
 }
 
 func (cr *CalculateResponse) String() string {
-	return fmt.Sprintf("status: %s, BlockHeight: %d", CalcRespStatusToString(cr.Status), cr.BlockHeight)
+	return fmt.Sprintf("status: %s, BlockHeight: %d, Reason: %s", CalcRespStatusToString(cr.Status),
+		cr.BlockHeight, cr.Reason)
 }
 
 type CalculateDone struct {
@@ -82,14 +91,98 @@ type CalculateDone struct {
 	BlockHeight uint64
 	IScore      common.HexInt
 	StateHash   []byte
+	// error code and message of failed calculation
+	ErrorCode    uint16
+	ErrorMessage string
 }
 
 func (cd *CalculateDone) String() string {
-	return fmt.Sprintf("Success: %s, BlockHeight: %d, IScore: %s, StateHash: %s",
+	str := fmt.Sprintf("Success: %s, BlockHeight: %d, IScore: %s, StateHash: %s",
 		strconv.FormatBool(cd.Success),
 		cd.BlockHeight,
 		cd.IScore.String(),
 		hex.EncodeToString(cd.StateHash))
+	if !cd.Success {
+		str += fmt.Sprintf(", Error: %s(%s)", CalcErrorToString(cd.ErrorCode), cd.ErrorMessage)
+	}
+	return str
+}
+
+// error codes of failed calculation. Codes of rejected CALCULATE are the same as CALCULATE response status
+const (
+	CalcErrorNone           uint16 = 0
+	CalcErrorInvalidData    uint16 = 1
+	CalcErrorDoing          uint16 = 2
+	CalcErrorInvalidBH      uint16 = 3
+	CalcErrorDuplicateBH    uint16 = 4
+	CalcErrorCancelRollback uint16 = 10
+	CalcErrorCancelExit     uint16 = 11
+	CalcErrorInternal       uint16 = 12
+)
+
+func CalcErrorToString(code uint16) string {
+	switch code {
+	case CalcErrorNone:
+		return "None"
+	case CalcErrorInvalidData:
+		return "Invalid IISS data"
+	case CalcErrorDoing:
+		return "Calculating"
+	case CalcErrorInvalidBH:
+		return "Invalid block height"
+	case CalcErrorDuplicateBH:
+		return "Duplicate block height"
+	case CalcErrorCancelRollback:
+		return "Canceled by ROLLBACK"
+	case CalcErrorCancelExit:
+		return "Canceled by exit"
+	case CalcErrorInternal:
+		return "Internal error"
+	default:
+		return "Unknown error"
+	}
+}
+
+// CalculateError is error of rejected CALCULATE
+type CalculateError struct {
+	Code    uint16
+	Message string
+}
+
+func (e *CalculateError) Error() string {
+	return e.Message
+}
+
+func newCalculateError(code uint16, format string, a ...interface{}) *CalculateError {
+	return &CalculateError{Code: code, Message: fmt.Sprintf(format, a...)}
+}
+
+// calcErrorCode returns error code of error returned by DoCalculate
+func calcErrorCode(err error) uint16 {
+	switch e := err.(type) {
+	case nil:
+		return CalcErrorNone
+	case *CalculateError:
+		return e.Code
+	case *IISSDataError:
+		return CalcErrorInvalidData
+	case *CalcCancelByRollbackError:
+		return CalcErrorCancelRollback
+	case *CalcCancelByExit:
+		return CalcErrorCancelExit
+	default:
+		return CalcErrorInternal
+	}
+}
+
+// calcRejected returns true if CALCULATE with error code was rejected before calculation
+func calcRejected(code uint16) bool {
+	switch code {
+	case CalcErrorInvalidData, CalcErrorDoing, CalcErrorInvalidBH, CalcErrorDuplicateBH:
+		return true
+	default:
+		return false
+	}
 }
 
 func calculateDelegationReward(ctx *Context, delegationInfo *DelegateData, start uint64, end uint64,
@@ -135,7 +228,11 @@ func calculateDelegationReward(ctx *Context, delegationInfo *DelegateData, start
 	return total
 }
 
-func calculateIScore(ctx *Context, ia *IScoreAccount, blockHeight uint64) (bool, *common.HexInt) {
+// calculateIScore calculates delegation reward of ia to blockHeight.
+// report is called with reward of each delegation to P-Rep candidate. reward is nil if delegation is less than
+// MinDelegation. report can be nil
+func calculateIScore(ctx *Context, ia *IScoreAccount, blockHeight uint64,
+	report func(pRep common.Address, reward *common.HexInt)) (bool, *common.HexInt) {
 	//log.Printf("[Delegation reward] Read data: %s\n", ia.String())
 
 	totalReward := common.NewHexIntFromUint64(0)
@@ -150,19 +247,25 @@ func calculateIScore(ctx *Context, ia *IScoreAccount, blockHeight uint64) (bool,
 	}
 
 	for _, dg := range ia.Delegations {
-		if MinDelegation > dg.Delegate.Uint64() {
-			// not enough delegation
-			continue
-		}
-
 		_, ok := ctx.PRepCandidates[dg.Address]
 		if ok == false {
 			// there is no P-Rep
 			continue
 		}
 
+		if MinDelegation > dg.Delegate.Uint64() {
+			// not enough delegation
+			if report != nil {
+				report(dg.Address, nil)
+			}
+			continue
+		}
+
 		reward := calculateDelegationReward(ctx, dg, ia.BlockHeight,
 			blockHeight, ctx.PRepCandidates[dg.Address], ia.Address)
+		if report != nil {
+			report(dg.Address, reward)
+		}
 
 		// update totalReward
 		totalReward.Add(&totalReward.Int, &reward.Int)
@@ -218,7 +321,7 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 		stats.Increase("Accounts", uint64(1))
 
 		// calculate
-		ok, reward := calculateIScore(ctx, ia, blockHeight)
+		ok, reward := calculateIScore(ctx, ia, blockHeight, ctx.pRepReport.recorder(index, ia.Address, 1))
 		if ok == false {
 			continue
 		}
@@ -234,6 +337,7 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 				}
 				batch.Reset()
 				entries = 0
+				crashPoint()
 			}
 		} else {
 			bucket.Set(key, ia.Bytes())
@@ -244,6 +348,7 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 
 		// update Statistics
 		stats.Increase("Beta3", *reward)
+		ctx.rewards.addBeta3(index, ia.Address, &reward.Int)
 
 		count++
 	}
@@ -262,6 +367,7 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 				log.Printf("Failed to write batch\n")
 			}
 			batch.Reset()
+			crashPoint()
 		}
 
 		// get stateHash if there is update
@@ -280,9 +386,9 @@ func calculateDB(quit <-chan struct{}, index int, readDB db.Database, writeDB db
 	}
 }
 
-func sendCalculateACK(c ipc.Connection, id uint32, status uint16, blockHeight uint64) error {
+func sendCalculateACK(c ipc.Connection, id uint32, status uint16, blockHeight uint64, reason string) error {
 	if c != nil {
-		response := CalculateResponse{Status: status, BlockHeight: blockHeight}
+		response := CalculateResponse{Status: status, BlockHeight: blockHeight, Reason: reason}
 		log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCalculate), id, response.String())
 		if err := c.Send(MsgCalculate, id, response); err != nil {
 			return err
@@ -292,7 +398,6 @@ func sendCalculateACK(c ipc.Connection, id uint32, status uint16, blockHeight ui
 }
 
 func (mh *msgHandler) calculate(c ipc.Connection, id uint32, data []byte) error {
-	success := true
 	var req CalculateRequest
 	mh.mgr.AddMsgTask()
 	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
@@ -301,41 +406,64 @@ func (mh *msgHandler) calculate(c ipc.Connection, id uint32, data []byte) error
 	log.Printf("\t CALCULATE request: %s", req.String())
 
 	ctx := mh.mgr.ctx
+	if req.DryRun {
+		resp := DoCalculateDryRun(ctx, &req)
+		mh.mgr.DoneMsgTask()
+		log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCalculate), id, resp.String())
+		return c.Send(MsgCalculate, id, resp)
+	}
 	rollback := ctx.CancelCalculation.GetChannel()
 
 	// do calculation
+	startTime := time.Now()
 	err, blockHeight, stats, stateHash := DoCalculate(rollback, ctx, &req, c, id)
+	observeCalculation(time.Since(startTime), stats, err)
 
 	// manage IISS data DB
 	if err == nil {
 		cleanupIISSData(req.Path)
 	} else {
 		log.Printf("Failed to calculate. %v", err)
-		success = false
 	}
 
 	// send CALCULATE_DONE
-	var resp CalculateDone
+	resp := newCalculateDone(ctx, err, blockHeight, stats, stateHash)
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCalculateDone), 0, resp.String())
+	err = mh.mgr.notifyCalculateDone(c, resp)
+	mh.mgr.DoneMsgTask()
+	return err
+}
+
+// newCalculateDone makes CALCULATE_DONE with the result of DoCalculate.
+// Failure of accepted calculation is written to calculation result DB. Error of rejected CALCULATE is sent
+// with CALCULATE_DONE only, because it is not the result of calculation
+func newCalculateDone(ctx *Context, err error, blockHeight uint64, stats *Statistics, stateHash []byte) *CalculateDone {
+	resp := new(CalculateDone)
 	resp.BlockHeight = blockHeight
-	resp.Success = success
+	resp.Success = err == nil
 	if stats != nil {
 		resp.IScore.Set(&stats.TotalReward.Int)
 	} else {
 		resp.IScore.SetUint64(0)
 	}
 	resp.StateHash = stateHash
+	if err == nil {
+		return resp
+	}
 
-	mh.mgr.DoneMsgTask()
-	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCalculateDone), 0, resp.String())
-	return c.Send(MsgCalculateDone, 0, &resp)
+	resp.ErrorCode = calcErrorCode(err)
+	resp.ErrorMessage = err.Error()
+	if !calcRejected(resp.ErrorCode) {
+		if err = WriteCalculationFailure(ctx.DB.getCalculateResultDB(), blockHeight, resp.ErrorCode,
+			resp.ErrorMessage); err != nil {
+			log.Printf("Failed to write calculation failure. %v", err)
+		}
+	}
+	return resp
 }
 
 func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ipc.Connection, id uint32) (error, uint64, *Statistics, []byte) {
-	h := sha3.NewShake256()
-	stateHash := make([]byte, 64)
-	stats := new(Statistics)
 	reload := isReloadRequest(req.BlockHeight, id)
-	var newAccount uint64
 
 	iScoreDB := ctx.DB
 	blockHeight := req.BlockHeight
@@ -343,23 +471,21 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 	log.Printf("Get calculate message: blockHeight: %d, IISS data path: %s", blockHeight, req.Path)
 	if !reload && ctx.DB.isCalculating() {
 		// send response of CALCULATE
-		sendCalculateACK(c, id, CalcRespStatusDoing, blockHeight)
-		err := fmt.Errorf("calculating now. drop calculate message. blockHeight: %d, IISS data path: %s",
+		err := newCalculateError(CalcErrorDoing, "calculating now. drop calculate message. blockHeight: %d, IISS data path: %s",
 			blockHeight, req.Path)
+		sendCalculateACK(c, id, CalcRespStatusDoing, blockHeight, err.Error())
 		return err, blockHeight, nil, nil
 	}
 
-	startTime := time.Now()
-
 	// open IISS Data
-	iissDB := OpenIISSData(req.Path)
+	iissDB := OpenIISSData(req.Path, ctx.IISSDataBackend)
 	defer iissDB.Close()
 
 	// Load IISS data - Header, Governance variable, P-Rep list
 	header, gvList, prepList := LoadIISSData(iissDB)
 	if header == nil {
-		sendCalculateACK(c, id, CalcRespStatusInvalidData, blockHeight)
-		err := fmt.Errorf("Failed to load IISS data (path: %s)\n", req.Path)
+		err := newCalculateError(CalcErrorInvalidData, "Failed to load IISS data (path: %s)\n", req.Path)
+		sendCalculateACK(c, id, CalcRespStatusInvalidData, blockHeight, err.Error())
 		ctx.DB.resetCalculatingBH()
 		return err, blockHeight, nil, nil
 	}
@@ -375,34 +501,67 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 	// check blockHeight and blockHash
 	calcDoneBH := iScoreDB.getCalcDoneBH()
 	if blockHeight == calcDoneBH {
-		sendCalculateACK(c, id, CalcRespStatusDuplicateBH, blockHeight)
-		err := fmt.Errorf("duplicated block(height: %d, hash: %s)\n",
+		err := newCalculateError(CalcErrorDuplicateBH, "duplicated block(height: %d, hash: %s)\n",
 			blockHeight, hex.EncodeToString(req.BlockHash))
+		sendCalculateACK(c, id, CalcRespStatusDuplicateBH, blockHeight, err.Error())
 		ctx.DB.resetCalculatingBH()
 		return err, blockHeight, nil, nil
 	}
 	if blockHeight < calcDoneBH {
-		sendCalculateACK(c, id, CalcRespStatusInvalidBH, blockHeight)
-		err := fmt.Errorf("too low blockHeight(request: %d, RC blockHeight: %d)\n",
+		err := newCalculateError(CalcErrorInvalidBH, "too low blockHeight(request: %d, RC blockHeight: %d)\n",
 			blockHeight, calcDoneBH)
+		sendCalculateACK(c, id, CalcRespStatusInvalidBH, blockHeight, err.Error())
 		ctx.DB.resetCalculatingBH()
 		return err, blockHeight, nil, nil
 	}
 
+	// reject invalid IISS data before updating anything with it
+	if problems := ValidateIISSData(iissDB, calcDoneBH); len(problems) != 0 {
+		err := &IISSDataError{Problems: problems}
+		sendCalculateACK(c, id, CalcRespStatusInvalidData, blockHeight, err.Error())
+		ctx.DB.resetCalculatingBH()
+		return err, blockHeight, nil, nil
+	}
+
+	// I-Score DB is changed from here. Crash is recovered with journal
+	ctx.DB.calcLock.Lock()
+	defer ctx.DB.calcLock.Unlock()
+	ctx.journal = ctx.DB.beginCalculation(blockHeight)
+	defer func() { ctx.journal = nil }()
+
 	// set toggle block height with Term start block height
 	ctx.DB.toggleAccountDB(blockHeight + 1)
+	crashPoint()
 
 	// send response of CALCULATE after toggle DB
-	sendCalculateACK(c, id, CalcRespStatusOK, blockHeight)
+	sendCalculateACK(c, id, CalcRespStatusOK, blockHeight, "")
 
 	// close and backup old query DB and open new calculate DB
-	ctx.DB.resetAccountDB(blockHeight, ctx.DB.getCalcDoneBH())
+	ctx.setCalculationPhase(JournalCalcReset)
+	ctx.DB.resetAccountDB(blockHeight)
+
+	return calculateTerm(quit, ctx, iissDB, header, gvList, prepList, blockHeight, req.BlockHash)
+}
+
+// calculateTerm calculates I-Score of all accounts to blockHeight with IISS data.
+// It reads accounts from query DB and writes them to calculate DB, so account DB must be toggled already.
+func calculateTerm(quit <-chan struct{}, ctx *Context, iissDB db.Database, header *IISSHeader,
+	gvList []*IISSGovernanceVariable, prepList []*PRep, blockHeight uint64,
+	blockHash []byte) (error, uint64, *Statistics, []byte) {
+	h := sha3.NewShake256()
+	stateHash := make([]byte, 64)
+	stats := new(Statistics)
+	var newAccount uint64
+	iScoreDB := ctx.DB
+	startTime := time.Now()
 
 	// Update header Info.
 	if header != nil {
 		ctx.Revision = header.Revision
 	}
 
+	ctx.setCalculationPhase(JournalCalcTerm)
+
 	// Update GV
 	ctx.UpdateGovernanceVariable(gvList)
 
@@ -414,7 +573,9 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 
 	ctx.Print()
 
-	InitCalcDebugResult(ctx, blockHeight, req.BlockHash)
+	InitCalcDebugResult(ctx, blockHeight, blockHash)
+	ctx.rewards = ctx.DB.newRewardRecorder()
+	ctx.pRepReport = ctx.DB.newPRepReporter()
 
 	//
 	// Calculate I-Score @ Account DB
@@ -468,23 +629,33 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 
 	reward := new(common.HexInt)
 	var hashValue []byte
+	var err error
 
 	// Update calculate DB with delegate TX
-	newAccount, reward, hashValue = calculateIISSTX(ctx, iissDB, blockHeight, false)
+	newAccount, reward, hashValue, err = calculateIISSTX(ctx, iissDB, blockHeight, false)
+	if err != nil {
+		return fmt.Errorf("failed to write to account DB. %v", err), blockHeight, nil, nil
+	}
 	stats.Increase("Accounts", newAccount)
 	stats.Increase("Beta3", *reward)
 	stats.Increase("TotalReward", *reward)
 	h.Write(hashValue)
 
 	// Update block produce reward
-	newAccount, reward, hashValue = calculateIISSBlockProduce(ctx, iissDB, blockHeight, false)
+	newAccount, reward, hashValue, err = calculateIISSBlockProduce(ctx, iissDB, blockHeight, false)
+	if err != nil {
+		return fmt.Errorf("failed to write to account DB. %v", err), blockHeight, nil, nil
+	}
 	stats.Increase("Accounts", newAccount)
 	stats.Increase("Beta1", *reward)
 	stats.Increase("TotalReward", *reward)
 	h.Write(hashValue)
 
 	// Update P-Rep delegated reward
-	newAccount, reward, hashValue = calculatePRepReward(ctx, blockHeight)
+	newAccount, reward, hashValue, err = calculatePRepReward(ctx, blockHeight)
+	if err != nil {
+		return fmt.Errorf("failed to write to account DB. %v", err), blockHeight, nil, nil
+	}
 	stats.Increase("Accounts", newAccount)
 	stats.Increase("Beta2", *reward)
 	stats.Increase("TotalReward", *reward)
@@ -493,16 +664,37 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 	ctx.stats = stats
 
 	// make stateHash
-	for _, hash := range stateHashList {
-		h.Write(hash)
+	stateHashVersion := StateHashVersion(ctx.Revision)
+	switch stateHashVersion {
+	case StateHashV2:
+		stateHash, _, err = CalculateStateHashV2(calcDBList)
+		if err != nil {
+			return fmt.Errorf("failed to make state hash. %v", err), blockHeight, nil, nil
+		}
+	default:
+		for _, hash := range stateHashList {
+			h.Write(hash)
+		}
+		h.Read(stateHash)
+	}
+
+	// make Merkle root of accounts for I-Score proof. Merkle tree is kept for QUERY_ISCORE_PROOF
+	var merkleRoot []byte
+	if ctx.DB.merkleTrees != nil {
+		tree, err := newAccountMerkleTree(calcDBList, blockHeight)
+		if err != nil {
+			return fmt.Errorf("failed to make Merkle root. %v", err), blockHeight, nil, nil
+		}
+		ctx.DB.merkleTrees.add(tree)
+		merkleRoot = tree.tree.Root()
 	}
-	h.Read(stateHash)
 
 	elapsedTime := time.Since(startTime)
 	log.Printf("Finish calculation: Duration: %s, block height: %d -> %d, DB: %d, batch: %d, %d entries",
 		elapsedTime, ctx.DB.getCalcDoneBH(), blockHeight, iScoreDB.info.DBCount, writeBatchCount, totalCount)
 	log.Printf("%s", stats.String())
-	log.Printf("stateHash : %s", hex.EncodeToString(stateHash))
+	log.Printf("stateHash V%d : %s", stateHashVersion, hex.EncodeToString(stateHash))
+	log.Printf("merkleRoot : %s", hex.EncodeToString(merkleRoot))
 
 	if NeedToUpdateCalcDebugResult(ctx) {
 		log.Printf("CalculationResult : %s", ctx.calcDebug.result.String())
@@ -510,28 +702,139 @@ func DoCalculate(quit <-chan struct{}, ctx *Context, req *CalculateRequest, c ip
 		ResetCalcDebugResults(ctx)
 	}
 
-	// set blockHeight
-	ctx.DB.setCalcDoneBH(blockHeight)
+	// write rewards of accounts
+	if err = ctx.DB.writeRewardBreakdown(blockHeight, ctx.rewards); err != nil {
+		log.Printf("Failed to write reward breakdown. %v", err)
+	}
+	ctx.rewards = nil
+
+	// write delegation rewards of P-Reps
+	if err = ctx.DB.writePRepReport(blockHeight, ctx.pRepReport); err != nil {
+		log.Printf("Failed to write P-Rep report. %v", err)
+	}
+	ctx.pRepReport = nil
 
 	// write calculation result
-	WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, stats, stateHash)
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), blockHeight, stats, stateHash, merkleRoot)
+	crashPoint()
+
+	// set blockHeight. calculation is rolled forward after crash from here
+	ctx.setCalculationPhase(JournalCalcCommit)
+	ctx.DB.setCalcDoneBH(blockHeight)
+	crashPoint()
+	ctx.endCalculation(blockHeight)
 
 	return nil, blockHeight, ctx.stats, stateHash
 }
 
+// accountWriter reads and updates I-Score accounts of an account DB.
+// Updated accounts are kept in memory to read them again and written to DB with batch
+type accountWriter struct {
+	bucket   db.Bucket
+	batch    db.Batch
+	accounts map[common.Address][]byte
+}
+
+func newAccountWriter(accountDB db.Database) *accountWriter {
+	bucket, _ := accountDB.GetBucket(db.PrefixIScore)
+	batch, _ := accountDB.GetBatch()
+	batch.New()
+
+	return &accountWriter{
+		bucket:   bucket,
+		batch:    batch,
+		accounts: make(map[common.Address][]byte),
+	}
+}
+
+func (aw *accountWriter) get(address common.Address) []byte {
+	if data, ok := aw.accounts[address]; ok {
+		return data
+	}
+	data, _ := aw.bucket.Get(address.Bytes())
+	return data
+}
+
+func (aw *accountWriter) set(ia *IScoreAccount) {
+	aw.accounts[ia.Address] = ia.Bytes()
+}
+
+func (aw *accountWriter) flush(batchCount int) error {
+	for address, data := range aw.accounts {
+		key := make([]byte, 0, len(db.PrefixIScore)+len(address))
+		key = append(key, db.PrefixIScore...)
+		key = append(key, address.Bytes()...)
+		aw.batch.Set(key, data)
+
+		if aw.batch.Len() >= batchCount {
+			if err := aw.batch.Write(); err != nil {
+				return err
+			}
+			aw.batch.Reset()
+			crashPoint()
+		}
+	}
+	aw.accounts = make(map[common.Address][]byte)
+
+	if aw.batch.Len() > 0 {
+		if err := aw.batch.Write(); err != nil {
+			return err
+		}
+		aw.batch.Reset()
+		crashPoint()
+	}
+	return nil
+}
+
+// runWithAccountDB runs f for all calculate DBs in parallel and writes updated accounts to DB.
+// It returns error of f or writing to DB. Accounts of account DB which f failed are not written
+func runWithAccountDB(ctx *Context, f func(index int, aw *accountWriter) error) error {
+	var wait sync.WaitGroup
+	calcDBList := ctx.DB.GetCalcDBList()
+	errList := make([]error, len(calcDBList))
+
+	wait.Add(len(calcDBList))
+	for i, cDB := range calcDBList {
+		go func(index int, write db.Database) {
+			defer wait.Done()
+
+			aw := newAccountWriter(write)
+			if err := f(index, aw); err != nil {
+				errList[index] = err
+				return
+			}
+			if err := aw.flush(writeBatchCount); err != nil {
+				log.Printf("Failed to write batch to account DB %d. err=%+v", index, err)
+				errList[index] = err
+			}
+		}(i, cDB)
+	}
+	wait.Wait()
+
+	for _, err := range errList {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Update I-Score of account in TX list
 func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbose bool) (
-	uint64, *common.HexInt, []byte) {
+	uint64, *common.HexInt, []byte, error) {
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
 	stats := new(common.HexInt)
-	var tx IISSTX
 	var entries, newAccount uint64 = 0, 0
 
+	// read delegate TXs and split them with account DB
+	txList := make([]*IISSTX, 0)
+	txIndexList := make([][]int, ctx.DB.info.DBCount)
 	iter, _ := iissDB.GetIterator()
 	prefix := util.BytesPrefix([]byte(db.PrefixIISSTX))
 	iter.New(prefix.Start, prefix.Limit)
 	for entries = 0; iter.Next(); entries++ {
+		tx := new(IISSTX)
 		err := tx.SetBytes(iter.Value())
 		if err != nil {
 			log.Printf("Failed to load IISS TX data")
@@ -543,23 +846,43 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 		}
 		switch tx.DataType {
 		case TXDataTypeDelegate:
-			// get Calculate DB for account
-			cDB := ctx.DB.getCalculateDB(tx.Address)
-			bucket, _ := cDB.GetBucket(db.PrefixIScore)
+			index := ctx.DB.getAccountDBIndex(tx.Address)
+			txIndexList[index] = append(txIndexList[index], len(txList))
+			txList = append(txList, tx)
+		case TXDataTypePrepReg:
+		case TXDataTypePrepUnReg:
+		}
+	}
+	iter.Release()
+	err := iter.Error()
+	if err != nil {
+		log.Printf("There is error while calculate IISS TX iteration. %+v", err)
+	}
+
+	// update accounts with TXs in TX order of each account DB
+	hashList := make([][]byte, len(txList))
+	statsList := make([]common.HexInt, ctx.DB.info.DBCount)
+	newAccountList := make([]uint64, ctx.DB.info.DBCount)
+	err = runWithAccountDB(ctx, func(index int, aw *accountWriter) error {
+		stats := &statsList[index]
+
+		for _, txIndex := range txIndexList[index] {
+			tx := txList[txIndex]
 
 			// update I-Score
-			newIA := NewIScoreAccountFromIISS(&tx)
+			newIA := NewIScoreAccountFromIISS(tx)
 
-			data, _ := bucket.Get(tx.Address.Bytes())
+			data := aw.get(tx.Address)
 			if data != nil {
 				ia, err := NewIScoreAccountFromBytes(data)
 				if err != nil {
 					log.Printf("Failed to make Account Info. from IISS TX(%s). err=%+v", tx.String(), err)
-					break
+					return err
 				}
 				if ia.BlockHeight != blockHeight {
 					log.Printf("Invalid account Info. from calculate DB(%s)", ia.String())
-					break
+					return fmt.Errorf("invalid block height %d of account %s. expected %d",
+						ia.BlockHeight, tx.Address.String(), blockHeight)
 				}
 
 				// backup original I-Score that calculated to blockHeight
@@ -568,22 +891,24 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 				// calculated I-Score from tx.BlockHeight to blockHeight with old delegation Info
 				ia.BlockHeight = tx.BlockHeight
 				ia.IScore.SetUint64(0)
-				calculateIScore(ctx, ia, blockHeight)
+				calculateIScore(ctx, ia, blockHeight, ctx.pRepReport.recorder(index, tx.Address, -1))
 
 				// reset I-Score to tx.BlockHeight
 				newIA.IScore.Sub(&newIA.IScore.Int, &ia.IScore.Int)
 
 				// Statistics
 				stats.Sub(&stats.Int, &ia.IScore.Int)
+				ctx.rewards.addBeta3(index, tx.Address, new(big.Int).Neg(&ia.IScore.Int))
 			} else {
-				newAccount++
+				newAccountList[index]++
 			}
 
 			// calculate I-Score from tx.BlockHeight to blockHeight with new delegation Info.
-			ok, reward := calculateIScore(ctx, newIA, blockHeight)
+			ok, reward := calculateIScore(ctx, newIA, blockHeight, ctx.pRepReport.recorder(index, tx.Address, 1))
 			// Statistics
 			if ok == true {
 				stats.Add(&stats.Int, &reward.Int)
+				ctx.rewards.addBeta3(index, tx.Address, &reward.Int)
 			}
 
 			if verbose {
@@ -591,32 +916,39 @@ func calculateIISSTX(ctx *Context, iissDB db.Database, blockHeight uint64, verbo
 			}
 
 			// write to account DB
-			bucket.Set(newIA.ID(), newIA.Bytes())
+			aw.set(newIA)
 
-			// update stateHash
-			h.Write(newIA.BytesForHash())
-		case TXDataTypePrepReg:
-		case TXDataTypePrepUnReg:
+			// keep hash of account to make stateHash with TX order
+			hashList[txIndex] = newIA.BytesForHash()
 		}
-	}
-	iter.Release()
-	err := iter.Error()
+		return nil
+	})
 	if err != nil {
-		log.Printf("There is error while calculate IISS TX iteration. %+v", err)
+		return 0, nil, nil, err
+	}
+
+	for i := range statsList {
+		stats.Add(&stats.Int, &statsList[i].Int)
+		newAccount += newAccountList[i]
 	}
 
 	// get stateHash
+	for _, hash := range hashList {
+		if hash != nil {
+			h.Write(hash)
+		}
+	}
 	h.Read(stateHash)
 
 	log.Printf("IISS TX: TX count: %d, new account: %d, I-Score: %s, stateHash: %s",
 		entries, newAccount, stats.String(), hex.EncodeToString(stateHash))
 
-	return newAccount, stats, stateHash
+	return newAccount, stats, stateHash, nil
 }
 
 // Calculate Block produce reward
 func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uint64, verbose bool) (
-	uint64, *common.HexInt, []byte) {
+	uint64, *common.HexInt, []byte, error) {
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
 	bpMap := make(map[common.Address]common.HexInt)
@@ -672,51 +1004,70 @@ func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uin
 		log.Printf("There is error while calculate IISS BP iteration. %+v", err)
 	}
 
+	// split accounts with account DB
+	addressList := make([][]common.Address, ctx.DB.info.DBCount)
+	for addr := range bpMap {
+		index := ctx.DB.getAccountDBIndex(addr)
+		addressList[index] = append(addressList[index], addr)
+	}
+
 	totalReward := new(common.HexInt)
-	iaSlice := make([]*IScoreAccount, 0)
+	iaSliceList := make([][]*IScoreAccount, ctx.DB.info.DBCount)
+	rewardList := make([]common.HexInt, ctx.DB.info.DBCount)
+	newAccountList := make([]uint64, ctx.DB.info.DBCount)
 
 	// write to account DB
-	for addr, reward := range bpMap {
-		// get Account DB for account
-		cDB := ctx.DB.getCalculateDB(addr)
-		bucket, _ := cDB.GetBucket(db.PrefixIScore)
-
-		// update IScoreAccount
-		var ia *IScoreAccount
-		var err error
-		data, _ := bucket.Get(addr.Bytes())
-		if data != nil {
-			ia, err = NewIScoreAccountFromBytes(data)
-			if err != nil {
-				log.Printf("Failed to make Account Info. for Block produce reward(%s). err=%+v", addr.String(), err)
-				break
-			}
+	err = runWithAccountDB(ctx, func(index int, aw *accountWriter) error {
+		for _, addr := range addressList[index] {
+			reward := bpMap[addr]
+
+			// update IScoreAccount
+			var ia *IScoreAccount
+			var err error
+			data := aw.get(addr)
+			if data != nil {
+				ia, err = NewIScoreAccountFromBytes(data)
+				if err != nil {
+					log.Printf("Failed to make Account Info. for Block produce reward(%s). err=%+v", addr.String(), err)
+					return err
+				}
 
-			// update I-Score
-			ia.IScore.Add(&ia.IScore.Int, &reward.Int)
-			ia.Address = addr
-			//log.Printf("Block produce reward: %s, %s", ia.String(), reward.String())
+				// update I-Score
+				ia.IScore.Add(&ia.IScore.Int, &reward.Int)
+				ia.Address = addr
+				//log.Printf("Block produce reward: %s, %s", ia.String(), reward.String())
 
-			// do not update block height of IA
-		} else {
-			// there is no account in DB
-			ia = new(IScoreAccount)
-			ia.IScore.Set(&reward.Int)
-			ia.Address = addr
-			ia.BlockHeight = blockHeight // has no delegation. Set blockHeight to blocHeight of calculation msg
+				// do not update block height of IA
+			} else {
+				// there is no account in DB
+				ia = new(IScoreAccount)
+				ia.IScore.Set(&reward.Int)
+				ia.Address = addr
+				ia.BlockHeight = blockHeight // has no delegation. Set blockHeight to blocHeight of calculation msg
 
-			newAccount++
-		}
+				newAccountList[index]++
+			}
 
-		// write to account DB
-		if ia != nil {
-			bucket.Set(ia.ID(), ia.Bytes())
+			// write to account DB
+			aw.set(ia)
 
-			totalReward.Add(&totalReward.Int, &reward.Int)
+			rewardList[index].Add(&rewardList[index].Int, &reward.Int)
+			ctx.rewards.addBeta1(index, addr, &reward.Int)
 
 			// for state root hash
-			iaSlice = append(iaSlice, ia)
+			iaSliceList[index] = append(iaSliceList[index], ia)
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	iaSlice := make([]*IScoreAccount, 0, len(bpMap))
+	for i := range iaSliceList {
+		iaSlice = append(iaSlice, iaSliceList[i]...)
+		totalReward.Add(&totalReward.Int, &rewardList[i].Int)
+		newAccount += newAccountList[i]
 	}
 
 	// sort data and make state root hash
@@ -731,11 +1082,24 @@ func calculateIISSBlockProduce(ctx *Context, iissDB db.Database, blockHeight uin
 	log.Printf("IISS Block produce: BP count: %d, new account: %d, I-Score: %s, stateHash: %s",
 		entries, newAccount, totalReward.String(), hex.EncodeToString(stateHash))
 
-	return newAccount, totalReward, stateHash
+	return newAccount, totalReward, stateHash, nil
+}
+
+type delegatorReward struct {
+	iScore      common.HexInt
+	blockHeight uint64
+}
+
+// pRepReward is the reward for delegators of a P-Rep in a period
+type pRepReward struct {
+	prep    *PRep
+	end     uint64
+	rewards []delegatorReward
+	hashes  [][]byte
 }
 
 // Calculate Main/Sub P-Rep reward
-func calculatePRepReward(ctx *Context, to uint64) (uint64, *common.HexInt, []byte) {
+func calculatePRepReward(ctx *Context, to uint64) (uint64, *common.HexInt, []byte, error) {
 	h := sha3.NewShake256()
 	stateHash := make([]byte, 64)
 	start := ctx.DB.getCalcDoneBH()
@@ -745,6 +1109,7 @@ func calculatePRepReward(ctx *Context, to uint64) (uint64, *common.HexInt, []byt
 	var newAccount uint64
 
 	// calculate for PRep list
+	pRepRewards := make([]*pRepReward, 0, len(ctx.PRep))
 	for i, prep := range ctx.PRep {
 		//log.Printf("[P-Rep reward] P-Rep : %s", prep.String())
 		if prep.TotalDelegation.Sign() == 0 {
@@ -765,27 +1130,49 @@ func calculatePRepReward(ctx *Context, to uint64) (uint64, *common.HexInt, []byt
 			continue
 		}
 
-		// calculate P-Rep reward for Governance variable and write to calculate DB
-		account, reward, hash := setPRepReward(ctx, s, e, prep, to)
-		h.Write(hash)
-		totalReward.Add(&totalReward.Int, &reward.Int)
-		newAccount += account
+		// calculate P-Rep reward for Governance variable
+		pRepRewards = append(pRepRewards, &pRepReward{
+			prep:    prep,
+			end:     e,
+			rewards: getPRepReward(ctx, s, e, prep),
+			hashes:  make([][]byte, len(prep.List)),
+		})
+	}
+
+	// write to calculate DB
+	rewardList := make([]common.HexInt, ctx.DB.info.DBCount)
+	newAccountList := make([]uint64, ctx.DB.info.DBCount)
+	err := runWithAccountDB(ctx, func(index int, aw *accountWriter) error {
+		for _, pr := range pRepRewards {
+			account, reward, err := setPRepReward(ctx, aw, index, pr, to)
+			if err != nil {
+				return err
+			}
+			rewardList[index].Add(&rewardList[index].Int, &reward.Int)
+			newAccountList[index] += account
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	for i := range rewardList {
+		totalReward.Add(&totalReward.Int, &rewardList[i].Int)
+		newAccount += newAccountList[i]
 	}
 
 	// get stateHash
+	for _, pr := range pRepRewards {
+		h.Write(pr.stateHash())
+	}
 	h.Read(stateHash)
 
-	return newAccount, totalReward, stateHash
+	return newAccount, totalReward, stateHash, nil
 }
 
-func setPRepReward(ctx *Context, start uint64, end uint64, prep *PRep, blockHeight uint64) (uint64, *common.HexInt, []byte) {
-	type reward struct {
-		iScore      common.HexInt
-		blockHeight uint64
-	}
-	rewards := make([]reward, len(prep.List))
-	h := sha3.NewShake256()
-	stateHash := make([]byte, 64)
+func getPRepReward(ctx *Context, start uint64, end uint64, prep *PRep) []delegatorReward {
+	rewards := make([]delegatorReward, len(prep.List))
 
 	// calculate P-Rep reward for Governance variable
 	for i, gv := range ctx.GV {
@@ -823,59 +1210,76 @@ func setPRepReward(ctx *Context, start uint64, end uint64, prep *PRep, blockHeig
 		}
 	}
 
+	return rewards
+}
+
+// setPRepReward writes P-Rep reward of delegators in account DB of index
+func setPRepReward(ctx *Context, aw *accountWriter, index int, pr *pRepReward, blockHeight uint64) (
+	uint64, *common.HexInt, error) {
 	totalReward := new(common.HexInt)
 	var newAccount uint64
 
 	// write to account DB
-	for i, dgInfo := range prep.List {
-		// get Account DB for account
-		cDB := ctx.DB.getCalculateDB(dgInfo.Address)
-		bucket, _ := cDB.GetBucket(db.PrefixIScore)
+	for i, dgInfo := range pr.prep.List {
+		if ctx.DB.getAccountDBIndex(dgInfo.Address) != index {
+			continue
+		}
 
 		// update IScoreAccount
 		var ia *IScoreAccount
 		var err error
-		data, _ := bucket.Get(dgInfo.Address.Bytes())
+		data := aw.get(dgInfo.Address)
 		if data != nil {
 			ia, err = NewIScoreAccountFromBytes(data)
 			if err != nil {
 				log.Printf("Failed to make Account Info. for P-Rep reward(%s). err=%+v", dgInfo.Address.String(), err)
-				break
+				return 0, nil, err
 			}
 
 			// update I-Score
-			ia.IScore.Add(&ia.IScore.Int, &rewards[i].iScore.Int)
+			ia.IScore.Add(&ia.IScore.Int, &pr.rewards[i].iScore.Int)
 			// do not update block height of IA
 			if ctx.Revision < Revision8 {
-				ia.BlockHeight = rewards[i].blockHeight
+				ia.BlockHeight = pr.rewards[i].blockHeight
 			}
 		} else {
 			// there is no account in DB
 			ia = new(IScoreAccount)
-			ia.IScore.Set(&rewards[i].iScore.Int)
+			ia.IScore.Set(&pr.rewards[i].iScore.Int)
 			if ctx.Revision >= Revision8 {
 				ia.BlockHeight = blockHeight
 			} else {
-				ia.BlockHeight = end // Set blockHeight to end
+				ia.BlockHeight = pr.end // Set blockHeight to end
 			}
 
 			newAccount++
 		}
 
 		// write to account DB
-		if ia != nil {
-			ia.Address = dgInfo.Address
-			//log.Printf("[P-Rep reward] Write to DB %s, increased reward: %s", ia.String(), rewards[i].iScore.String())
-			bucket.Set(ia.ID(), ia.Bytes())
-			h.Write(ia.BytesForHash())
-			totalReward.Add(&totalReward.Int, &rewards[i].iScore.Int)
-		}
+		ia.Address = dgInfo.Address
+		//log.Printf("[P-Rep reward] Write to DB %s, increased reward: %s", ia.String(), pr.rewards[i].iScore.String())
+		aw.set(ia)
+		pr.hashes[i] = ia.BytesForHash()
+		totalReward.Add(&totalReward.Int, &pr.rewards[i].iScore.Int)
+		ctx.rewards.addBeta2(index, dgInfo.Address, &pr.rewards[i].iScore.Int)
 	}
 
-	// get stateHash
+	return newAccount, totalReward, nil
+}
+
+// stateHash returns hash of delegator accounts in P-Rep delegation list order
+func (pr *pRepReward) stateHash() []byte {
+	h := sha3.NewShake256()
+	stateHash := make([]byte, 64)
+
+	for _, hash := range pr.hashes {
+		if hash != nil {
+			h.Write(hash)
+		}
+	}
 	h.Read(stateHash)
 
-	return newAccount, totalReward, stateHash
+	return stateHash
 }
 
 const (
@@ -939,6 +1343,9 @@ type QueryCalculateResultResponse struct {
 	BlockHeight uint64
 	IScore      common.HexInt
 	StateHash   []byte
+	// error code and message of failed calculation
+	ErrorCode    uint16
+	ErrorMessage string
 }
 
 func (cr *QueryCalculateResultResponse) StatusString() string {
@@ -957,11 +1364,15 @@ func (cr *QueryCalculateResultResponse) StatusString() string {
 }
 
 func (cr *QueryCalculateResultResponse) String() string {
-	return fmt.Sprintf("Status: %s, BlockHeight: %d, IScore: %s, StateHash: %s",
+	str := fmt.Sprintf("Status: %s, BlockHeight: %d, IScore: %s, StateHash: %s",
 		cr.StatusString(),
 		cr.BlockHeight,
 		cr.IScore.String(),
 		hex.EncodeToString(cr.StateHash))
+	if cr.Status == calcFailed {
+		str += fmt.Sprintf(", Error: %s(%s)", CalcErrorToString(cr.ErrorCode), cr.ErrorMessage)
+	}
+	return str
 }
 
 func (mh *msgHandler) queryCalculateResult(c ipc.Connection, id uint32, data []byte) error {
@@ -1010,6 +1421,8 @@ func DoQueryCalculateResult(ctx *Context, blockHeight uint64, resp *QueryCalcula
 			resp.StateHash = cr.StateHash
 		} else {
 			resp.Status = calcFailed
+			resp.ErrorCode = cr.ErrorCode
+			resp.ErrorMessage = cr.ErrorMessage
 		}
 	} else {
 		// No calculation result
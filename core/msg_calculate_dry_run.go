@@ -0,0 +1,70 @@
+package core
+
+import (
+	"fmt"
+	"log"
+)
+
+// CalculateDryRunResponse has CALCULATE response status and CALCULATE_DONE which CALCULATE would produce
+type CalculateDryRunResponse struct {
+	Status uint16
+	CalculateDone
+}
+
+func (resp *CalculateDryRunResponse) String() string {
+	return fmt.Sprintf("status: %s, %s", CalcRespStatusToString(resp.Status), resp.CalculateDone.String())
+}
+
+// DoCalculateDryRun calculates I-Score with IISS data of req to throwaway calculate DBs.
+// It does not toggle or reset account DBs, write calculation result or clean up IISS data
+func DoCalculateDryRun(ctx *Context, req *CalculateRequest) *CalculateDryRunResponse {
+	// calculate DB, GV and P-Rep candidates are not changed by calculation and rollback while dry-run.
+	// Do not wait for running calculation. Dry-run responds with CalcRespStatusDoing
+	if !ctx.DB.isCalculating() {
+		ctx.DB.calcLock.Lock()
+		defer ctx.DB.calcLock.Unlock()
+	}
+
+	resp := calculateDryRun(ctx, req.Path, ctx.IISSDataBackend, req.BlockHash)
+	if resp.BlockHeight == 0 {
+		resp.BlockHeight = req.BlockHeight
+	}
+	return resp
+}
+
+// VerifyCalculate calculates I-Score of I-Score DB in dbRoot with IISS data in iissPath offline.
+// Nothing is written to I-Score DB
+func VerifyCalculate(dbRoot string, iissPath string, iissBackend string) (*CalculateDryRunResponse, error) {
+	ctx, err := openSimulateContext(dbRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIScoreDB(ctx.DB)
+
+	return calculateDryRun(ctx, iissPath, iissBackend, nil), nil
+}
+
+func calculateDryRun(ctx *Context, iissPath string, iissBackend string, blockHash []byte) *CalculateDryRunResponse {
+	resp := new(CalculateDryRunResponse)
+
+	status, result, err := simulateCalculation(ctx, iissPath, iissBackend, nil, blockHash, true)
+	resp.Status = status
+	if err != nil {
+		log.Printf("Failed to calculate dry-run with %s. %v", iissPath, err)
+		resp.ErrorCode = calcErrorCode(err)
+		resp.ErrorMessage = err.Error()
+		return resp
+	}
+	if result == nil {
+		// error codes of rejected CALCULATE are the same as status
+		resp.ErrorCode = status
+		resp.ErrorMessage = CalcRespStatusToString(status)
+		return resp
+	}
+
+	resp.Success = true
+	resp.BlockHeight = result.BlockHeight
+	resp.IScore.Set(&result.Stats.TotalReward.Int)
+	resp.StateHash = result.StateHash
+	return resp
+}
@@ -80,7 +80,8 @@ func TestMsgCalc_CalculateIISSTX(t *testing.T) {
 	writeTX(iissDB, txList)
 
 	// calculate IISS TX
-	account, stats, hash := calculateIISSTX(ctx, iissDB, 100, false)
+	account, stats, hash, err := calculateIISSTX(ctx, iissDB, 100, false)
+	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), account)
 
 	// check Calculate DB
@@ -160,7 +161,8 @@ func TestMsgCalc_CalculateIISSTX_small_delegation(t *testing.T) {
 	writeTX(iissDB, txList)
 
 	// calculate IISS TX
-	account, stats, hash := calculateIISSTX(ctx, iissDB, 100, false)
+	account, stats, hash, err := calculateIISSTX(ctx, iissDB, 100, false)
+	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), account)
 
 	// check Calculate DB
@@ -180,6 +182,80 @@ func TestMsgCalc_CalculateIISSTX_small_delegation(t *testing.T) {
 	assert.Equal(t, stateHash, hash)
 }
 
+func calculateIISSTXWithDBCount(t *testing.T, dbCount int, addresses []common.Address) []byte {
+	ctx := initTest(dbCount)
+	defer finalizeTest(ctx)
+
+	// set GV
+	gv := new(GovernanceVariable)
+	gv.BlockHeight = 0
+	gv.MainPRepCount.SetUint64(NumMainPRep)
+	gv.SubPRepCount.SetUint64(NumSubPRep)
+	gv.CalculatedIncentiveRep.SetUint64(1)
+	gv.RewardRep.SetUint64(minRewardRep)
+	gv.setReward()
+	ctx.GV = append(ctx.GV, gv)
+
+	// set P-Rep candidate
+	prepA := new(PRepCandidate)
+	prepA.Address = *common.NewAddressFromString("hxaa")
+	prepA.Start = 0
+	ctx.PRepCandidates[prepA.Address] = prepA
+
+	// write IISS TX. each account delegates twice with different amount
+	iissDBDir := testDBDir + "/iiss"
+	iissDB := db.Open(iissDBDir, string(db.GoLevelDBBackend), testDB)
+	defer iissDB.Close()
+	defer os.RemoveAll(iissDBDir)
+	txList := make([]*IISSTX, 0)
+	for i := 0; i < 2; i++ {
+		for j, addr := range addresses {
+			dgDataSlice := []DelegateData{
+				{prepA.Address, *common.NewHexIntFromUint64(MinDelegation * uint64(i+j+1))},
+			}
+			tx := makeIISSTX(TXDataTypeDelegate, addr.String(), dgDataSlice)
+			tx.Index = uint64(len(txList))
+			tx.BlockHeight = uint64(10 * (i + 1))
+			txList = append(txList, tx)
+		}
+	}
+	writeTX(iissDB, txList)
+
+	account, stats, hash, err := calculateIISSTX(ctx, iissDB, 100, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(addresses)), account)
+
+	// check Calculate DB
+	reward := new(common.HexInt)
+	for j, addr := range addresses {
+		bucket, _ := ctx.DB.getCalculateDB(addr).GetBucket(db.PrefixIScore)
+		bs, _ := bucket.Get(addr.Bytes())
+		ia, err := NewIScoreAccountFromBytes(bs)
+		assert.NoError(t, err)
+
+		expected := MinDelegation*uint64(j+1)*(20-10)*minRewardRep/rewardDivider +
+			MinDelegation*uint64(j+2)*(100-20)*minRewardRep/rewardDivider
+		assert.Equal(t, expected, ia.IScore.Uint64())
+		assert.Equal(t, uint64(100), ia.BlockHeight)
+		reward.Add(&reward.Int, &ia.IScore.Int)
+	}
+	assert.Equal(t, reward.Uint64(), stats.Uint64())
+
+	return hash
+}
+
+func TestMsgCalc_CalculateIISSTX_accountDB(t *testing.T) {
+	addresses := make([]common.Address, 0)
+	for i := 1; i <= 10; i++ {
+		addresses = append(addresses, *common.NewAddressFromString(fmt.Sprintf("hx%02x", i*17)))
+	}
+
+	// stateHash does not depend on the number of account DB
+	hash1 := calculateIISSTXWithDBCount(t, 1, addresses)
+	hash4 := calculateIISSTXWithDBCount(t, 4, addresses)
+	assert.Equal(t, hash1, hash4)
+}
+
 func TestMsgCalc_CalculateIISSBlockProduce(t *testing.T) {
 	const (
 		bp0BlockHeight = 5
@@ -256,7 +332,8 @@ func TestMsgCalc_CalculateIISSBlockProduce(t *testing.T) {
 	bucket.Set(bp.ID(), bs)
 
 	// calculate BP
-	account, stats, hash := calculateIISSBlockProduce(ctx, iissDB, 100, false)
+	account, stats, hash, err := calculateIISSBlockProduce(ctx, iissDB, 100, false)
+	assert.NoError(t, err)
 	assert.Equal(t, uint64(3), account)
 
 	calcDB := ctx.DB.getCalculateDB(iconist)
@@ -338,6 +415,62 @@ func setRevision(ctx *Context, revision uint64) {
 	ctx.Revision = revision
 }
 
+func TestMsgCalc_runWithAccountDB(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	// an address for each account DB
+	addresses := make([]common.Address, ctx.DB.info.DBCount)
+	for i := 0; i < 2; i++ {
+		address := *common.NewAddressFromString(fmt.Sprintf("hx%02x", i))
+		addresses[ctx.DB.getAccountDBIndex(address)] = address
+	}
+	assert.NotEqual(t, addresses[0], addresses[1])
+
+	// accounts of account DB which failed are not written
+	err := runWithAccountDB(ctx, func(index int, aw *accountWriter) error {
+		aw.set(&IScoreAccount{Address: addresses[index]})
+		if index == 1 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	})
+	assert.Error(t, err)
+	bucket, _ := ctx.DB.getCalculateDB(addresses[0]).GetBucket(db.PrefixIScore)
+	assert.True(t, bucket.Has(addresses[0].Bytes()))
+	bucket, _ = ctx.DB.getCalculateDB(addresses[1]).GetBucket(db.PrefixIScore)
+	assert.False(t, bucket.Has(addresses[1].Bytes()))
+
+	// invalid account fails block produce reward
+	bucket.Set(addresses[1].Bytes(), []byte("invalid"))
+	iissDBDir := testDBDir + "/iiss"
+	iissDB := db.Open(iissDBDir, string(db.GoLevelDBBackend), testDB)
+	defer iissDB.Close()
+	defer os.RemoveAll(iissDBDir)
+	ctx.GV = []*GovernanceVariable{makeGV(0)}
+	bp := new(IISSBlockProduceInfo)
+	bp.BlockHeight = 5
+	bp.Generator = addresses[1]
+	bp.Validator = make([]common.Address, 0)
+	bs, _ := bp.Bytes()
+	bpBucket, _ := iissDB.GetBucket(db.PrefixIISSBPInfo)
+	bpBucket.Set(bp.ID(), bs)
+	_, _, _, err = calculateIISSBlockProduce(ctx, iissDB, 100, false)
+	assert.Error(t, err)
+
+	// invalid account fails delegation TX
+	writeTX(iissDB, []*IISSTX{makeIISSTX(TXDataTypeDelegate, addresses[1].String(), nil)})
+	_, _, _, err = calculateIISSTX(ctx, iissDB, 100, false)
+	assert.Error(t, err)
+
+	// account not calculated to block height fails delegation TX
+	ia := &IScoreAccount{Address: addresses[1]}
+	ia.BlockHeight = 50
+	bucket.Set(addresses[1].Bytes(), ia.Bytes())
+	_, _, _, err = calculateIISSTX(ctx, iissDB, 100, false)
+	assert.Error(t, err)
+}
+
 func TestMsgCalc_CalculatePRepReward(t *testing.T) {
 	for revision := RevisionMin - 1; revision <= RevisionMax; revision++ {
 		t.Run(fmt.Sprintf("Revision:%d", revision), func(t *testing.T) {
@@ -430,7 +563,8 @@ func testCalculatePRepReward(t *testing.T, revision uint64) {
 	ctx.PRep = append(ctx.PRep, prep)
 
 	// calculate P-Rep reward
-	account, stats, hash := calculatePRepReward(ctx, BlockHeight2)
+	account, stats, hash, err := calculatePRepReward(ctx, BlockHeight2)
+	assert.NoError(t, err)
 	assert.Equal(t, uint64(3), account)
 
 	calcDB := ctx.DB.getCalculateDB(prepA)
@@ -708,6 +842,7 @@ func TestMsgCalc_DoCalculate_Error(t *testing.T) {
 	err, blockHeight, _, _ = DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, 0)
 	assert.Error(t, err)
 	assert.True(t, strings.HasPrefix(err.Error(), "Failed to load IISS data"))
+	assert.Equal(t, CalcErrorInvalidData, calcErrorCode(err))
 	assert.Equal(t, req.BlockHeight, blockHeight)
 
 	// write IISS data DB
@@ -720,6 +855,7 @@ func TestMsgCalc_DoCalculate_Error(t *testing.T) {
 	err, blockHeight, _, _ = DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, 0)
 	assert.Error(t, err)
 	assert.True(t, strings.HasPrefix(err.Error(), "too low blockHeight"))
+	assert.Equal(t, CalcErrorInvalidBH, calcErrorCode(err))
 	assert.Equal(t, req.BlockHeight, blockHeight)
 
 	// get CALCULATE message with duplicated block height
@@ -728,17 +864,35 @@ func TestMsgCalc_DoCalculate_Error(t *testing.T) {
 	err, blockHeight, _, _ = DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, 0)
 	assert.Error(t, err)
 	assert.True(t, strings.HasPrefix(err.Error(), "duplicated block"))
+	assert.Equal(t, CalcErrorDuplicateBH, calcErrorCode(err))
 	assert.Equal(t, req.BlockHeight, blockHeight)
 
-	// Rollback with ROLLBACK
+	// get CALCULATE message with invalid IISS data
 	ctx.DB.setCalcDoneBH(uint64(50))
 	ctx.DB.setCalculatingBH(uint64(50))
+	iissDB = OpenIISSData(iissDBDir, string(db.GoLevelDBBackend))
+	WriteIISSTX(iissDB, 0, "hx11", 20, TXDataTypeDelegate, nil)
+	iissDB.Close()
+	err, blockHeight, _, _ = DoCalculate(ctx.CancelCalculation.GetChannel(), ctx, &req, nil, 0)
+	assert.Error(t, err)
+	_, ok := err.(*IISSDataError)
+	assert.True(t, ok, err)
+	assert.Equal(t, req.BlockHeight, blockHeight)
+	assert.Equal(t, uint64(50), ctx.DB.getCalcDoneBH())
+	assert.False(t, ctx.DB.isCalculating())
+	iissDB = OpenIISSData(iissDBDir, string(db.GoLevelDBBackend))
+	bucket, _ := iissDB.GetBucket(db.PrefixIISSTX)
+	bucket.Delete((&IISSTX{Index: 0}).ID())
+	iissDB.Close()
+
+	// Rollback with ROLLBACK
 
 	quitChannel := ctx.CancelCalculation.GetChannel()
 	ctx.CancelCalculation.notifyRollback()
 	err, blockHeight, _, _ = DoCalculate(quitChannel, ctx, &req, nil, 0)
 	assert.Error(t, err)
 	assert.True(t, strings.HasSuffix(err.Error(), "was canceled by ROLLBACK"))
+	assert.Equal(t, CalcErrorCancelRollback, calcErrorCode(err))
 }
 
 // test RC write calculating field with header. not request
@@ -852,13 +1006,88 @@ func TestMsgQueryCalc_DoQueryCalculateResult(t *testing.T) {
 	stateHash := make([]byte, 64)
 	binary.BigEndian.PutUint64(stateHash, blockHeight)
 
-	WriteCalculationResult(crDB, blockHeight, stats, stateHash)
+	WriteCalculationResult(crDB, blockHeight, stats, stateHash, nil)
 
 	DoQueryCalculateResult(ctx, blockHeight, &resp)
 	assert.Equal(t, calcSucceeded, resp.Status)
 	assert.Equal(t, blockHeight, resp.BlockHeight)
 	assert.Equal(t, 0, resp.IScore.Cmp(&stats.TotalReward.Int))
 	assert.Equal(t, stateHash, resp.StateHash)
+
+	// failure does not overwrite calculation result
+	assert.NoError(t, WriteCalculationFailure(crDB, blockHeight, CalcErrorDuplicateBH, "duplicated"))
+	DoQueryCalculateResult(ctx, blockHeight, &resp)
+	assert.Equal(t, calcSucceeded, resp.Status)
+
+	// failed calculation
+	failBH := blockHeight + 100
+	assert.NoError(t, WriteCalculationFailure(crDB, failBH, CalcErrorCancelRollback, "canceled"))
+	resp = QueryCalculateResultResponse{}
+	DoQueryCalculateResult(ctx, failBH, &resp)
+	assert.Equal(t, calcFailed, resp.Status)
+	assert.Equal(t, CalcErrorCancelRollback, resp.ErrorCode)
+	assert.Equal(t, "canceled", resp.ErrorMessage)
+
+	// calculation result overwrites failure
+	WriteCalculationResult(crDB, failBH, stats, stateHash, nil)
+	resp = QueryCalculateResultResponse{}
+	DoQueryCalculateResult(ctx, failBH, &resp)
+	assert.Equal(t, calcSucceeded, resp.Status)
+	assert.Equal(t, CalcErrorNone, resp.ErrorCode)
+}
+
+func Test_calcErrorCode(t *testing.T) {
+	assert.Equal(t, CalcErrorNone, calcErrorCode(nil))
+	assert.Equal(t, CalcErrorInvalidBH, calcErrorCode(newCalculateError(CalcErrorInvalidBH, "too low")))
+	assert.Equal(t, CalcErrorInvalidData, calcErrorCode(&IISSDataError{}))
+	assert.Equal(t, CalcErrorCancelRollback, calcErrorCode(&CalcCancelByRollbackError{}))
+	assert.Equal(t, CalcErrorCancelExit, calcErrorCode(&CalcCancelByExit{}))
+	assert.Equal(t, CalcErrorInternal, calcErrorCode(&os.PathError{}))
+}
+
+func Test_newCalculateDone(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	crDB := ctx.DB.getCalculateResultDB()
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+
+	// rejected CALCULATE sends error with CALCULATE_DONE only
+	rejects := []error{
+		newCalculateError(CalcErrorDoing, "calculating"),
+		newCalculateError(CalcErrorInvalidBH, "too low"),
+		newCalculateError(CalcErrorDuplicateBH, "duplicated"),
+		&IISSDataError{},
+	}
+	for i, err := range rejects {
+		blockHeight := uint64(100 + i)
+		done := newCalculateDone(ctx, err, blockHeight, nil, nil)
+		assert.False(t, done.Success)
+		assert.Equal(t, calcErrorCode(err), done.ErrorCode)
+		assert.Equal(t, err.Error(), done.ErrorMessage)
+		assert.False(t, bucket.Has(common.Uint64ToBytes(blockHeight)))
+	}
+
+	// failure of accepted calculation is written to calculation result DB
+	accepted := []error{&CalcCancelByRollbackError{}, &CalcCancelByExit{}, fmt.Errorf("write error")}
+	for i, err := range accepted {
+		blockHeight := uint64(200 + i)
+		done := newCalculateDone(ctx, err, blockHeight, nil, nil)
+		assert.False(t, done.Success)
+		assert.Equal(t, calcErrorCode(err), done.ErrorCode)
+		var resp QueryCalculateResultResponse
+		DoQueryCalculateResult(ctx, blockHeight, &resp)
+		assert.Equal(t, calcFailed, resp.Status)
+		assert.Equal(t, done.ErrorCode, resp.ErrorCode)
+	}
+
+	// success
+	stats := new(Statistics)
+	stats.TotalReward.SetUint64(10)
+	done := newCalculateDone(ctx, nil, 300, stats, []byte("hash"))
+	assert.True(t, done.Success)
+	assert.Equal(t, CalcErrorNone, done.ErrorCode)
+	assert.Equal(t, uint64(10), done.IScore.Uint64())
+	assert.False(t, bucket.Has(common.Uint64ToBytes(300)))
 }
 
 func Test_isCalcCancelByRollback(t *testing.T) {
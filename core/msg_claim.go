@@ -86,23 +86,23 @@ func DoClaim(ctx *Context, req *ClaimMessage) (uint64, *common.HexInt) {
 	var claim *Claim = nil
 	var ia *IScoreAccount = nil
 	var err error
-	isDB := ctx.DB
 
-	var cDB, qDB db.Database
 	var bucket db.Bucket
 	var bs []byte
 
-	// read from claim DB
-	cDB = isDB.getClaimDB()
-	bucket, _ = cDB.GetBucket(db.PrefixIScore)
-	bs, _ = bucket.Get(req.Address.Bytes())
-	if bs != nil {
-		claim, _ = NewClaimFromBytes(bs)
+	// read claim DB and query DB with the same view
+	view, err := ctx.DB.newReadView()
+	if err != nil {
+		log.Printf("Failed to get read view. err=%+v", err)
+		return 0, nil
 	}
+	defer view.Release()
+
+	// read from claim DB
+	claim, _ = view.getClaim(req.Address)
 
 	// read from query DB
-	qDB = isDB.getQueryDB(req.Address)
-	bucket, _ = qDB.GetBucket(db.PrefixIScore)
+	bucket, _ = view.getQueryDB(req.Address).GetBucket(db.PrefixIScore)
 	bs, _ = bucket.Get(req.Address.Bytes())
 	if bs != nil {
 		ia, err = NewIScoreAccountFromBytes(bs)
@@ -140,6 +140,7 @@ func DoClaim(ctx *Context, req *ClaimMessage) (uint64, *common.HexInt) {
 		log.Printf("Failed to write PreCommit. err=%+v", err)
 		goto NoReward
 	}
+	metricClaims.Inc()
 
 	return ia.BlockHeight, &ia.IScore
 
@@ -232,7 +233,7 @@ func (mh *msgHandler) commitBlock(c ipc.Connection, id uint32, data []byte) erro
 	iDB := mh.mgr.ctx.DB
 	if req.Success == true {
 		err = writePreCommitToClaimDB(iDB.getPreCommitDB(), iDB.getClaimDB(), iDB.getClaimBackupDB(),
-			req.BlockHeight, req.BlockHash)
+			iDB.getClaimHistoryDB(), iDB.getClaimBackupPeriod(), req.BlockHeight, req.BlockHash)
 		if err == nil {
 			mh.mgr.ctx.DB.setCurrentBlockInfo(req.BlockHeight, req.BlockHash)
 		}
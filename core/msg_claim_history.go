@@ -0,0 +1,116 @@
+package core
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+const (
+	ClaimHistoryStatusOK     uint16 = 0
+	ClaimHistoryStatusFailed uint16 = 1
+)
+
+// QueryClaimHistoryRequest queries claims of Address committed from block height From to To.
+// To is not limited if it is 0
+type QueryClaimHistoryRequest struct {
+	Address common.Address
+	From    uint64
+	To      uint64
+}
+
+func (req *QueryClaimHistoryRequest) String() string {
+	return fmt.Sprintf("Address: %s, From: %d, To: %d", req.Address.String(), req.From, req.To)
+}
+
+type ClaimHistoryEntry struct {
+	BlockHeight uint64 // block height of claim
+	BlockHash   common.HexBytes
+	TXIndex     uint64
+	TXHash      common.HexBytes
+	IScore      common.HexInt // claimed I-Score
+}
+
+func newClaimHistoryEntry(cr *ClaimRecord) ClaimHistoryEntry {
+	var entry ClaimHistoryEntry
+	entry.BlockHeight = cr.BlockHeight
+	entry.BlockHash = cr.BlockHash
+	entry.TXIndex = cr.TXIndex
+	entry.TXHash = cr.TXHash
+	entry.IScore.Set(&cr.IScore.Int)
+	return entry
+}
+
+func (entry *ClaimHistoryEntry) String() string {
+	return fmt.Sprintf("BlockHeight: %d, BlockHash: %s, TXIndex: %d, TXHash: %s, IScore: %s",
+		entry.BlockHeight,
+		hex.EncodeToString(entry.BlockHash),
+		entry.TXIndex,
+		hex.EncodeToString(entry.TXHash),
+		entry.IScore.String())
+}
+
+type QueryClaimHistoryResponse struct {
+	Status  uint16
+	Address common.Address
+	Claims  []ClaimHistoryEntry
+}
+
+func (resp *QueryClaimHistoryResponse) StatusString() string {
+	switch resp.Status {
+	case ClaimHistoryStatusOK:
+		return "OK"
+	case ClaimHistoryStatusFailed:
+		return "Failed"
+	default:
+		return "Unknown status"
+	}
+}
+
+func (resp *QueryClaimHistoryResponse) String() string {
+	return fmt.Sprintf("Status: %s, Address: %s, Claims: %d",
+		resp.StatusString(),
+		resp.Address.String(),
+		len(resp.Claims))
+}
+
+func (mh *msgHandler) queryClaimHistory(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryClaimHistoryRequest
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		log.Printf("Failed to unmarshal data. err=%+v", err)
+		return err
+	}
+	log.Printf("\t QUERY_CLAIM_HISTORY request: %s", req.String())
+
+	mh.mgr.AddMsgTask()
+	resp := DoQueryClaimHistory(mh.mgr.ctx, &req)
+	mh.mgr.DoneMsgTask()
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)",
+		MsgToString(MsgQueryClaimHistory), id, resp.String())
+	return c.Send(MsgQueryClaimHistory, id, resp)
+}
+
+// DoQueryClaimHistory reads claims of address committed in block range from claim history DB
+func DoQueryClaimHistory(ctx *Context, req *QueryClaimHistoryRequest) *QueryClaimHistoryResponse {
+	resp := new(QueryClaimHistoryResponse)
+	resp.Address = req.Address
+	resp.Claims = make([]ClaimHistoryEntry, 0)
+
+	chList, err := ReadClaimHistory(ctx.DB.getClaimHistoryDB(), req.Address, req.From, req.To)
+	if err != nil {
+		log.Printf("Failed to query claim history of %s. %v", req.Address.String(), err)
+		resp.Status = ClaimHistoryStatusFailed
+		return resp
+	}
+
+	for _, ch := range chList {
+		resp.Claims = append(resp.Claims, newClaimHistoryEntry(ch))
+	}
+
+	return resp
+}
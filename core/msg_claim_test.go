@@ -77,7 +77,7 @@ func TestMsgClaim_DoClaim(t *testing.T) {
 
 	// write claim to DB
 	writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
-		claim.BlockHeight, claim.BlockHash)
+		ctx.DB.getClaimHistoryDB(), ctx.DB.getClaimBackupPeriod(), claim.BlockHeight, claim.BlockHash)
 
 	// invalid address
 	blockHeight, iScore = DoClaim(ctx, &invalidAddressClaim)
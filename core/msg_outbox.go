@@ -0,0 +1,117 @@
+package core
+
+import (
+	"log"
+	"math"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// notifyCalculateDone writes CALCULATE_DONE to outbox and sends it to peer.
+// CALCULATE_DONE of successful calculation was written to outbox at commit already.
+// CALCULATE_DONE in outbox is sent after READY on new connection if it was not sent.
+// If ACK_CALCULATE_DONE is enabled, it is sent again on new connection until peer acknowledges it.
+// CALCULATE_DONE of rejected CALCULATE is not written to outbox, because it would replace CALCULATE_DONE of
+// calculation at the same block height
+func (m *manager) notifyCalculateDone(c ipc.Connection, done *CalculateDone) error {
+	m.outboxLock.Lock()
+	defer m.outboxLock.Unlock()
+
+	rejected := calcRejected(done.ErrorCode)
+	if !rejected {
+		if err := WriteCalculateDoneOutbox(m.ctx.DB.getCalculateResultDB(), done); err != nil {
+			log.Printf("Failed to write CALCULATE_DONE to outbox. %v", err)
+		}
+	}
+
+	// peer may be connected again while calculating, so c may be closed already
+	if m.clientMode {
+		c, _ = m.getConn()
+	} else {
+		c = m.getWriter()
+	}
+	if c == nil {
+		log.Printf("Send %s on new connection", MsgToString(MsgCalculateDone))
+		return nil
+	}
+
+	err := c.Send(MsgCalculateDone, 0, done)
+	if err != nil {
+		if m.clientMode {
+			// stop handling message with broken connection and reconnect
+			c.Close()
+		}
+		return err
+	}
+	if !rejected {
+		m.sentCalculateDone(done.BlockHeight)
+	}
+	return nil
+}
+
+// sentCalculateDone deletes CALCULATE_DONE sent to peer from outbox.
+// It is kept until ACK_CALCULATE_DONE if ACK_CALCULATE_DONE is enabled. Caller must hold outboxLock
+func (m *manager) sentCalculateDone(blockHeight uint64) {
+	if m.calcDoneAck {
+		return
+	}
+	if _, err := DeleteCalculateDoneOutbox(m.ctx.DB.getCalculateResultDB(), blockHeight, blockHeight); err != nil {
+		log.Printf("Failed to delete CALCULATE_DONE from outbox. %v", err)
+	}
+}
+
+// flushCalculateDone sends CALCULATE_DONE in outbox to new writer connection. Caller must hold outboxLock
+func (mh *msgHandler) flushCalculateDone(c ipc.Connection) {
+	doneList, err := ReadCalculateDoneOutbox(mh.mgr.ctx.DB.getCalculateResultDB())
+	if err != nil {
+		log.Printf("Failed to read CALCULATE_DONE outbox. %v", err)
+		return
+	}
+
+	for _, done := range doneList {
+		log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgCalculateDone), 0, done.String())
+		if err = c.Send(MsgCalculateDone, 0, done); err != nil {
+			log.Printf("Failed to send CALCULATE_DONE in outbox. %v", err)
+			return
+		}
+		mh.mgr.sentCalculateDone(done.BlockHeight)
+	}
+}
+
+func (mh *msgHandler) ackCalculateDone(c ipc.Connection, id uint32, data []byte) error {
+	var blockHeight uint64
+	mh.mgr.AddMsgTask()
+	if _, err := codec.MP.UnmarshalFromBytes(data, &blockHeight); err != nil {
+		mh.mgr.DoneMsgTask()
+		return err
+	}
+	log.Printf("\t %s request: block height : %d", MsgToString(MsgAckCalculateDone), blockHeight)
+
+	mh.mgr.outboxLock.Lock()
+	err := DoAckCalculateDone(mh.mgr.ctx, blockHeight)
+	mh.mgr.outboxLock.Unlock()
+	if err != nil {
+		log.Printf("Failed to acknowledge CALCULATE_DONE. %v", err)
+	}
+
+	mh.mgr.DoneMsgTask()
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgAckCalculateDone), id, "ack")
+	return c.Send(MsgAckCalculateDone, id, nil)
+}
+
+// DoAckCalculateDone deletes CALCULATE_DONE with block height lower than or equal to blockHeight from outbox
+func DoAckCalculateDone(ctx *Context, blockHeight uint64) error {
+	count, err := DeleteCalculateDoneOutbox(ctx.DB.getCalculateResultDB(), 0, blockHeight)
+	log.Printf("Delete %d CALCULATE_DONE from outbox", count)
+	return err
+}
+
+// rollbackCalculateDoneOutbox deletes CALCULATE_DONE of calculations above rollback block height
+func rollbackCalculateDoneOutbox(ctx *Context, blockHeight uint64) error {
+	if blockHeight == math.MaxUint64 {
+		return nil
+	}
+	_, err := DeleteCalculateDoneOutbox(ctx.DB.getCalculateResultDB(), blockHeight+1, math.MaxUint64)
+	return err
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+const (
+	ProofStatusOK          uint16 = 0
+	ProofStatusNoAccount   uint16 = 1
+	ProofStatusCalculating uint16 = 2
+	ProofStatusInvalidBH   uint16 = 3
+	ProofStatusFailed      uint16 = 4
+	ProofStatusDisabled    uint16 = 5
+)
+
+type QueryIScoreProofRequest struct {
+	Address     common.Address
+	BlockHeight uint64
+}
+
+func (req *QueryIScoreProofRequest) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d", req.Address.String(), req.BlockHeight)
+}
+
+// QueryIScoreProofResponse has I-Score of account calculated at BlockHeight and its Merkle proof.
+// Claimed I-Score is not subtracted from IScore.
+type QueryIScoreProofResponse struct {
+	Status             uint16
+	Address            common.Address
+	BlockHeight        uint64
+	IScore             common.HexInt
+	AccountBlockHeight uint64
+	Index              uint64
+	Proof              []MerkleProofNode
+	MerkleRoot         []byte
+}
+
+func (resp *QueryIScoreProofResponse) StatusString() string {
+	switch resp.Status {
+	case ProofStatusOK:
+		return "OK"
+	case ProofStatusNoAccount:
+		return "No account"
+	case ProofStatusCalculating:
+		return "Calculating"
+	case ProofStatusInvalidBH:
+		return "Invalid block height"
+	case ProofStatusFailed:
+		return "Failed"
+	case ProofStatusDisabled:
+		return "Merkle proof is disabled"
+	default:
+		return "Unknown status"
+	}
+}
+
+func (resp *QueryIScoreProofResponse) String() string {
+	return fmt.Sprintf("Status: %s, Address: %s, BlockHeight: %d, IScore: %s, AccountBlockHeight: %d, "+
+		"Index: %d, Proof: %d nodes, MerkleRoot: %s",
+		resp.StatusString(),
+		resp.Address.String(),
+		resp.BlockHeight,
+		resp.IScore.String(),
+		resp.AccountBlockHeight,
+		resp.Index,
+		len(resp.Proof),
+		hex.EncodeToString(resp.MerkleRoot))
+}
+
+// Leaf returns leaf data of account in Merkle tree
+func (resp *QueryIScoreProofResponse) Leaf() []byte {
+	ia := new(IScoreAccount)
+	ia.Address = resp.Address
+	ia.IScore.Set(&resp.IScore.Int)
+	ia.BlockHeight = resp.AccountBlockHeight
+	return ia.BytesForHash()
+}
+
+// Verify checks Merkle proof with root
+func (resp *QueryIScoreProofResponse) Verify(root []byte) bool {
+	return resp.Status == ProofStatusOK && VerifyMerkleProof(root, resp.Leaf(), resp.Proof)
+}
+
+func (mh *msgHandler) queryIScoreProof(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryIScoreProofRequest
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		log.Printf("Failed to unmarshal data. err=%+v", err)
+		return err
+	}
+	log.Printf("\t QUERY_ISCORE_PROOF request: %s", req.String())
+
+	mh.mgr.AddMsgTask()
+	resp := DoQueryIScoreProof(mh.mgr.ctx, req.Address, req.BlockHeight)
+	mh.mgr.DoneMsgTask()
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryIScoreProof), id, resp.String())
+	return c.Send(MsgQueryIScoreProof, id, resp)
+}
+
+// DoQueryIScoreProof makes Merkle proof of account for calculation at blockHeight.
+// Calculate DB has accounts of the last calculation and query DB has accounts of the previous calculation.
+func DoQueryIScoreProof(ctx *Context, address common.Address, blockHeight uint64) *QueryIScoreProofResponse {
+	resp := new(QueryIScoreProofResponse)
+	resp.Address = address
+	resp.BlockHeight = blockHeight
+
+	if ctx.DB.merkleTrees == nil {
+		resp.Status = ProofStatusDisabled
+		return resp
+	}
+
+	// account DBs are not closed by toggle and reset while reading
+	view, calculating := ctx.DB.newAccountView(blockHeight)
+	if calculating {
+		resp.Status = ProofStatusCalculating
+		return resp
+	}
+	if view == nil {
+		resp.Status = ProofStatusInvalidBH
+		return resp
+	}
+	defer view.Release()
+
+	tree, err := ctx.DB.merkleTrees.get(blockHeight, view.queryDBList)
+	if err != nil {
+		log.Printf("Failed to make Merkle tree of %d. %v", blockHeight, err)
+		resp.Status = ProofStatusFailed
+		return resp
+	}
+	resp.MerkleRoot = tree.tree.Root()
+
+	// check Merkle root in calculation result
+	bucket, _ := ctx.DB.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	bs, _ := bucket.Get(common.Uint64ToBytes(blockHeight))
+	if bs != nil {
+		cr, err := NewCalculationResultFromBytes(bs)
+		if err == nil && len(cr.MerkleRoot) != 0 && !bytes.Equal(cr.MerkleRoot, resp.MerkleRoot) {
+			log.Printf("Merkle root of %d is different with calculation result. %s, %s", blockHeight,
+				hex.EncodeToString(resp.MerkleRoot), hex.EncodeToString(cr.MerkleRoot))
+			resp.Status = ProofStatusFailed
+			return resp
+		}
+	}
+
+	index := tree.index(address)
+	if index < 0 {
+		resp.Status = ProofStatusNoAccount
+		return resp
+	}
+	bucket, _ = view.getQueryDB(address).GetBucket(db.PrefixIScore)
+	bs, err = bucket.Get(address.Bytes())
+	if err != nil || bs == nil {
+		log.Printf("Failed to read account %s of %d. %v", address.String(), blockHeight, err)
+		resp.Status = ProofStatusFailed
+		return resp
+	}
+	ia, err := NewIScoreAccountFromBytes(bs)
+	if err != nil {
+		log.Printf("Failed to read account %s of %d. %v", address.String(), blockHeight, err)
+		resp.Status = ProofStatusFailed
+		return resp
+	}
+
+	resp.Status = ProofStatusOK
+	resp.IScore.Set(&ia.IScore.Int)
+	resp.AccountBlockHeight = ia.BlockHeight
+	resp.Index = uint64(index)
+	resp.Proof = tree.tree.Proof(index)
+
+	return resp
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgProof_DoQueryIScoreProof(t *testing.T) {
+	const (
+		prevCalcBH = uint64(90)
+		calcBH     = uint64(100)
+	)
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	// disabled
+	resp := DoQueryIScoreProof(ctx, *common.NewAddressFromString("hx11"), 0)
+	assert.Equal(t, ProofStatusDisabled, resp.Status)
+	ctx.DB.SetMerkleProof(true)
+
+	ctx.DB.setCalcDoneBH(prevCalcBH)
+	ctx.DB.setCalculatingBH(calcBH)
+	ctx.DB.setCalcDoneBH(calcBH)
+	queryAccounts := writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 10, prevCalcBH)
+	calcAccounts := writeMigrateTestAccounts(ctx.DB.GetCalcDBList(), ctx.DB.getAccountDBIndex, 20, calcBH)
+	calcRoot, _ := CalculateMerkleRoot(ctx.DB.GetCalcDBList())
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), calcBH, nil, nil, calcRoot)
+
+	// last calculation
+	target := calcAccounts[15]
+	resp = DoQueryIScoreProof(ctx, target.Address, calcBH)
+	assert.Equal(t, ProofStatusOK, resp.Status)
+	assert.Equal(t, calcRoot, resp.MerkleRoot)
+	assert.Equal(t, 0, target.IScore.Cmp(&resp.IScore.Int))
+	assert.Equal(t, target.BlockHeight, resp.AccountBlockHeight)
+	assert.True(t, resp.Verify(calcRoot))
+
+	// previous calculation without Merkle root in calculation result
+	target = queryAccounts[3]
+	resp = DoQueryIScoreProof(ctx, target.Address, prevCalcBH)
+	assert.Equal(t, ProofStatusOK, resp.Status)
+	assert.True(t, resp.Verify(resp.MerkleRoot))
+	assert.False(t, resp.Verify(calcRoot))
+
+	// no account in previous calculation
+	resp = DoQueryIScoreProof(ctx, calcAccounts[15].Address, prevCalcBH)
+	assert.Equal(t, ProofStatusNoAccount, resp.Status)
+	assert.False(t, resp.Verify(resp.MerkleRoot))
+
+	// invalid block height
+	resp = DoQueryIScoreProof(ctx, target.Address, calcBH+1)
+	assert.Equal(t, ProofStatusInvalidBH, resp.Status)
+
+	// different Merkle root in calculation result
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), calcBH, nil, nil, testHash)
+	resp = DoQueryIScoreProof(ctx, calcAccounts[0].Address, calcBH)
+	assert.Equal(t, ProofStatusFailed, resp.Status)
+
+	// Merkle tree is made again after rollback
+	ctx.DB.merkleTrees.add(&accountMerkleTree{blockHeight: prevCalcBH, tree: NewMerkleTree(nil)})
+	ctx.DB.setAccountDBToggle(ctx.DB.info.QueryDBIsZero, ctx.DB.info.ToggleBH)
+	resp = DoQueryIScoreProof(ctx, queryAccounts[3].Address, prevCalcBH)
+	assert.Equal(t, ProofStatusOK, resp.Status)
+	assert.True(t, resp.Verify(resp.MerkleRoot))
+
+	// calculating
+	ctx.DB.setCalculatingBH(calcBH + 10)
+	resp = DoQueryIScoreProof(ctx, *common.NewAddressFromString("hx11"), calcBH)
+	assert.Equal(t, ProofStatusCalculating, resp.Status)
+}
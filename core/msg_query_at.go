@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+const (
+	QueryAtStatusOK          uint16 = 0
+	QueryAtStatusNoAccount   uint16 = 1
+	QueryAtStatusCalculating uint16 = 2
+	QueryAtStatusInvalidBH   uint16 = 3
+	QueryAtStatusFailed      uint16 = 4
+)
+
+type QueryAtRequest struct {
+	Address     common.Address
+	BlockHeight uint64
+}
+
+func (req *QueryAtRequest) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d", req.Address.String(), req.BlockHeight)
+}
+
+// QueryAtResponse has I-Score and delegations of account calculated at BlockHeight.
+// Claimed I-Score is not subtracted from IScore.
+type QueryAtResponse struct {
+	Status             uint16
+	Address            common.Address
+	BlockHeight        uint64
+	IScore             common.HexInt
+	AccountBlockHeight uint64
+	Delegations        []*DelegateData
+}
+
+func (resp *QueryAtResponse) StatusString() string {
+	switch resp.Status {
+	case QueryAtStatusOK:
+		return "OK"
+	case QueryAtStatusNoAccount:
+		return "No account"
+	case QueryAtStatusCalculating:
+		return "Calculating"
+	case QueryAtStatusInvalidBH:
+		return "Invalid block height"
+	case QueryAtStatusFailed:
+		return "Failed"
+	default:
+		return "Unknown status"
+	}
+}
+
+func (resp *QueryAtResponse) String() string {
+	return fmt.Sprintf("Status: %s, Address: %s, BlockHeight: %d, IScore: %s, AccountBlockHeight: %d, "+
+		"Delegations: %d",
+		resp.StatusString(),
+		resp.Address.String(),
+		resp.BlockHeight,
+		resp.IScore.String(),
+		resp.AccountBlockHeight,
+		len(resp.Delegations))
+}
+
+func (mh *msgHandler) queryAt(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryAtRequest
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		log.Printf("Failed to unmarshal data. err=%+v", err)
+		return err
+	}
+	log.Printf("\t QUERY_AT request: %s", req.String())
+
+	mh.mgr.AddMsgTask()
+	resp := DoQueryAt(mh.mgr.ctx, req.Address, req.BlockHeight)
+	mh.mgr.DoneMsgTask()
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(MsgQueryAt), id, resp.String())
+	return c.Send(MsgQueryAt, id, resp)
+}
+
+// DoQueryAt reads I-Score of account calculated at blockHeight.
+// Calculate DB, query DB and retained backup account DBs have I-Scores of past calculations.
+func DoQueryAt(ctx *Context, address common.Address, blockHeight uint64) *QueryAtResponse {
+	resp := new(QueryAtResponse)
+	resp.Address = address
+	resp.BlockHeight = blockHeight
+
+	var ia *IScoreAccount
+	var err error
+	resp.Status, err = ctx.DB.readAccountDBAt(blockHeight, address, func(accountDB db.Database) {
+		bucket, _ := accountDB.GetBucket(db.PrefixIScore)
+		var bs []byte
+		if bs, err = bucket.Get(address.Bytes()); err == nil && bs != nil {
+			ia, err = NewIScoreAccountFromBytes(bs)
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to query I-Score of %s at %d. %v", address.String(), blockHeight, err)
+		resp.Status = QueryAtStatusFailed
+		return resp
+	}
+	if resp.Status != QueryAtStatusOK {
+		return resp
+	}
+
+	if ia == nil {
+		resp.Status = QueryAtStatusNoAccount
+		return resp
+	}
+	resp.IScore.Set(&ia.IScore.Int)
+	resp.AccountBlockHeight = ia.BlockHeight
+	resp.Delegations = ia.Delegations
+
+	return resp
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+var queryAtTestAddress = common.NewAddressFromString("hx11")
+
+// calculateQueryAtTest emulates calculation which writes I-Score of queryAtTestAddress with calculation block height
+func calculateQueryAtTest(t *testing.T, ctx *Context, blockHeight uint64) {
+	ctx.DB.setCalculatingBH(blockHeight)
+	ctx.DB.toggleAccountDB(blockHeight + 1)
+	assert.NoError(t, ctx.DB.resetAccountDB(blockHeight))
+
+	ia := IScoreAccount{Address: *queryAtTestAddress}
+	ia.BlockHeight = blockHeight
+	ia.IScore.SetUint64(blockHeight)
+	dg := &DelegateData{Address: *common.NewAddressFromString("hx22")}
+	dg.Delegate.SetUint64(blockHeight * 10)
+	ia.Delegations = []*DelegateData{dg}
+	bucket, _ := ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+
+	ctx.DB.setCalcDoneBH(blockHeight)
+	assert.NoError(t, ctx.DB.pruneCommittedAccountBackups(blockHeight))
+}
+
+func assertQueryAt(t *testing.T, ctx *Context, blockHeight uint64, status uint16) {
+	resp := DoQueryAt(ctx, *queryAtTestAddress, blockHeight)
+	assert.Equal(t, status, resp.Status, "QUERY_AT %d", blockHeight)
+	if status == QueryAtStatusOK {
+		assert.Equal(t, blockHeight, resp.AccountBlockHeight)
+		assert.Equal(t, 0, resp.IScore.Cmp(&common.NewHexIntFromUint64(blockHeight).Int))
+		assert.Equal(t, 1, len(resp.Delegations))
+		assert.Equal(t, 0, resp.Delegations[0].Delegate.Cmp(&common.NewHexIntFromUint64(blockHeight*10).Int))
+	}
+}
+
+func TestMsgQueryAt_DoQueryAt(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetAccountDBBackups(3)
+
+	for _, bh := range []uint64{10, 20, 30, 40, 50} {
+		calculateQueryAtTest(t, ctx, bh)
+	}
+
+	// calculate DB, query DB and 3 backup account DBs
+	for _, bh := range []uint64{10, 20, 30, 40, 50} {
+		assertQueryAt(t, ctx, bh, QueryAtStatusOK)
+	}
+	backups, err := LoadAccountBackups(ctx.DB.management)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(backups))
+	for _, bh := range []uint64{20, 10} {
+		backupName := fmt.Sprintf(BackupDBNameFormat, bh, 1)
+		_, err = os.Stat(filepath.Join(ctx.DB.info.DBRoot, backupName))
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	// deleted backup and invalid block height
+	assertQueryAt(t, ctx, 0, QueryAtStatusInvalidBH)
+	assertQueryAt(t, ctx, 15, QueryAtStatusInvalidBH)
+
+	// no account
+	resp := DoQueryAt(ctx, *common.NewAddressFromString("hx33"), 30)
+	assert.Equal(t, QueryAtStatusNoAccount, resp.Status)
+
+	// calculating
+	ctx.DB.setCalculatingBH(60)
+	assertQueryAt(t, ctx, 30, QueryAtStatusCalculating)
+	ctx.DB.resetCalculatingBH()
+
+	// rollback restores the latest backup and keeps others
+	assert.NoError(t, ctx.DB.rollbackAccountDB(45))
+	assert.Equal(t, uint64(40), ctx.DB.getCalcDoneBH())
+	assertQueryAt(t, ctx, 50, QueryAtStatusInvalidBH)
+	assertQueryAt(t, ctx, 40, QueryAtStatusOK)
+	assertQueryAt(t, ctx, 20, QueryAtStatusOK)
+	assertQueryAt(t, ctx, 10, QueryAtStatusOK)
+
+	// calculation after rollback
+	calculateQueryAtTest(t, ctx, 60)
+	for _, bh := range []uint64{10, 20, 40, 60} {
+		assertQueryAt(t, ctx, bh, QueryAtStatusOK)
+	}
+}
+
+func TestMsgQueryAt_DefaultBackups(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	for _, bh := range []uint64{10, 20, 30, 40} {
+		calculateQueryAtTest(t, ctx, bh)
+	}
+
+	// one backup account DB generation for rollback
+	blockHeights, err := ctx.DB.getAccountBackupBHs()
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{40}, blockHeights)
+	assertQueryAt(t, ctx, 20, QueryAtStatusOK)
+	assertQueryAt(t, ctx, 10, QueryAtStatusInvalidBH)
+}
+
+func TestMsgQueryAt_OpenAccountDBOfAddress(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetAccountDBBackups(2)
+
+	for _, bh := range []uint64{10, 20, 30} {
+		calculateQueryAtTest(t, ctx, bh)
+	}
+	backups, err := LoadAccountBackups(ctx.DB.management)
+	assert.NoError(t, err)
+	backup := FindAccountBackup(backups, 10)
+	assert.NotNil(t, backup)
+
+	// only backup account DB of address is opened
+	index := ctx.DB.getAccountDBIndex(*queryAtTestAddress)
+	other := (index + 1) % ctx.DB.info.DBCount
+	assert.NoError(t, os.RemoveAll(filepath.Join(ctx.DB.info.DBRoot, backup.DBName(other))))
+	assertQueryAt(t, ctx, 10, QueryAtStatusOK)
+
+	assert.NoError(t, os.RemoveAll(filepath.Join(ctx.DB.info.DBRoot, backup.DBName(index))))
+	resp := DoQueryAt(ctx, *queryAtTestAddress, 10)
+	assert.Equal(t, QueryAtStatusFailed, resp.Status)
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+const (
+	RewardBreakdownStatusOK       uint16 = 0
+	RewardBreakdownStatusDisabled uint16 = 1
+	RewardBreakdownStatusFailed   uint16 = 2
+)
+
+// QueryRewardBreakdownRequest queries rewards of Address calculated at BlockHeight.
+// Rewards of all calculations in reward breakdown DB are queried if BlockHeight is 0
+type QueryRewardBreakdownRequest struct {
+	Address     common.Address
+	BlockHeight uint64
+}
+
+func (req *QueryRewardBreakdownRequest) String() string {
+	return fmt.Sprintf("Address: %s, BlockHeight: %d", req.Address.String(), req.BlockHeight)
+}
+
+type RewardTerm struct {
+	BlockHeight uint64
+	Beta1       common.HexInt
+	Beta2       common.HexInt
+	Beta3       common.HexInt
+	IScore      common.HexInt
+}
+
+func (rt *RewardTerm) String() string {
+	return fmt.Sprintf("BlockHeight: %d, Beta1: %s, Beta2: %s, Beta3: %s, IScore: %s",
+		rt.BlockHeight,
+		rt.Beta1.String(),
+		rt.Beta2.String(),
+		rt.Beta3.String(),
+		rt.IScore.String())
+}
+
+type QueryRewardBreakdownResponse struct {
+	Status  uint16
+	Address common.Address
+	Rewards []RewardTerm
+}
+
+func (resp *QueryRewardBreakdownResponse) StatusString() string {
+	switch resp.Status {
+	case RewardBreakdownStatusOK:
+		return "OK"
+	case RewardBreakdownStatusDisabled:
+		return "Disabled"
+	case RewardBreakdownStatusFailed:
+		return "Failed"
+	default:
+		return "Unknown status"
+	}
+}
+
+func (resp *QueryRewardBreakdownResponse) String() string {
+	return fmt.Sprintf("Status: %s, Address: %s, Rewards: %d",
+		resp.StatusString(),
+		resp.Address.String(),
+		len(resp.Rewards))
+}
+
+func (mh *msgHandler) queryRewardBreakdown(c ipc.Connection, id uint32, data []byte) error {
+	var req QueryRewardBreakdownRequest
+	if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+		log.Printf("Failed to unmarshal data. err=%+v", err)
+		return err
+	}
+	log.Printf("\t QUERY_REWARD_BREAKDOWN request: %s", req.String())
+
+	mh.mgr.AddMsgTask()
+	resp := DoQueryRewardBreakdown(mh.mgr.ctx, req.Address, req.BlockHeight)
+	mh.mgr.DoneMsgTask()
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)",
+		MsgToString(MsgQueryRewardBreakdown), id, resp.String())
+	return c.Send(MsgQueryRewardBreakdown, id, resp)
+}
+
+// DoQueryRewardBreakdown reads Beta1, Beta2 and Beta3 of account calculated at blockHeight from reward breakdown DB
+func DoQueryRewardBreakdown(ctx *Context, address common.Address, blockHeight uint64) *QueryRewardBreakdownResponse {
+	resp := new(QueryRewardBreakdownResponse)
+	resp.Address = address
+	resp.Rewards = make([]RewardTerm, 0)
+
+	rbDB := ctx.DB.getRewardBreakdownDB()
+	if rbDB == nil {
+		resp.Status = RewardBreakdownStatusDisabled
+		return resp
+	}
+
+	rbList, err := ReadRewardBreakdowns(rbDB, address, blockHeight)
+	if err != nil {
+		log.Printf("Failed to query reward breakdown of %s at %d. %v", address.String(), blockHeight, err)
+		resp.Status = RewardBreakdownStatusFailed
+		return resp
+	}
+
+	for _, rb := range rbList {
+		var rt RewardTerm
+		rt.BlockHeight = rb.BlockHeight
+		rt.Beta1.Set(&rb.Beta1.Int)
+		rt.Beta2.Set(&rb.Beta2.Int)
+		rt.Beta3.Set(&rb.Beta3.Int)
+		rt.IScore.Set(&rb.IScore().Int)
+		resp.Rewards = append(resp.Rewards, rt)
+	}
+
+	return resp
+}
@@ -61,7 +61,6 @@ func (mh *msgHandler) rollback(c ipc.Connection, id uint32, data []byte) error {
 }
 
 func DoRollBack(ctx *Context, req *RollBackRequest) error {
-	var err error
 	idb := ctx.DB
 	blockHeight := req.BlockHeight
 
@@ -75,31 +74,120 @@ func DoRollBack(ctx *Context, req *RollBackRequest) error {
 	// notify rollback to other goroutines
 	ctx.CancelCalculation.notifyRollback()
 
+	// wait for calculation canceled by rollback and roll it back
+	idb.calcLock.Lock()
+	defer idb.calcLock.Unlock()
+	if err := ctx.rollbackCanceledCalculation(); err != nil {
+		log.Printf("Failed to Rollback canceled calculation. %+v", err)
+		return err
+	}
+
+	// I-Score DB is changed from here. Crash is recovered with journal
+	j := &Journal{Op: JournalOpRollback}
+	j.BlockHeight = blockHeight
+	j.BlockHash = req.BlockHash
+	setJournalPhase(idb.management, j, JournalRollbackClaim)
+
+	err := ctx.rollbackFromClaim(j)
+	if err != nil {
+		// failed rollback is not recovered
+		deleteJournal(idb.management, JournalOpRollback)
+	}
+	return err
+}
+
+// rollbackCanceledCalculation rolls back calculation which was canceled or interrupted. Caller must hold calcLock
+func (ctx *Context) rollbackCanceledCalculation() error {
+	j, err := readJournal(ctx.DB.management, JournalOpCalculate)
+	if err != nil || j == nil {
+		return err
+	}
+	log.Printf("Rollback canceled calculation. %s", j.String())
+	return ctx.recoverCalculation(j, j.CalcDone)
+}
+
+func (ctx *Context) rollbackFromClaim(j *Journal) error {
+	idb := ctx.DB
+
 	// must Rollback claim DB first
-	err = rollbackClaimDB(ctx, blockHeight, req.BlockHash)
+	err := rollbackClaimDB(ctx, j.BlockHeight, j.BlockHash)
 	if err != nil {
 		log.Printf("Failed to Rollback claim DB. %+v", err)
 		return err
 	}
 
-	if checkAccountDBRollback(ctx, blockHeight) {
-		err = idb.rollbackAccountDB(blockHeight)
-		if err != nil {
-			log.Printf("Failed to Rollback account DB. %+v", err)
-			return err
-		}
+	if !checkAccountDBRollback(ctx, j.BlockHeight) {
+		setJournalPhase(idb.management, j, JournalRollbackManagement)
+		return ctx.rollbackFromManagement(j)
 	}
 
-	// rollback GV and Main/Sub P-Rep list
-	ctx.RollbackManagementDB(blockHeight)
+	ar, err := idb.planAccountDBRollback(j.BlockHeight)
+	if err != nil {
+		log.Printf("Failed to Rollback account DB. %+v", err)
+		return err
+	}
+	j.Account = *ar
+	setJournalPhase(idb.management, j, JournalRollbackAccount)
+	return ctx.rollbackFromAccount(j)
+}
 
-	return nil
+func (ctx *Context) rollbackFromAccount(j *Journal) error {
+	idb := ctx.DB
+	if err := idb.applyAccountDBRollback(&j.Account); err != nil {
+		log.Printf("Failed to Rollback account DB. %+v", err)
+		return err
+	}
+
+	setJournalPhase(idb.management, j, JournalRollbackManagement)
+	return ctx.rollbackFromManagement(j)
 }
 
-func checkRollback(ctx *Context, rollback uint64) error {
+func (ctx *Context) rollbackFromManagement(j *Journal) error {
+	var err error
 	idb := ctx.DB
-	if idb.getPrevCalcDoneBH() >= rollback {
-		return &RollbackLowBlockHeightError{idb.getPrevCalcDoneBH(), rollback}
+	blockHeight := j.BlockHeight
+
+	// rollback GV and Main/Sub P-Rep list. Those of rolled back calculations are deleted
+	mngBH := blockHeight
+	if calcDone := idb.getCalcDoneBH(); calcDone < mngBH {
+		mngBH = calcDone
+	}
+	ctx.RollbackManagementDB(mngBH)
+
+	// rewards of rolled back calculation are invalid
+	if err = idb.rollbackRewardBreakdown(blockHeight); err != nil {
+		log.Printf("Failed to Rollback reward breakdown. %+v", err)
+	}
+	if err = idb.rollbackPRepReport(blockHeight); err != nil {
+		log.Printf("Failed to Rollback P-Rep report. %+v", err)
+	}
+
+	// CALCULATE_DONE of rolled back calculation must not be sent
+	if err = rollbackCalculateDoneOutbox(ctx, blockHeight); err != nil {
+		log.Printf("Failed to Rollback CALCULATE_DONE outbox. %+v", err)
+	}
+
+	return deleteJournal(idb.management, JournalOpRollback)
+}
+
+// checkRollback checks account DB can be rolled back to rollback with retained backup account DB generations
+// and claim DB can be rolled back to rollback with claims in claim backup DB.
+// It is checked before canceling calculation, so nothing is changed with too low block height
+func checkRollback(ctx *Context, rollback uint64) error {
+	limit, err := ctx.DB.getRollbackLimitBH()
+	if err != nil {
+		return err
+	}
+	if limit >= rollback {
+		return &RollbackLowBlockHeightError{limit, rollback}
+	}
+
+	cbInfo, err := loadClaimBackupInfo(ctx.DB.getClaimBackupDB())
+	if err != nil {
+		return err
+	}
+	if cbInfo.FirstBlockHeight > rollback {
+		return &RollbackLowBlockHeightError{cbInfo.FirstBlockHeight, rollback}
 	}
 	return nil
 }
@@ -1,8 +1,13 @@
 package core
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,6 +58,166 @@ func TestMsgRollback_checkRollback(t *testing.T) {
 	}
 }
 
+func TestMsgRollback_RollbackMultipleTerms(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetAccountDBBackups(3)
+
+	crDB := ctx.DB.getCalculateResultDB()
+	for _, bh := range []uint64{10, 20, 30, 40, 50} {
+		calculateQueryAtTest(t, ctx, bh)
+		WriteCalculationResult(crDB, bh, nil, nil, nil)
+	}
+
+	// 3 backup account DB generations can roll back 3 calculations
+	limit, err := ctx.DB.getRollbackLimitBH()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), limit)
+	assert.Error(t, checkRollback(ctx, 20))
+	assert.NoError(t, checkRollback(ctx, 21))
+
+	assert.NoError(t, ctx.DB.rollbackAccountDB(25))
+	assert.Equal(t, uint64(20), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(21), ctx.DB.info.ToggleBH)
+	assert.False(t, ctx.DB.isCalculating())
+
+	// calculate DB has I-Score of calculation 20 and query DB has previous one
+	for _, tt := range []struct {
+		aDB db.Database
+		bh  uint64
+	}{
+		{ctx.DB.getCalculateDB(*queryAtTestAddress), 20},
+		{ctx.DB.getQueryDB(*queryAtTestAddress), 10},
+	} {
+		bucket, _ := tt.aDB.GetBucket(db.PrefixIScore)
+		bs, _ := bucket.Get(queryAtTestAddress.Bytes())
+		ia, err := NewIScoreAccountFromBytes(bs)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.bh, ia.IScore.Uint64())
+	}
+
+	// backup account DBs and calculation results of rolled back calculations are deleted
+	backups, err := LoadAccountBackups(ctx.DB.management)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(backups))
+	for _, bh := range []uint64{30, 40, 50} {
+		_, err = os.Stat(filepath.Join(ctx.DB.info.DBRoot, fmt.Sprintf(BackupDBNameFormat, bh, 1)))
+		assert.True(t, os.IsNotExist(err))
+	}
+	bucket, _ := crDB.GetBucket(db.PrefixCalcResult)
+	for _, bh := range []uint64{30, 40, 50} {
+		assert.False(t, bucket.Has(common.Uint64ToBytes(bh)))
+	}
+	assert.True(t, bucket.Has(common.Uint64ToBytes(20)))
+
+	// calculation after rollback
+	calculateQueryAtTest(t, ctx, 30)
+	for _, bh := range []uint64{10, 20, 30} {
+		assertQueryAt(t, ctx, bh, QueryAtStatusOK)
+	}
+}
+
+func TestMsgRollback_checkRollbackClaimBackup(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	ctx.DB.setCalcDoneBH(100)
+	ctx.DB.setCalcDoneBH(200)
+
+	// no claim in claim backup DB
+	assert.NoError(t, checkRollback(ctx, 101))
+
+	// claims from block height 150 are in claim backup DB
+	assert.NoError(t, writeClaimBackupInfo(ctx.DB.getClaimBackupDB(), 150, ctx.DB.getClaimBackupPeriod()))
+	assert.Error(t, checkRollback(ctx, 149))
+	assert.NoError(t, checkRollback(ctx, 150))
+	assert.NoError(t, checkRollback(ctx, 160))
+
+	// ROLLBACK with too low block height does not cancel calculation
+	cancel := ctx.CancelCalculation.GetChannel()
+	assert.Error(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 120, BlockHash: []byte{120}}))
+	select {
+	case <-cancel:
+		assert.Fail(t, "calculation is canceled by failed ROLLBACK")
+	default:
+	}
+	assert.Equal(t, uint64(200), ctx.DB.getCalcDoneBH())
+	assert.False(t, hasJournal(ctx.DB.management))
+}
+
+func TestMsgRollback_claimBackupCoversRollback(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+
+	// unknown calculation term
+	ctx.DB.SetClaimBackupPeriod(1)
+	assert.True(t, ctx.DB.claimBackupCoversRollback())
+
+	// account DB can be rolled back for 2 terms with a backup generation
+	ctx.DB.setCalcDoneBH(100)
+	ctx.DB.setCalcDoneBH(200)
+	ctx.DB.SetClaimBackupPeriod(199)
+	assert.True(t, ctx.DB.claimBackupCoversRollback())
+	ctx.DB.SetClaimBackupPeriod(150)
+	assert.False(t, ctx.DB.claimBackupCoversRollback())
+
+	// 4 terms with 3 backup generations
+	ctx.DB.SetAccountDBBackups(3)
+	ctx.DB.SetClaimBackupPeriod(199)
+	assert.False(t, ctx.DB.claimBackupCoversRollback())
+	ctx.DB.SetClaimBackupPeriod(399)
+	assert.True(t, ctx.DB.claimBackupCoversRollback())
+}
+
+func TestMsgRollback_DoRollBackMultipleTerms(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+	ctx.DB.SetAccountDBBackups(3)
+
+	// calculation at bh has GV and P-Rep list at bh - 5
+	for _, bh := range []uint64{10, 20, 30, 40, 50} {
+		ctx.DB.setCalculatingBH(bh)
+		ctx.DB.toggleAccountDB(bh + 1)
+		assert.NoError(t, ctx.DB.resetAccountDB(bh))
+		gv := makeIISSGV()
+		gv.BlockHeight = bh - 5
+		ctx.UpdateGovernanceVariable([]*IISSGovernanceVariable{gv})
+		pRep := makePRep()
+		pRep.BlockHeight = bh - 5
+		ctx.UpdatePRep([]*PRep{pRep})
+		ctx.DB.setCalcDoneBH(bh)
+		assert.NoError(t, ctx.DB.pruneCommittedAccountBackups(bh))
+	}
+
+	// GV and P-Rep list are retained for rollback with backup account DBs
+	assert.Equal(t, 5, len(ctx.GV))
+	assert.Equal(t, 5, len(ctx.PRep))
+
+	assert.NoError(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 25, BlockHash: []byte{25}}))
+	assert.Equal(t, uint64(20), ctx.DB.getCalcDoneBH())
+	assert.Equal(t, uint64(10), ctx.DB.getPrevCalcDoneBH())
+	assert.Equal(t, uint64(20), ctx.DB.getCalculatingBH())
+	assert.Equal(t, uint64(21), ctx.DB.info.ToggleBH)
+
+	// GV and P-Rep list of rolled back calculations are deleted
+	gvBucket, _ := ctx.DB.management.GetBucket(db.PrefixGovernanceVariable)
+	pRepBucket, _ := ctx.DB.management.GetBucket(db.PrefixPRep)
+	assert.Equal(t, 2, len(ctx.GV))
+	assert.Equal(t, 2, len(ctx.PRep))
+	for i, bh := range []uint64{5, 15} {
+		assert.Equal(t, bh, ctx.GV[i].BlockHeight)
+		assert.Equal(t, bh, ctx.PRep[i].BlockHeight)
+	}
+	for _, bh := range []uint64{25, 35, 45} {
+		assert.False(t, gvBucket.Has((&GovernanceVariable{BlockHeight: bh}).ID()))
+		assert.False(t, pRepBucket.Has((&PRep{BlockHeight: bh}).ID()))
+	}
+
+	// too deep rollback
+	assert.Error(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 5, BlockHash: []byte{5}}))
+}
+
 func TestMsgRollback_checkAccountDBRollback(t *testing.T) {
 	ctx := initTest(2)
 	defer finalizeTest(ctx)
@@ -89,3 +254,95 @@ func TestRollback_notifyRollback(t *testing.T) {
 	assert.NotEqual(t, oldChannel, c.GetChannel())
 	assert.NotNil(t, c.GetChannel())
 }
+
+// calculatePRepCandidateTest emulates calculation at blockHeight with P-Rep register and unregister TXs
+func calculatePRepCandidateTest(t *testing.T, ctx *Context, blockHeight uint64, txList []*IISSTX) {
+	iissDir := filepath.Join(testDir, "iiss")
+	_, iissDB := writeHeader(iissDir, testDB, blockHeight)
+	defer os.RemoveAll(iissDir)
+	defer iissDB.Close()
+	writeTX(iissDB, txList)
+
+	ctx.DB.setCalculatingBH(blockHeight)
+	ctx.DB.toggleAccountDB(blockHeight + 1)
+	assert.NoError(t, ctx.DB.resetAccountDB(blockHeight))
+	ctx.UpdatePRepCandidate(iissDB)
+	ctx.DB.setCalcDoneBH(blockHeight)
+	assert.NoError(t, ctx.DB.pruneCommittedAccountBackups(blockHeight))
+}
+
+func makePRepCandidateTX(dataType uint64, address string, index uint64, blockHeight uint64) *IISSTX {
+	tx := makeIISSTX(dataType, address, nil)
+	tx.Index = index
+	tx.BlockHeight = blockHeight
+	return tx
+}
+
+func assertPRepCandidate(t *testing.T, ctx *Context, address string, start uint64, end uint64) {
+	addr := *common.NewAddressFromString(address)
+	pRepMap, err := LoadPRepCandidate(ctx.DB.management)
+	assert.NoError(t, err)
+	for _, pRep := range []*PRepCandidate{ctx.PRepCandidates[addr], pRepMap[addr]} {
+		if start == 0 {
+			assert.Nil(t, pRep)
+			continue
+		}
+		assert.NotNil(t, pRep)
+		assert.Equal(t, start, pRep.Start)
+		assert.Equal(t, end, pRep.End)
+	}
+}
+
+func TestMsgRollback_RollbackPRepCandidate(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	ctx.DB.SetAccountDBBackups(2)
+
+	// hxaa registers at 5
+	calculatePRepCandidateTest(t, ctx, 10, []*IISSTX{
+		makePRepCandidateTX(TXDataTypePrepReg, "hxaa", 0, 5),
+	})
+	// hxbb registers at 15 and hxaa unregisters at 16
+	calculatePRepCandidateTest(t, ctx, 20, []*IISSTX{
+		makePRepCandidateTX(TXDataTypePrepReg, "hxbb", 0, 15),
+		makePRepCandidateTX(TXDataTypePrepUnReg, "hxaa", 1, 16),
+	})
+	// hxbb unregisters and registers again at 25
+	calculatePRepCandidateTest(t, ctx, 30, []*IISSTX{
+		makePRepCandidateTX(TXDataTypePrepUnReg, "hxbb", 0, 25),
+		makePRepCandidateTX(TXDataTypePrepReg, "hxcc", 1, 25),
+	})
+	assertPRepCandidate(t, ctx, "hxaa", 5, 16)
+	assertPRepCandidate(t, ctx, "hxbb", 15, 25)
+	assertPRepCandidate(t, ctx, "hxcc", 25, 0)
+
+	// rollback calculation 30. unregister of hxbb and register of hxcc are reverted
+	assert.NoError(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 25, BlockHash: []byte{25}}))
+	assert.Equal(t, uint64(20), ctx.DB.getCalcDoneBH())
+	assertPRepCandidate(t, ctx, "hxaa", 5, 16)
+	assertPRepCandidate(t, ctx, "hxbb", 15, 0)
+	assertPRepCandidate(t, ctx, "hxcc", 0, 0)
+
+	// rollback calculation 20. register of hxbb and unregister of hxaa are reverted
+	assert.NoError(t, DoRollBack(ctx, &RollBackRequest{BlockHeight: 11, BlockHash: []byte{11}}))
+	assert.Equal(t, uint64(10), ctx.DB.getCalcDoneBH())
+	assertPRepCandidate(t, ctx, "hxaa", 5, 0)
+	assertPRepCandidate(t, ctx, "hxbb", 0, 0)
+
+	// calculation after rollback registers hxbb at new block height and unregisters hxaa again
+	calculatePRepCandidateTest(t, ctx, 20, []*IISSTX{
+		makePRepCandidateTX(TXDataTypePrepReg, "hxbb", 0, 18),
+		makePRepCandidateTX(TXDataTypePrepUnReg, "hxaa", 1, 19),
+	})
+	assertPRepCandidate(t, ctx, "hxaa", 5, 19)
+	assertPRepCandidate(t, ctx, "hxbb", 18, 0)
+
+	// change logs of calculations which can't be rolled back are deleted
+	calculatePRepCandidateTest(t, ctx, 30, nil)
+	calculatePRepCandidateTest(t, ctx, 40, nil)
+	logs, err := loadPRepCandidateLogs(ctx.DB.management, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, uint64(18), logs[0].BlockHeight)
+	assert.Equal(t, uint64(19), logs[1].BlockHeight)
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"fmt"
 	"github.com/icon-project/rewardcalculator/common/db"
+	"sync"
 	"testing"
 
 	"github.com/icon-project/rewardcalculator/common"
@@ -48,7 +50,7 @@ func TestMsg_DoQuery(t *testing.T) {
 
 	// commit to claim DB
 	writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
-		claim.BlockHeight, claim.BlockHash)
+		ctx.DB.getClaimHistoryDB(), ctx.DB.getClaimBackupPeriod(), claim.BlockHeight, claim.BlockHash)
 
 	// Query to claimed Account after commit
 	resp = DoQuery(ctx, *address)
@@ -56,6 +58,75 @@ func TestMsg_DoQuery(t *testing.T) {
 	assert.Equal(t, 0, resp.IScore.Cmp(&common.NewHexIntFromUint64(100).Int))
 }
 
+func TestMsg_DoBatchQuery(t *testing.T) {
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	addrs := make([]common.Address, 0)
+	for i := 1; i <= 4; i++ {
+		address := common.NewAddressFromString(fmt.Sprintf("hx%02d", i))
+		addrs = append(addrs, *address)
+
+		// query DB has I-Score calculated at block height 100 and calculate DB has at 200
+		ia := IScoreAccount{Address: *address}
+		ia.BlockHeight = 100
+		ia.IScore.SetUint64(claimMinIScore*2 + uint64(i))
+		bucket, _ := ctx.DB.getQueryDB(*address).GetBucket(db.PrefixIScore)
+		bucket.Set(ia.ID(), ia.Bytes())
+
+		ia.BlockHeight = 200
+		ia.IScore.SetUint64(claimMinIScore*3 + uint64(i))
+		bucket, _ = ctx.DB.getCalculateDB(*address).GetBucket(db.PrefixIScore)
+		bucket.Set(ia.ID(), ia.Bytes())
+	}
+
+	// account not in DB
+	addrs = append(addrs, *common.NewAddressFromString("hx99"))
+
+	// claim I-Score of the first account
+	claim := ClaimMessage{BlockHeight: 101, BlockHash: []byte("1-1"), Address: addrs[0]}
+	commit := CommitClaim{Success: true, BlockHeight: claim.BlockHeight, BlockHash: claim.BlockHash, Address: claim.Address}
+	DoClaim(ctx, &claim)
+	DoCommitClaim(ctx, &commit)
+	writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
+		ctx.DB.getClaimHistoryDB(), ctx.DB.getClaimBackupPeriod(), claim.BlockHeight, claim.BlockHash)
+
+	resp := DoBatchQuery(ctx, addrs)
+	assert.Equal(t, len(addrs), len(resp.Accounts))
+	for i, account := range resp.Accounts {
+		assert.Equal(t, addrs[i], account.Address)
+		assert.Equal(t, DoQuery(ctx, addrs[i]).String(), account.String())
+	}
+	assert.Equal(t, uint64(100), resp.Accounts[0].BlockHeight)
+	assert.Equal(t, 0, resp.Accounts[0].IScore.Cmp(&common.NewHexIntFromUint64(1).Int))
+	assert.Equal(t, uint64(0), resp.Accounts[4].BlockHeight)
+	assert.Equal(t, 0, resp.Accounts[4].IScore.Sign())
+
+	// I-Scores come from the same query DB while account DB is toggled
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for bh := uint64(1); ; bh++ {
+			select {
+			case <-stop:
+				return
+			default:
+				ctx.DB.toggleAccountDB(bh)
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		resp = DoBatchQuery(ctx, addrs[1:4])
+		for _, account := range resp.Accounts {
+			assert.Equal(t, resp.Accounts[0].BlockHeight, account.BlockHeight)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
 
 func TestMsg_DoInit(t *testing.T) {
 	ctx := initTest(1)
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"log"
+
+	"github.com/icon-project/rewardcalculator/common/codec"
+	"github.com/icon-project/rewardcalculator/common/ipc"
+)
+
+// isWriteMessage returns true if msg modifies I-Score DB.
+// Only the writer connection can send them.
+func isWriteMessage(msg uint) bool {
+	switch msg {
+	case MsgClaim, MsgCalculate, MsgCommitBlock, MsgCommitClaim, MsgRollBack, MsgINIT, MsgAckCalculateDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// acquireWriter makes c the writer connection if there is no writer connection.
+// In server mode, new writer connection gets CALCULATE_DONE in outbox before the response of its first message.
+// Other connections don't get CALCULATE_DONE which was not sent to the writer connection of restarted peer
+func (mh *msgHandler) acquireWriter(c ipc.Connection) bool {
+	// CALCULATE_DONE is not sent while sending CALCULATE_DONE in outbox
+	mh.mgr.outboxLock.Lock()
+	defer mh.mgr.outboxLock.Unlock()
+
+	writer, acquired := mh.mgr.acquireWriter(c)
+	if acquired && !mh.mgr.clientMode {
+		mh.flushCalculateDone(c)
+	}
+	return writer
+}
+
+// refuse sends failure response of msg from read-only connection
+func (mh *msgHandler) refuse(c ipc.Connection, msg uint, id uint32, data []byte) error {
+	log.Printf("Refuse %s from read-only connection", MsgToString(msg))
+
+	var resp interface{}
+	switch msg {
+	case MsgClaim:
+		var req ClaimMessage
+		if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+			return err
+		}
+		// block height of response is zero in error case
+		r := new(ResponseClaim)
+		r.ClaimMessage = req
+		r.BlockHeight = 0
+		resp = r
+	case MsgCalculate:
+		var req CalculateRequest
+		if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+			return err
+		}
+		resp = &CalculateResponse{Status: CalcRespStatusReadOnly, BlockHeight: req.BlockHeight}
+	case MsgCommitBlock:
+		r := new(CommitBlock)
+		if _, err := codec.MP.UnmarshalFromBytes(data, r); err != nil {
+			return err
+		}
+		r.Success = false
+		resp = r
+	case MsgRollBack:
+		var req RollBackRequest
+		if _, err := codec.MP.UnmarshalFromBytes(data, &req); err != nil {
+			return err
+		}
+		r := new(RollBackResponse)
+		r.Success = false
+		r.RollBackRequest = req
+		resp = r
+	case MsgINIT:
+		var blockHeight uint64
+		if _, err := codec.MP.UnmarshalFromBytes(data, &blockHeight); err != nil {
+			return err
+		}
+		resp = &ResponseInit{Success: false, BlockHeight: blockHeight}
+	default:
+		// COMMIT_CLAIM and ACK_CALCULATE_DONE have no failure response
+		resp = nil
+	}
+
+	log.Printf("Send message. (msg:%s, id:%d, data:%s)", MsgToString(msg), id, MsgDataToString(resp))
+	return c.Send(msg, id, resp)
+}
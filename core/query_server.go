@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// Read-only JSON-RPC 2.0 service over HTTP for explorers and wallets.
+// Requests are served from query DB and claim DB without taking message task of IPC channel.
+//
+//	rc_query                 {"address": "hx..."}               ResponseQuery
+//	rc_queryCalculateStatus  {}                                 QueryCalculateStatusResponse
+//	rc_queryCalculateResult  {"blockHeight": "0x..."}           calculation result
+//	rc_queryClaimHistory     {"address": "hx..."}               ClaimHistory
+const (
+	QueryPath = "/api/v1"
+
+	jsonRPCVersion = "2.0"
+
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+	jsonRPCMethodNotFound = -32601
+	jsonRPCInvalidParams  = -32602
+	jsonRPCServerError    = -32000
+
+	queryMaxRequestSize  = 1024 * 1024
+	queryShutdownTimeout = 5 * time.Second
+)
+
+type jsonRPCRequest struct {
+	Version string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type jsonRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonRPCError) Error() string {
+	return fmt.Sprintf("%s(%d)", e.Message, e.Code)
+}
+
+type jsonRPCResponse struct {
+	Version string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonRPCError   `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type queryAddressParams struct {
+	Address *common.Address `json:"address"`
+}
+
+type queryBlockHeightParams struct {
+	BlockHeight *common.HexUint64 `json:"blockHeight"`
+}
+
+// QueryCalculateResultJSON is QueryCalculateResultResponse with state hash in hex string
+type QueryCalculateResultJSON struct {
+	Status      uint16
+	BlockHeight uint64
+	IScore      common.HexInt
+	StateHash   common.HexBytes
+	// error code and message of failed calculation
+	ErrorCode    uint16
+	ErrorMessage string
+}
+
+// ClaimHistory has total claimed I-Score of account in claim DB and claims in claim history DB.
+// Claims before FirstBlockHeight are included in Claimed only.
+type ClaimHistory struct {
+	Address          common.Address
+	Claimed          common.HexInt
+	BlockHeight      uint64 // block height of the last claim
+	FirstBlockHeight uint64
+	History          []ClaimHistoryEntry
+}
+
+type queryHandler struct {
+	ctx *Context
+}
+
+func newQueryHandler(ctx *Context) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(QueryPath, &queryHandler{ctx: ctx})
+	return mux
+}
+
+func (h *queryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := jsonRPCResponse{Version: jsonRPCVersion, ID: json.RawMessage("null")}
+	var req jsonRPCRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, queryMaxRequestSize)).Decode(&req); err != nil {
+		resp.Error = &jsonRPCError{Code: jsonRPCParseError, Message: err.Error()}
+	} else {
+		if len(req.ID) != 0 {
+			resp.ID = req.ID
+		}
+		if req.Version != jsonRPCVersion || req.Method == "" {
+			resp.Error = &jsonRPCError{Code: jsonRPCInvalidRequest, Message: "invalid request"}
+		} else {
+			resp.Result, resp.Error = h.handle(req.Method, req.Params)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		log.Printf("Failed to write query response. %v", err)
+	}
+}
+
+func (h *queryHandler) handle(method string, params json.RawMessage) (interface{}, *jsonRPCError) {
+	switch method {
+	case "rc_query":
+		var p queryAddressParams
+		if err := parseQueryParams(params, &p); err != nil || p.Address == nil {
+			return nil, invalidParams("address")
+		}
+		return DoQuery(h.ctx, *p.Address), nil
+	case "rc_queryCalculateStatus":
+		var resp QueryCalculateStatusResponse
+		DoQueryCalculateStatus(h.ctx, &resp)
+		return &resp, nil
+	case "rc_queryCalculateResult":
+		var p queryBlockHeightParams
+		if err := parseQueryParams(params, &p); err != nil || p.BlockHeight == nil {
+			return nil, invalidParams("blockHeight")
+		}
+		var resp QueryCalculateResultResponse
+		DoQueryCalculateResult(h.ctx, p.BlockHeight.Value, &resp)
+		result := &QueryCalculateResultJSON{
+			Status:       resp.Status,
+			BlockHeight:  resp.BlockHeight,
+			StateHash:    common.HexBytes{},
+			ErrorCode:    resp.ErrorCode,
+			ErrorMessage: resp.ErrorMessage,
+		}
+		result.IScore.Set(&resp.IScore.Int)
+		if resp.StateHash != nil {
+			result.StateHash = resp.StateHash
+		}
+		return result, nil
+	case "rc_queryClaimHistory":
+		var p queryAddressParams
+		if err := parseQueryParams(params, &p); err != nil || p.Address == nil {
+			return nil, invalidParams("address")
+		}
+		history, err := queryClaimHistory(h.ctx, *p.Address)
+		if err != nil {
+			return nil, &jsonRPCError{Code: jsonRPCServerError, Message: err.Error()}
+		}
+		return history, nil
+	default:
+		return nil, &jsonRPCError{Code: jsonRPCMethodNotFound, Message: "method not found"}
+	}
+}
+
+func parseQueryParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	return json.Unmarshal(params, v)
+}
+
+func invalidParams(name string) *jsonRPCError {
+	return &jsonRPCError{Code: jsonRPCInvalidParams, Message: fmt.Sprintf("invalid params. %s is required", name)}
+}
+
+// queryClaimHistory reads claimed I-Score of address in claim DB and claims in claim history DB
+func queryClaimHistory(ctx *Context, address common.Address) (*ClaimHistory, error) {
+	history := new(ClaimHistory)
+	history.Address = address
+	history.History = make([]ClaimHistoryEntry, 0)
+
+	// read from claim DB
+	bucket, _ := ctx.DB.getClaimDB().GetBucket(db.PrefixIScore)
+	bs, _ := bucket.Get(address.Bytes())
+	if bs == nil {
+		return history, nil
+	}
+	claim, err := NewClaimFromBytes(bs)
+	if err != nil {
+		return nil, err
+	}
+	history.Claimed.Set(&claim.Data.IScore.Int)
+	history.BlockHeight = claim.Data.BlockHeight
+
+	// read claim history DB
+	crList, err := ReadClaimHistory(ctx.DB.getClaimHistoryDB(), address, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, cr := range crList {
+		history.History = append(history.History, newClaimHistoryEntry(cr))
+	}
+	if len(history.History) > 0 {
+		history.FirstBlockHeight = history.History[0].BlockHeight
+	}
+
+	return history, nil
+}
+
+// StartQueryServer serves read-only JSON-RPC with HTTP on address in background
+func StartQueryServer(ctx *Context, address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &http.Server{Handler: newQueryHandler(ctx)}
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Query server stopped. %v", err)
+		}
+	}()
+	log.Printf("Serve query at http://%s%s", address, QueryPath)
+
+	return srv, nil
+}
+
+// StopQueryServer waits for queries in progress before I-Score DB is closed
+func StopQueryServer(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Failed to stop query server. %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func postQuery(t *testing.T, handler http.Handler, body string, result interface{}) *jsonRPCError {
+	req := httptest.NewRequest(http.MethodPost, QueryPath, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Version string          `json:"jsonrpc"`
+		Result  json.RawMessage `json:"result"`
+		Error   *jsonRPCError   `json:"error"`
+		ID      json.RawMessage `json:"id"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, jsonRPCVersion, resp.Version)
+	if resp.Error == nil && result != nil {
+		assert.NoError(t, json.Unmarshal(resp.Result, result))
+	}
+	return resp.Error
+}
+
+func writeQueryTestAccount(ctx *Context, address common.Address, iScore uint64, blockHeight uint64) {
+	ia := IScoreAccount{Address: address}
+	ia.BlockHeight = blockHeight
+	ia.IScore.SetUint64(iScore)
+	bucket, _ := ctx.DB.getQueryDB(address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+}
+
+func claimQueryTestAccount(ctx *Context, address common.Address, blockHeight uint64) {
+	blockHash := []byte{byte(blockHeight)}
+	claim := ClaimMessage{BlockHeight: blockHeight, BlockHash: blockHash, Address: address}
+	commit := CommitClaim{Success: true, BlockHeight: blockHeight, BlockHash: blockHash, Address: address}
+	DoClaim(ctx, &claim)
+	DoCommitClaim(ctx, &commit)
+	writePreCommitToClaimDB(ctx.DB.getPreCommitDB(), ctx.DB.getClaimDB(), ctx.DB.getClaimBackupDB(),
+		ctx.DB.getClaimHistoryDB(), ctx.DB.getClaimBackupPeriod(), blockHeight, blockHash)
+}
+
+func TestQueryServer_Query(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	handler := newQueryHandler(ctx)
+
+	address := common.NewAddressFromString("hx11")
+	writeQueryTestAccount(ctx, *address, claimMinIScore*5+100, 100)
+
+	var resp ResponseQuery
+	err := postQuery(t, handler, `{"jsonrpc":"2.0","id":1,"method":"rc_query","params":{"address":"hx11"}}`, &resp)
+	assert.Nil(t, err)
+	assert.Equal(t, *address, resp.Address)
+	assert.Equal(t, uint64(100), resp.BlockHeight)
+	assert.Equal(t, 0, resp.IScore.Cmp(&common.NewHexIntFromUint64(claimMinIScore*5+100).Int))
+
+	var status QueryCalculateStatusResponse
+	err = postQuery(t, handler, `{"jsonrpc":"2.0","id":2,"method":"rc_queryCalculateStatus"}`, &status)
+	assert.Nil(t, err)
+	assert.Equal(t, CalculationDone, status.Status)
+
+	stats := new(Statistics)
+	stats.TotalReward.SetUint64(10)
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), 100, stats, []byte{1, 2}, nil)
+	var result QueryCalculateResultJSON
+	err = postQuery(t, handler,
+		`{"jsonrpc":"2.0","id":3,"method":"rc_queryCalculateResult","params":{"blockHeight":"0x64"}}`, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, calcSucceeded, result.Status)
+	assert.Equal(t, uint64(100), result.BlockHeight)
+	assert.Equal(t, 0, result.IScore.Cmp(&stats.TotalReward.Int))
+	assert.Equal(t, common.HexBytes{1, 2}, result.StateHash)
+
+	err = postQuery(t, handler,
+		`{"jsonrpc":"2.0","id":4,"method":"rc_queryCalculateResult","params":{"blockHeight":"0x65"}}`, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, InvalidBH, result.Status)
+
+	// failed calculation
+	assert.NoError(t, WriteCalculationFailure(ctx.DB.getCalculateResultDB(), 200, CalcErrorCancelRollback,
+		"canceled"))
+	result = QueryCalculateResultJSON{}
+	err = postQuery(t, handler,
+		`{"jsonrpc":"2.0","id":5,"method":"rc_queryCalculateResult","params":{"blockHeight":"0xc8"}}`, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, calcFailed, result.Status)
+	assert.Equal(t, CalcErrorCancelRollback, result.ErrorCode)
+	assert.Equal(t, "canceled", result.ErrorMessage)
+}
+
+func TestQueryServer_ClaimHistory(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	handler := newQueryHandler(ctx)
+
+	address := common.NewAddressFromString("hx11")
+	body := `{"jsonrpc":"2.0","id":1,"method":"rc_queryClaimHistory","params":{"address":"hx11"}}`
+
+	// no claim
+	var history ClaimHistory
+	err := postQuery(t, handler, body, &history)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(history.History))
+	assert.Equal(t, 0, history.Claimed.Sign())
+
+	// claim twice
+	writeQueryTestAccount(ctx, *address, claimMinIScore*5+100, 100)
+	claimQueryTestAccount(ctx, *address, 101)
+	writeQueryTestAccount(ctx, *address, claimMinIScore*8+100, 200)
+	claimQueryTestAccount(ctx, *address, 201)
+
+	err = postQuery(t, handler, body, &history)
+	assert.Nil(t, err)
+	assert.Equal(t, *address, history.Address)
+	assert.Equal(t, 0, history.Claimed.Cmp(&common.NewHexIntFromUint64(claimMinIScore*8).Int))
+	assert.Equal(t, uint64(201), history.BlockHeight)
+	assert.Equal(t, uint64(101), history.FirstBlockHeight)
+	assert.Equal(t, 2, len(history.History))
+	assert.Equal(t, uint64(101), history.History[0].BlockHeight)
+	assert.Equal(t, 0, history.History[0].IScore.Cmp(&common.NewHexIntFromUint64(claimMinIScore*5).Int))
+	assert.Equal(t, uint64(201), history.History[1].BlockHeight)
+	assert.Equal(t, 0, history.History[1].IScore.Cmp(&common.NewHexIntFromUint64(claimMinIScore*3).Int))
+
+	// query does not include claim of other account
+	claimQueryTestAccount(ctx, *common.NewAddressFromString("hx22"), 202)
+	err = postQuery(t, handler, body, &history)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(history.History))
+}
+
+func TestQueryServer_InvalidRequest(t *testing.T) {
+	ctx := initTest(1)
+	defer finalizeTest(ctx)
+	handler := newQueryHandler(ctx)
+
+	err := postQuery(t, handler, `{"jsonrpc":"2.0","id":1,"method":"rc_unknown"}`, nil)
+	assert.Equal(t, jsonRPCMethodNotFound, err.Code)
+
+	err = postQuery(t, handler, `{"jsonrpc":"2.0","id":1,"method":"rc_query","params":{}}`, nil)
+	assert.Equal(t, jsonRPCInvalidParams, err.Code)
+
+	err = postQuery(t, handler, `{"jsonrpc":"2.0","id":1,"method":"rc_query","params":{"address":"xx"}}`, nil)
+	assert.Equal(t, jsonRPCInvalidParams, err.Code)
+
+	err = postQuery(t, handler, `{"id":1,"method":"rc_query"}`, nil)
+	assert.Equal(t, jsonRPCInvalidRequest, err.Code)
+
+	err = postQuery(t, handler, `{"jsonrpc":`, nil)
+	assert.Equal(t, jsonRPCParseError, err.Code)
+
+	// POST only
+	req := httptest.NewRequest(http.MethodGet, QueryPath, nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// readView pins a generation of query DB and a snapshot of claim DB while handling a request.
+// Account DB can be toggled while readView is in use, but query DB of readView is not closed
+// by resetAccountDB and rollbackAccountDB until Release().
+// Do not call functions which lock account DB before Release().
+type readView struct {
+	idb         *IScoreDB
+	generation  int
+	queryDBList []db.Database
+	claim       db.Snapshot
+}
+
+func (idb *IScoreDB) newReadView() (*readView, error) {
+	v := &readView{idb: idb}
+
+	idb.accountLock.RLock()
+	if idb.info.QueryDBIsZero {
+		v.generation = 0
+		v.queryDBList = idb.Account0
+	} else {
+		v.generation = 1
+		v.queryDBList = idb.Account1
+	}
+	idb.views[v.generation].Add(1)
+	idb.accountLock.RUnlock()
+
+	snapshot, err := idb.getClaimDB().GetSnapshot()
+	if err == nil && snapshot != nil {
+		if err = snapshot.New(); err == nil {
+			v.claim = snapshot
+		}
+	}
+	if err != nil {
+		idb.views[v.generation].Done()
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// newAccountView pins account DBs which have accounts calculated at blockHeight.
+// Calculate DB has accounts of the last calculation and query DB has accounts of the previous calculation.
+// It returns nil if account DBs of blockHeight are not available. calculating is true while calculating
+func (idb *IScoreDB) newAccountView(blockHeight uint64) (v *readView, calculating bool) {
+	idb.accountLock.RLock()
+	defer idb.accountLock.RUnlock()
+
+	// account DB is toggled after calculating block height is set
+	if idb.isCalculating() {
+		return nil, true
+	}
+
+	v = &readView{idb: idb}
+	switch blockHeight {
+	case idb.info.CalcDone:
+		v.generation = 0
+		if idb.info.QueryDBIsZero {
+			v.generation = 1
+		}
+	case idb.info.PrevCalcDone:
+		v.generation = 1
+		if idb.info.QueryDBIsZero {
+			v.generation = 0
+		}
+	default:
+		return nil, false
+	}
+	if v.generation == 0 {
+		v.queryDBList = idb.Account0
+	} else {
+		v.queryDBList = idb.Account1
+	}
+	idb.views[v.generation].Add(1)
+
+	return v, false
+}
+
+// Release unpins query DB and releases claim DB snapshot
+func (v *readView) Release() {
+	if v.claim != nil {
+		v.claim.Release()
+		v.claim = nil
+	}
+	if v.queryDBList != nil {
+		v.queryDBList = nil
+		v.idb.views[v.generation].Done()
+	}
+}
+
+func (v *readView) getQueryDB(address common.Address) db.Database {
+	return v.queryDBList[v.idb.getAccountDBIndex(address)]
+}
+
+// getClaim reads claimed I-Score of address from claim DB snapshot.
+// It reads claim DB directly if claim DB does not support snapshot.
+func (v *readView) getClaim(address common.Address) (*Claim, error) {
+	var bs []byte
+	var err error
+	if v.claim != nil {
+		bs, err = v.claim.Get(append([]byte(db.PrefixClaim), address.Bytes()...))
+	} else {
+		bucket, _ := v.idb.getClaimDB().GetBucket(db.PrefixClaim)
+		bs, err = bucket.Get(address.Bytes())
+	}
+	if err != nil || bs == nil {
+		return nil, err
+	}
+	return NewClaimFromBytes(bs)
+}
+
+// waitReadViews waits for release of readViews which pinned query DB of generations.
+// Caller must hold write lock of account DB, so no readView pins query DB while waiting.
+func (idb *IScoreDB) waitReadViews(generations ...int) {
+	for _, g := range generations {
+		idb.views[g].Wait()
+	}
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+)
+
+// SimulateGV overrides governance variables in calculation term. nil field is not overridden
+type SimulateGV struct {
+	IncentiveRep  *uint64
+	RewardRep     *uint64
+	MainPRepCount *uint64
+	SubPRepCount  *uint64
+}
+
+func (sg *SimulateGV) applyIISS(gv *IISSGovernanceVariable) {
+	if sg.IncentiveRep != nil {
+		gv.IncentiveRep = *sg.IncentiveRep
+	}
+	if sg.RewardRep != nil {
+		gv.RewardRep = *sg.RewardRep
+	}
+	if sg.MainPRepCount != nil {
+		gv.MainPRepCount = *sg.MainPRepCount
+	}
+	if sg.SubPRepCount != nil {
+		gv.SubPRepCount = *sg.SubPRepCount
+	}
+}
+
+func (sg *SimulateGV) apply(gv *GovernanceVariable) {
+	if sg.IncentiveRep != nil {
+		gv.CalculatedIncentiveRep.SetUint64(*sg.IncentiveRep)
+	}
+	if sg.RewardRep != nil {
+		gv.RewardRep.SetUint64(*sg.RewardRep)
+	}
+	if sg.MainPRepCount != nil {
+		gv.MainPRepCount.SetUint64(*sg.MainPRepCount)
+	}
+	if sg.SubPRepCount != nil {
+		gv.SubPRepCount.SetUint64(*sg.SubPRepCount)
+	}
+	gv.setReward()
+}
+
+// AccountDelta has I-Score of account before and after simulated calculation. Claimed I-Score is not subtracted
+type AccountDelta struct {
+	Address common.Address
+	Before  common.HexInt
+	After   common.HexInt
+	Delta   common.HexInt
+}
+
+type SimulateResult struct {
+	// calculation block height of I-Score DB
+	CalcDoneBH uint64
+	// simulated calculation block height
+	BlockHeight uint64
+	Stats       Statistics
+	StateHash   []byte
+	Accounts    []*AccountDelta
+}
+
+// Simulate calculates I-Score of I-Score DB in dbRoot with IISS data in iissPath and governance variable overrides.
+// Accounts are read from calculate DB of I-Score DB and calculated to layer DBs on it.
+// Management DB and calculation result DB are wrapped with layer DB, so nothing is written to disk.
+func Simulate(dbRoot string, iissPath string, iissBackend string, gv *SimulateGV) (*SimulateResult, error) {
+	ctx, err := openSimulateContext(dbRoot)
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIScoreDB(ctx.DB)
+
+	status, result, err := simulateCalculation(ctx, iissPath, iissBackend, gv, nil, false)
+	if err == nil && status != CalcRespStatusOK {
+		err = fmt.Errorf("can't simulate. %s", CalcRespStatusToString(status))
+	}
+	return result, err
+}
+
+// openSimulateContext opens I-Score DB in dbRoot to simulate calculation offline
+func openSimulateContext(dbRoot string) (*Context, error) {
+	dbRoot = filepath.Clean(dbRoot)
+	backend, err := ReadDBBackend(dbRoot)
+	if err != nil {
+		return nil, err
+	}
+	dir, name := filepath.Split(dbRoot)
+	return NewContext(dir, backend, name, 0, "")
+}
+
+// simulateCalculation calculates I-Score of ctx with IISS data in iissPath without modifying I-Score DB of ctx.
+// It returns CALCULATE response status which DoCalculate would send.
+// Accounts are calculated to layer DBs and I-Score deltas of accounts are made.
+// If scratch, accounts are calculated to throwaway account DBs not to keep all accounts in memory instead
+func simulateCalculation(ctx *Context, iissPath string, iissBackend string, gv *SimulateGV, blockHash []byte,
+	scratch bool) (uint16, *SimulateResult, error) {
+	if ctx.DB.isCalculating() {
+		return CalcRespStatusDoing, nil, nil
+	}
+
+	// open IISS Data
+	iissDB := OpenIISSData(iissPath, iissBackend)
+	defer iissDB.Close()
+
+	header, gvList, prepList := LoadIISSData(iissDB)
+	if header == nil {
+		return CalcRespStatusInvalidData, nil, nil
+	}
+
+	calcDoneBH := ctx.DB.getCalcDoneBH()
+	blockHeight := header.BlockHeight
+	if blockHeight == 0 {
+		blockHeight = calcDoneBH + 1
+	}
+	if blockHeight == calcDoneBH {
+		return CalcRespStatusDuplicateBH, nil, nil
+	}
+	if blockHeight < calcDoneBH {
+		return CalcRespStatusInvalidBH, nil, nil
+	}
+	if problems := ValidateIISSData(iissDB, calcDoneBH); len(problems) != 0 {
+		return CalcRespStatusInvalidData, nil, &IISSDataError{Problems: problems}
+	}
+
+	var calcDBList []db.Database
+	if scratch {
+		calcDBs, closeScratch, err := openScratchAccountDBs(ctx.DB.info)
+		if err != nil {
+			return CalcRespStatusOK, nil, err
+		}
+		defer closeScratch()
+		calcDBList = calcDBs
+	} else {
+		// accounts of calculate DB of ctx are copied on write
+		for _, cDB := range ctx.DB.GetCalcDBList() {
+			calcDBList = append(calcDBList, db.NewLayerDB(cDB))
+		}
+	}
+	simCtx := newSimulateContext(ctx, blockHeight, calcDBList)
+	if gv != nil {
+		for _, v := range gvList {
+			gv.applyIISS(v)
+		}
+		for _, v := range simCtx.GV {
+			gv.apply(v)
+		}
+	}
+
+	err, _, stats, stateHash := calculateTerm(simCtx.CancelCalculation.GetChannel(), simCtx, iissDB, header,
+		gvList, prepList, blockHeight, blockHash)
+	if err != nil {
+		return CalcRespStatusOK, nil, err
+	}
+
+	result := new(SimulateResult)
+	result.CalcDoneBH = calcDoneBH
+	result.BlockHeight = blockHeight
+	if stats != nil {
+		result.Stats = *stats
+	}
+	result.StateHash = stateHash
+	if !scratch {
+		result.Accounts, err = simulateDeltas(simCtx.DB.getQueryDBList(), simCtx.DB.GetCalcDBList())
+		if err != nil {
+			return CalcRespStatusOK, nil, err
+		}
+	}
+
+	return CalcRespStatusOK, result, nil
+}
+
+// openScratchAccountDBs opens throwaway account DBs with DB backend of info in a temporary directory.
+// It returns function which closes and deletes throwaway account DBs
+func openScratchAccountDBs(info *DBInfo) ([]db.Database, func(), error) {
+	scratchDir, err := ioutil.TempDir("", SimulateDBNamePrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	dbList := make([]db.Database, info.DBCount)
+	for i := range dbList {
+		dbList[i] = db.Open(scratchDir, info.DBType, fmt.Sprintf(AccountDBNameFormat, i+1, info.DBCount, 0))
+	}
+	closeScratch := func() {
+		closeDBList(dbList)
+		if err := os.RemoveAll(scratchDir); err != nil {
+			log.Printf("Failed to delete throwaway account DBs in %s. %v", scratchDir, err)
+		}
+	}
+	return dbList, closeScratch, nil
+}
+
+// newSimulateContext makes context which calculates accounts in calculate DB of ctx to calcDBList.
+// Management DB and calculation result DB of ctx are wrapped with layer DB
+func newSimulateContext(ctx *Context, blockHeight uint64, calcDBList []db.Database) *Context {
+	info := *ctx.DB.info
+	info.QueryDBIsZero = !info.QueryDBIsZero
+	info.ToggleBH = blockHeight + 1
+	info.Calculating = blockHeight
+
+	simDB := new(IScoreDB)
+	simDB.info = &info
+	simDB.management = db.NewLayerDB(ctx.DB.management)
+	simDB.calcResult = db.NewLayerDB(ctx.DB.calcResult)
+
+	// calculate DB of ctx is query DB of simulation
+	if info.QueryDBIsZero {
+		simDB.Account0 = ctx.DB.GetCalcDBList()
+		simDB.Account1 = calcDBList
+	} else {
+		simDB.Account0 = calcDBList
+		simDB.Account1 = ctx.DB.GetCalcDBList()
+	}
+
+	simCtx := new(Context)
+	simCtx.DB = simDB
+	simCtx.Revision = ctx.Revision
+	simCtx.PRep = ctx.PRep
+	simCtx.IISSDataBackend = ctx.IISSDataBackend
+	simCtx.calcDebug = &CalcDebug{conf: NewCalcDebugConfig()}
+	simCtx.CancelCalculation = NewCancel()
+
+	// GV and P-Rep candidates are updated in calculation
+	simCtx.GV = make([]*GovernanceVariable, len(ctx.GV))
+	for i, gv := range ctx.GV {
+		v := *gv
+		simCtx.GV[i] = &v
+	}
+	simCtx.PRepCandidates = make(map[common.Address]*PRepCandidate, len(ctx.PRepCandidates))
+	for address, pc := range ctx.PRepCandidates {
+		v := *pc
+		simCtx.PRepCandidates[address] = &v
+	}
+
+	return simCtx
+}
+
+// simulateDeltas compares I-Score of accounts in simulated calculate DBs with query DBs
+func simulateDeltas(queryDBList []db.Database, calcDBList []db.Database) ([]*AccountDelta, error) {
+	deltas := make([]*AccountDelta, 0)
+
+	for i, cDB := range calcDBList {
+		bucket, _ := queryDBList[i].GetBucket(db.PrefixIScore)
+		iter, err := cDB.GetIterator()
+		if err != nil {
+			return nil, err
+		}
+		iter.New(nil, nil)
+		for iter.Next() {
+			var ia *IScoreAccount
+			ia, err = NewIScoreAccountFromBytes(iter.Value())
+			if err != nil {
+				break
+			}
+			address := iter.Key()[len(db.PrefixIScore):]
+
+			delta := new(AccountDelta)
+			delta.Address = *common.NewAddress(address)
+			delta.After.Set(&ia.IScore.Int)
+
+			var bs []byte
+			if bs, err = bucket.Get(address); err != nil {
+				break
+			}
+			if bs != nil {
+				var before *IScoreAccount
+				if before, err = NewIScoreAccountFromBytes(bs); err != nil {
+					break
+				}
+				delta.Before.Set(&before.IScore.Int)
+			}
+
+			delta.Delta.Sub(&delta.After.Int, &delta.Before.Int)
+			if delta.Delta.Sign() != 0 {
+				deltas = append(deltas, delta)
+			}
+		}
+		iter.Release()
+		if err != nil {
+			return nil, err
+		}
+		if err = iter.Error(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		return bytes.Compare(deltas[i].Address.Bytes(), deltas[j].Address.Bytes()) < 0
+	})
+
+	return deltas, nil
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate_Simulate(t *testing.T) {
+	const (
+		calcBH = uint64(100)
+		simBH  = uint64(200)
+	)
+	ctx := initTest(2)
+	defer os.RemoveAll(testDir)
+	dbRoot := ctx.DB.info.DBRoot
+
+	// set GV and P-Rep candidate
+	gv := makeIISSGV()
+	gv.BlockHeight = 0
+	gv.RewardRep = minRewardRep
+	ctx.UpdateGovernanceVariable([]*IISSGovernanceVariable{gv})
+	pRep := &PRepCandidate{Address: *common.NewAddressFromString("hxaa")}
+	bucket, _ := ctx.DB.management.GetBucket(db.PrefixPRepCandidate)
+	bs, _ := pRep.Bytes()
+	bucket.Set(pRep.ID(), bs)
+
+	// write account calculated at calcBH
+	ctx.DB.setCalculatingBH(calcBH)
+	ctx.DB.setCalcDoneBH(calcBH)
+	ia := IScoreAccount{Address: *common.NewAddressFromString("hx11")}
+	ia.BlockHeight = calcBH
+	ia.IScore.SetUint64(1000)
+	ia.Delegations = []*DelegateData{{Address: pRep.Address, Delegate: *common.NewHexIntFromUint64(MinDelegation)}}
+	bucket, _ = ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+	bucket.Set(ia.ID(), ia.Bytes())
+	CloseIScoreDB(ctx.DB)
+
+	// write IISS data
+	iissDir := filepath.Join(testDir, "iiss")
+	_, iissDB := writeHeader(iissDir, testDB, simBH)
+	iissDB.Close()
+	iissPath := filepath.Join(iissDir, testDB)
+
+	reward := MinDelegation * (simBH - calcBH) * minRewardRep / rewardDivider
+	dbFiles := readSimulateTestDir(t, dbRoot)
+	result, err := Simulate(dbRoot, iissPath, string(db.GoLevelDBBackend), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, calcBH, result.CalcDoneBH)
+	assert.Equal(t, simBH, result.BlockHeight)
+	assert.Equal(t, uint64(reward), result.Stats.Beta3.Uint64())
+	assert.Equal(t, 1, len(result.Accounts))
+	assert.Equal(t, ia.Address, result.Accounts[0].Address)
+	assert.Equal(t, uint64(1000), result.Accounts[0].Before.Uint64())
+	assert.Equal(t, uint64(1000+reward), result.Accounts[0].After.Uint64())
+	assert.Equal(t, uint64(reward), result.Accounts[0].Delta.Uint64())
+
+	// override RewardRep
+	rewardRep := uint64(minRewardRep * 2)
+	result, err = Simulate(dbRoot, iissPath, string(db.GoLevelDBBackend), &SimulateGV{RewardRep: &rewardRep})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(reward*2), result.Stats.Beta3.Uint64())
+	assert.Equal(t, uint64(reward*2), result.Accounts[0].Delta.Uint64())
+
+	// I-Score DB is not modified and throwaway account DBs are not made
+	assert.Equal(t, dbFiles, readSimulateTestDir(t, dbRoot))
+	ctx, err = NewContext(testDir, testDBBackend, "test", 0, "")
+	assert.NoError(t, err)
+	assert.Equal(t, calcBH, ctx.DB.getCalcDoneBH())
+	assert.False(t, ctx.DB.isCalculating())
+	assert.Equal(t, 1, len(ctx.GV))
+	assert.Equal(t, uint64(minRewardRep), ctx.GV[0].RewardRep.Uint64())
+	bucket, _ = ctx.DB.getCalculateDB(ia.Address).GetBucket(db.PrefixIScore)
+	bs, _ = bucket.Get(ia.ID())
+	stored, _ := NewIScoreAccountFromBytes(bs)
+	assert.Equal(t, uint64(1000), stored.IScore.Uint64())
+	bucket, _ = ctx.DB.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	assert.False(t, bucket.Has(common.Uint64ToBytes(simBH)))
+	CloseIScoreDB(ctx.DB)
+
+	// too low block height
+	_, iissDB = writeHeader(iissDir, testDB, calcBH)
+	iissDB.Close()
+	_, err = Simulate(dbRoot, iissPath, string(db.GoLevelDBBackend), nil)
+	assert.Error(t, err)
+}
+
+func TestMsgCalculate_DoCalculateDryRun(t *testing.T) {
+	const (
+		calcBH = uint64(100)
+		dryBH  = uint64(200)
+	)
+	ctx := initTest(2)
+	defer finalizeTest(ctx)
+
+	ctx.DB.setCalculatingBH(calcBH)
+	ctx.DB.setCalcDoneBH(calcBH)
+	queryDBIsZero := ctx.DB.info.QueryDBIsZero
+
+	// write IISS data
+	iissDir := filepath.Join(testDir, "iiss")
+	_, iissDB := writeHeader(iissDir, testDB, dryBH)
+	iissDB.Close()
+	iissPath := filepath.Join(iissDir, testDB)
+	dbFiles := readSimulateTestDir(t, ctx.DB.info.DBRoot)
+	scratchDirs, _ := filepath.Glob(filepath.Join(os.TempDir(), SimulateDBNamePrefix+"*"))
+
+	req := &CalculateRequest{Path: iissPath, BlockHeight: dryBH, DryRun: true}
+	resp := DoCalculateDryRun(ctx, req)
+	assert.Equal(t, CalcRespStatusOK, resp.Status)
+	assert.True(t, resp.Success)
+	assert.Equal(t, dryBH, resp.BlockHeight)
+	assert.Equal(t, uint64(0), resp.IScore.Uint64())
+	assert.Equal(t, 64, len(resp.StateHash))
+
+	// account DBs are not toggled and calculation result is not written
+	assert.Equal(t, calcBH, ctx.DB.getCalcDoneBH())
+	assert.False(t, ctx.DB.isCalculating())
+	assert.Equal(t, queryDBIsZero, ctx.DB.info.QueryDBIsZero)
+	bucket, _ := ctx.DB.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	assert.False(t, bucket.Has(common.Uint64ToBytes(dryBH)))
+
+	// IISS data is not cleaned up and throwaway account DBs in temporary directory are deleted
+	_, err := os.Stat(iissPath)
+	assert.NoError(t, err)
+	assert.Equal(t, dbFiles, readSimulateTestDir(t, ctx.DB.info.DBRoot))
+	leftovers, _ := filepath.Glob(filepath.Join(os.TempDir(), SimulateDBNamePrefix+"*"))
+	assert.Equal(t, scratchDirs, leftovers)
+
+	// dry-run waits for rollback which holds calcLock
+	ctx.DB.calcLock.Lock()
+	done := make(chan *CalculateDryRunResponse)
+	go func() { done <- DoCalculateDryRun(ctx, req) }()
+	select {
+	case <-done:
+		assert.Fail(t, "dry-run while holding calcLock")
+	case <-time.After(100 * time.Millisecond):
+	}
+	ctx.DB.calcLock.Unlock()
+	resp = <-done
+	assert.Equal(t, CalcRespStatusOK, resp.Status)
+
+	// dry-run does not wait for running calculation
+	ctx.DB.setCalculatingBH(dryBH)
+	ctx.DB.calcLock.Lock()
+	resp = DoCalculateDryRun(ctx, req)
+	ctx.DB.calcLock.Unlock()
+	ctx.DB.resetCalculatingBH()
+	assert.Equal(t, CalcRespStatusDoing, resp.Status)
+
+	// duplicated block height
+	_, iissDB = writeHeader(iissDir, testDB, calcBH)
+	iissDB.Close()
+	resp = DoCalculateDryRun(ctx, req)
+	assert.Equal(t, CalcRespStatusDuplicateBH, resp.Status)
+	assert.Equal(t, CalcErrorDuplicateBH, resp.ErrorCode)
+	assert.False(t, resp.Success)
+	assert.Equal(t, dryBH, resp.BlockHeight)
+}
+
+// readSimulateTestDir returns names of DBs in dir. Files of management DB are changed by opening it
+func readSimulateTestDir(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, info := range infos {
+		if info.IsDir() {
+			names = append(names, info.Name())
+		}
+	}
+	return names
+}
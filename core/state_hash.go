@@ -0,0 +1,145 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/icon-project/rewardcalculator/common/db"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"golang.org/x/crypto/sha3"
+)
+
+// State hash of CALCULATE
+//
+// StateHashV1 (revision < RevisionStateHashV2)
+//
+//	SHAKE256(IISS TX hash || block produce hash || P-Rep reward hash || account DB 1 hash || ... || account DB N hash)
+//	Account DB hashes are written in account DB order, so the state hash depends on the number of account DB.
+//
+// StateHashV2 (revision >= RevisionStateHashV2)
+//
+//	SHAKE256(entry(account 1) || entry(account 2) || ... || entry(account M)), 64 bytes
+//	- accounts : all I-Score accounts in calculate DB after calculation, sorted by address in ascending byte order
+//	- entry(account) : address (21 bytes) || block height (8 bytes, big endian) || I-Score (32 bytes, big endian)
+//	The state hash does not depend on the number of account DB and the order of calculation.
+const (
+	StateHashV1 uint64 = 1
+	StateHashV2 uint64 = 2
+
+	RevisionStateHashV2 = Revision9
+
+	stateHashIScoreBytes = 32
+	stateHashEntryBytes  = common.AddressBytes + 8 + stateHashIScoreBytes
+)
+
+// StateHashVersion returns state hash version for revision of ICON Service
+func StateHashVersion(revision uint64) uint64 {
+	if revision >= RevisionStateHashV2 {
+		return StateHashV2
+	}
+	return StateHashV1
+}
+
+// BytesForStateHashV2 returns entry of account for StateHashV2
+func (ia *IScoreAccount) BytesForStateHashV2() ([]byte, error) {
+	iScore := ia.IScore.Bytes()
+	if ia.IScore.Sign() < 0 || len(iScore) > stateHashIScoreBytes {
+		return nil, fmt.Errorf("invalid I-Score %s of %s", ia.IScore.String(), ia.Address.String())
+	}
+
+	buf := make([]byte, stateHashEntryBytes)
+	copy(buf, ia.Address.Bytes())
+	binary.BigEndian.PutUint64(buf[common.AddressBytes:], ia.BlockHeight)
+	copy(buf[stateHashEntryBytes-len(iScore):], iScore)
+	return buf, nil
+}
+
+// CalculateStateHashV2 returns StateHashV2 of accounts in account DBs and the number of accounts
+func CalculateStateHashV2(dbList []db.Database) ([]byte, uint64, error) {
+	h := sha3.NewShake256()
+	var count uint64
+
+	prefix := util.BytesPrefix([]byte(db.PrefixIScore))
+	err := iterateSorted(dbList, prefix.Start, prefix.Limit, func(key []byte, value []byte) error {
+		ia, err := NewIScoreAccountFromBytes(value)
+		if err != nil {
+			return err
+		}
+		ia.Address = *common.NewAddress(key[len(db.PrefixIScore):])
+
+		bs, err := ia.BytesForStateHashV2()
+		if err != nil {
+			return err
+		}
+		h.Write(bs)
+		count++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stateHash := make([]byte, 64)
+	h.Read(stateHash)
+	return stateHash, count, nil
+}
+
+type StateHashInfo struct {
+	BlockHeight uint64
+	Accounts    uint64
+	StateHash   string
+
+	// state hash in calculation result DB. It is same as StateHash if calculated with StateHashV2
+	CalcResultStateHash string
+}
+
+// ReadStateHash calculates StateHashV2 with account DB of I-Score DB in dbRoot.
+// Calculate DB has accounts of the last calculation and query DB has accounts of the previous calculation.
+func ReadStateHash(dbRoot string, queryDB bool) (*StateHashInfo, error) {
+	dbRoot = filepath.Clean(dbRoot)
+	backend, err := ReadDBBackend(dbRoot)
+	if err != nil {
+		return nil, err
+	}
+	dir, name := filepath.Split(dbRoot)
+	ctx, err := NewContext(dir, backend, name, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	defer CloseIScoreDB(ctx.DB)
+
+	if ctx.DB.isCalculating() {
+		return nil, fmt.Errorf("can't read state hash while calculating. CalcDone: %d, Calculating: %d",
+			ctx.DB.getCalcDoneBH(), ctx.DB.getCalculatingBH())
+	}
+
+	info := new(StateHashInfo)
+	dbList := ctx.DB.GetCalcDBList()
+	info.BlockHeight = ctx.DB.getCalcDoneBH()
+	if queryDB {
+		dbList = ctx.DB.getQueryDBList()
+		info.BlockHeight = ctx.DB.getPrevCalcDoneBH()
+	}
+
+	stateHash, count, err := CalculateStateHashV2(dbList)
+	if err != nil {
+		return nil, err
+	}
+	info.Accounts = count
+	info.StateHash = hex.EncodeToString(stateHash)
+
+	bucket, _ := ctx.DB.getCalculateResultDB().GetBucket(db.PrefixCalcResult)
+	bs, _ := bucket.Get(common.Uint64ToBytes(info.BlockHeight))
+	if bs != nil {
+		cr, err := NewCalculationResultFromBytes(bs)
+		if err != nil {
+			return nil, err
+		}
+		info.CalcResultStateHash = hex.EncodeToString(cr.StateHash)
+	}
+
+	return info, nil
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/common"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/sha3"
+)
+
+func TestStateHash_StateHashVersion(t *testing.T) {
+	assert.Equal(t, StateHashV1, StateHashVersion(Revision8))
+	assert.Equal(t, StateHashV2, StateHashVersion(RevisionStateHashV2))
+	assert.Equal(t, StateHashV2, StateHashVersion(RevisionStateHashV2+1))
+}
+
+func TestStateHash_BytesForStateHashV2(t *testing.T) {
+	ia := new(IScoreAccount)
+	ia.Address = *common.NewAddressFromString("hx11")
+	ia.BlockHeight = 0x0102
+	ia.IScore.SetUint64(0x0304)
+
+	bs, err := ia.BytesForStateHashV2()
+	assert.NoError(t, err)
+	assert.Equal(t, stateHashEntryBytes, len(bs))
+	assert.Equal(t, ia.Address.Bytes(), bs[:common.AddressBytes])
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 1, 2}, bs[common.AddressBytes:common.AddressBytes+8])
+	assert.Equal(t, []byte{3, 4}, bs[stateHashEntryBytes-2:])
+
+	// I-Score overflow
+	ia.IScore.Lsh(&ia.IScore.Int, stateHashIScoreBytes*8)
+	_, err = ia.BytesForStateHashV2()
+	assert.Error(t, err)
+}
+
+func TestStateHash_CalculateStateHashV2(t *testing.T) {
+	ctx := initTest(4)
+	defer finalizeTest(ctx)
+
+	accounts := writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 20, 10)
+	hash4, count, err := CalculateStateHashV2(ctx.DB.getQueryDBList())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(accounts)), count)
+
+	// make expected state hash
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Compare(accounts[j]) < 0
+	})
+	h := sha3.NewShake256()
+	for _, ia := range accounts {
+		bs, _ := ia.BytesForStateHashV2()
+		h.Write(bs)
+	}
+	expected := make([]byte, 64)
+	h.Read(expected)
+	assert.Equal(t, expected, hash4)
+
+	// same accounts in one DB
+	oneDB := ctx.DB.GetCalcDBList()[:1]
+	writeMigrateTestAccounts(oneDB, func(common.Address) int { return 0 }, 20, 10)
+	hash1, count, err := CalculateStateHashV2(oneDB)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(len(accounts)), count)
+	assert.Equal(t, hash4, hash1)
+}
+
+func TestStateHash_ReadStateHash(t *testing.T) {
+	const (
+		prevCalcBH = uint64(90)
+		calcBH     = uint64(100)
+	)
+	ctx := initTest(2)
+	defer os.RemoveAll(testDir)
+	dbRoot := ctx.DB.info.DBRoot
+
+	ctx.DB.setCalcDoneBH(prevCalcBH)
+	ctx.DB.setCalculatingBH(calcBH)
+	ctx.DB.setCalcDoneBH(calcBH)
+	writeMigrateTestAccounts(ctx.DB.getQueryDBList(), ctx.DB.getAccountDBIndex, 10, prevCalcBH)
+	writeMigrateTestAccounts(ctx.DB.GetCalcDBList(), ctx.DB.getAccountDBIndex, 20, calcBH)
+	queryHash, _, _ := CalculateStateHashV2(ctx.DB.getQueryDBList())
+	calcHash, _, _ := CalculateStateHashV2(ctx.DB.GetCalcDBList())
+	WriteCalculationResult(ctx.DB.getCalculateResultDB(), calcBH, nil, calcHash, nil)
+	CloseIScoreDB(ctx.DB)
+
+	// calculate DB
+	info, err := ReadStateHash(dbRoot, false)
+	assert.NoError(t, err)
+	assert.Equal(t, calcBH, info.BlockHeight)
+	assert.Equal(t, uint64(20), info.Accounts)
+	assert.Equal(t, hex.EncodeToString(calcHash), info.StateHash)
+	assert.Equal(t, info.StateHash, info.CalcResultStateHash)
+
+	// query DB
+	info, err = ReadStateHash(dbRoot, true)
+	assert.NoError(t, err)
+	assert.Equal(t, prevCalcBH, info.BlockHeight)
+	assert.Equal(t, uint64(10), info.Accounts)
+	assert.Equal(t, hex.EncodeToString(queryHash), info.StateHash)
+	assert.Equal(t, "", info.CalcResultStateHash)
+
+	// invalid path
+	_, err = ReadStateHash(filepath.Join(testDir, "invalid"), false)
+	assert.Error(t, err)
+}
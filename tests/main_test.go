@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/icon-project/rewardcalculator/core"
+)
+
+// run all tests with each DB backend
+func TestMain(m *testing.M) {
+	result := 0
+	for _, backend := range core.DBBackends {
+		testDBBackend = backend
+		fmt.Printf("Run tests with DB backend %s\n", backend)
+		if code := m.Run(); code != 0 {
+			result = code
+		}
+	}
+	os.Exit(result)
+}
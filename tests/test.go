@@ -22,9 +22,11 @@ type testOption struct {
 	ipc      *core.RCIPC
 }
 
+var testDBBackend = string(db.GoLevelDBBackend)
+
 func initTest() *testOption {
 	var err error
-	testDir, err := ioutil.TempDir("", "goleveldb")
+	testDir, err := ioutil.TempDir("", testDBBackend)
 	if err != nil {
 		panic(err)
 	}
@@ -34,7 +36,7 @@ func initTest() *testOption {
 
 	dbPath := filepath.Join(opts.rootPath, ".iscoredb")
 	address := filepath.Join(opts.rootPath, "/icon_rc.sock")
-	cmd := exec.Command("icon_rc", "-db", dbPath, "-ipc-addr",  address)
+	cmd := exec.Command("icon_rc", "-db", dbPath, "-ipc-addr",  address, "-db-backend", testDBBackend)
 	err = cmd.Start()
 	if err != nil {
 		log.Fatal(err)